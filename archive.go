@@ -0,0 +1,171 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// defaultMaxArchiveSize bounds the bytes read from one archive, after
+// decompression, so a small .tgz cannot expand without limit.
+const defaultMaxArchiveSize = 100 << 20
+
+// archiveExts are the suffixes of arguments read as tar archives.
+var archiveExts = []string{".tar", ".tar.gz", ".tgz"}
+
+// memberExts are the archive members that are validated; everything
+// else in the archive is ignored.
+var memberExts = []string{".yaml", ".yml", ".json"}
+
+func isArchive(name string) bool { return hasSuffixFold(name, archiveExts) }
+
+func hasSuffixFold(name string, suffixes []string) bool {
+	name = strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateArchive streams the tar archive at name and validates each
+// manifest member as "name!member". Members are never written to disk.
+// Directories and other members are skipped, but any member whose path
+// leads outside the archive root, such as "../web.yaml", is reported as
+// an I/O finding.
+func (r *runner) validateArchive(name string) int {
+	f, err := os.Open(name)
+	if err != nil {
+		return r.archiveFailed(name, fmt.Errorf("%w: %w", validator.ErrIO, err))
+	}
+	defer f.Close()
+	var in io.Reader = f
+	if !strings.HasSuffix(strings.ToLower(name), ".tar") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return r.archiveFailed(name, fmt.Errorf("%s: %w: %w", name, validator.ErrIO, err))
+		}
+		defer zr.Close()
+		in = zr
+	}
+	limit := &capReader{r: in, left: r.maxArchive}
+	if r.maxArchive > 0 {
+		in = limit
+	}
+
+	code, errs := exitOK, 0
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !limit.exceeded {
+			r.prog.clear()
+			r.emit(validator.IOResult(name, fmt.Errorf("%s: %w: %w", name, validator.ErrIO, err)))
+			code, errs = worseExit(code, exitIO), errs+1
+			break
+		}
+		if err == nil && outsideRoot(hdr.Name) {
+			// Whatever the member holds, an archive that would write
+			// outside its root when extracted is not to be trusted.
+			r.prog.clear()
+			r.emit(validator.IOResult(name+"!"+hdr.Name, fmt.Errorf("%w: member path leads outside the archive root", validator.ErrIO)))
+			code, errs = worseExit(code, exitIO), errs+1
+			continue
+		}
+		if err == nil && hdr.Typeflag == tar.TypeReg && hasSuffixFold(hdr.Name, memberExts) {
+			c, n := r.validateMember(name+"!"+path.Clean(hdr.Name), tr, limit)
+			code, errs = worseExit(code, c), errs+n
+		}
+		if limit.exceeded {
+			r.prog.clear()
+			r.emit(validator.IOResult(name, &validator.SizeError{Name: name, Limit: r.maxArchive}))
+			code, errs = worseExit(code, exitIO), errs+1
+			break
+		}
+	}
+	r.prog.advance(errs)
+	return code
+}
+
+// outsideRoot reports whether the archive member called member would
+// be extracted outside the directory the archive is extracted in.
+func outsideRoot(member string) bool {
+	member = path.Clean(strings.ReplaceAll(member, `\`, "/"))
+	return path.IsAbs(member) || member == ".." || strings.HasPrefix(member, "../")
+}
+
+// validateMember validates one archive member read from in, returning
+// its exit code and error count. When the archive as a whole goes over
+// its limit the caller reports that instead.
+func (r *runner) validateMember(name string, in io.Reader, limit *capReader) (int, int) {
+	src, err := validator.ReadAll(name, in, r.opts.MaxFileSize)
+	if err != nil {
+		if limit.exceeded {
+			return exitIO, 0
+		}
+		var se *validator.SizeError
+		if !errors.As(err, &se) {
+			err = fmt.Errorf("%s: %w: %w", name, validator.ErrIO, err)
+		}
+		r.prog.clear()
+		r.emit(validator.IOResult(name, err))
+		return exitIO, 1
+	}
+	res, err := validator.ValidateBytes(context.Background(), name, src, r.opts)
+	code, errs := r.report(name, res, err)
+	r.keep(name, src)
+	if r.refs != nil {
+		r.refs.Add(name, src)
+	}
+	if err == nil && r.setDefaults {
+		code = worseExit(code, printDefaulted(r.stdout, r.stderr, name, src))
+	}
+	return code, errs
+}
+
+// archiveFailed reports an archive that could not be opened at all.
+func (r *runner) archiveFailed(name string, err error) int {
+	r.prog.clear()
+	r.emit(validator.IOResult(name, err))
+	r.prog.advance(1)
+	return exitIO
+}
+
+// capReader stops a stream after left bytes, recording that it was
+// cut short so the caller can tell the limit from a corrupt archive.
+type capReader struct {
+	r        io.Reader
+	left     int64
+	exceeded bool
+}
+
+var errArchiveLimit = errors.New("archive size limit reached")
+
+func (c *capReader) Read(p []byte) (int, error) {
+	if c.left <= 0 {
+		// Only a stream that goes on past the limit is too large.
+		var probe [1]byte
+		if n, _ := c.r.Read(probe[:]); n == 0 {
+			return 0, io.EOF
+		}
+		c.exceeded = true
+		return 0, errArchiveLimit
+	}
+	if int64(len(p)) > c.left {
+		p = p[:c.left]
+	}
+	n, err := c.r.Read(p)
+	c.left -= int64(n)
+	return n, err
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// topLevelOrder is the canonical order of well-known top-level keys.
+// Other keys keep their relative order after these.
+var topLevelOrder = []string{"apiVersion", "kind", "metadata", "spec", "data", "stringData", "status"}
+
+// runFmt implements `fmt [--check|--write] FILE...`.
+func runFmt(prog string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(prog+" fmt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	check := fs.Bool("check", false, "exit non-zero and list files that are not formatted, without printing them")
+	write := fs.Bool("write", false, "rewrite files in place")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s fmt [--check | --write] FILE...\n", prog)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 || (*check && *write) {
+		fs.Usage()
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			code = exitIO
+			continue
+		}
+		out, err := formatYAML(src)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			code = worseExit(code, exitParse)
+			continue
+		}
+		switch {
+		case *check:
+			if !bytes.Equal(src, out) {
+				fmt.Fprintf(stdout, "%s\n", path)
+				if code == exitOK {
+					code = exitInvalid
+				}
+			}
+		case *write:
+			if bytes.Equal(src, out) {
+				continue
+			}
+			if err := writeFileAtomic(path, out); err != nil {
+				fmt.Fprintln(stderr, err)
+				code = exitIO
+			}
+		default:
+			if _, err := stdout.Write(out); err != nil {
+				fmt.Fprintln(stderr, err)
+				return exitIO
+			}
+		}
+	}
+	return code
+}
+
+// formatYAML normalizes the layout of every document in src through a
+// yaml.Node round trip, which keeps comments, anchors, merge keys and
+// block scalar content intact.
+func formatYAML(src []byte) ([]byte, error) {
+	return rewriteYAML(src, func(*yaml.Node) {})
+}
+
+// rewriteYAML decodes every document in src, applies edit to it and
+// re-encodes the stream in the canonical layout. Documents that hold no
+// more than comments are kept as they are, each on its own, and empty
+// ones, such as the one after a trailing "---", are left out, as they
+// are by validation.
+func rewriteYAML(src []byte, edit func(doc *yaml.Node)) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, d := range splitDocuments(src) {
+		body := d.body()
+		var out []byte
+		switch {
+		case len(bytes.TrimSpace(body)) == 0:
+			continue
+		case !hasYAMLContent(body):
+			out = append(bytes.TrimRight(body, "\r\n"), '\n')
+		default:
+			// Blank lines in front keep the decoder's line numbers those
+			// of src.
+			dec := yaml.NewDecoder(io.MultiReader(bytes.NewReader(bytes.Repeat([]byte("\n"), d.line-1)), bytes.NewReader(d.data)))
+			var doc yaml.Node
+			if err := dec.Decode(&doc); err != nil {
+				return nil, err
+			}
+			if len(doc.Content) == 0 || isNullNode(doc.Content[0]) {
+				continue
+			}
+			edit(&doc)
+			sortTopLevel(doc.Content[0])
+			normalizeNodes(&doc)
+			var err error
+			if out, err = encodeYAML(&doc); err != nil {
+				return nil, err
+			}
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(out)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeYAML encodes doc in the canonical layout.
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isNullNode reports whether n is a null scalar, which the validator
+// skips as an empty document.
+func isNullNode(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+}
+
+// docText is the text of one document of a stream, from its separator
+// on, and the line of src it starts on.
+type docText struct {
+	data []byte
+	line int
+}
+
+// body returns the text of d without a bare separator line.
+func (d docText) body() []byte {
+	first, rest, _ := bytes.Cut(d.data, []byte("\n"))
+	if string(bytes.TrimRight(first, "\r")) == "---" {
+		return rest
+	}
+	return d.data
+}
+
+// splitDocuments splits src into its documents at the "---" lines.
+// Directives stay with the document they precede.
+func splitDocuments(src []byte) []docText {
+	var docs []docText
+	cur := docText{line: 1}
+	for i, line := range bytes.SplitAfter(src, []byte("\n")) {
+		text := string(bytes.TrimRight(line, "\r\n"))
+		if (text == "---" || strings.HasPrefix(text, "--- ")) && !onlyDirectives(cur.data) {
+			docs = append(docs, cur)
+			cur = docText{line: i + 1}
+		}
+		cur.data = append(cur.data, line...)
+	}
+	return append(docs, cur)
+}
+
+// onlyDirectives reports whether data holds directives such as
+// "%YAML 1.2" and nothing but blank lines besides.
+func onlyDirectives(data []byte) bool {
+	found := false
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		switch t := bytes.TrimSpace(line); {
+		case len(t) == 0:
+		case t[0] == '%':
+			found = true
+		default:
+			return false
+		}
+	}
+	return found
+}
+
+// sortTopLevel moves the well-known keys of mapping m to the front in
+// canonical order, keeping the relative order of everything else. The
+// comment above the first key stays at the top of the document, and
+// nothing is moved if that would place an alias before its anchor.
+func sortTopLevel(m *yaml.Node) {
+	if m.Kind != yaml.MappingNode || len(m.Content) == 0 {
+		return
+	}
+	var head, rest []*yaml.Node
+	for _, key := range topLevelOrder {
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if m.Content[i].Value == key {
+				head = append(head, m.Content[i], m.Content[i+1])
+			}
+		}
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if !isTopLevelKey(m.Content[i].Value) {
+			rest = append(rest, m.Content[i], m.Content[i+1])
+		}
+	}
+	sorted := append(head, rest...)
+	if !anchorsBeforeAliases(sorted) {
+		return
+	}
+	if sorted[0] != m.Content[0] {
+		sorted[0].HeadComment, m.Content[0].HeadComment = m.Content[0].HeadComment, ""
+	}
+	m.Content = sorted
+}
+
+// anchorsBeforeAliases reports whether, in document order, every alias
+// in nodes refers to an anchor defined earlier.
+func anchorsBeforeAliases(nodes []*yaml.Node) bool {
+	seen := map[*yaml.Node]bool{}
+	var walk func(n *yaml.Node) bool
+	walk = func(n *yaml.Node) bool {
+		if n.Kind == yaml.AliasNode && !seen[n.Alias] {
+			return false
+		}
+		if n.Anchor != "" {
+			seen[n] = true
+		}
+		for _, c := range n.Content {
+			if !walk(c) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, n := range nodes {
+		if !walk(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTopLevelKey(k string) bool {
+	for _, key := range topLevelOrder {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeNodes settles on one style for strings that carry quotes:
+// single-quoted scalars become double-quoted, while plain, literal and
+// folded scalars are left alone. It also drops the resolved !!merge tag
+// from merge keys, which the encoder would otherwise spell out.
+func normalizeNodes(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Style&yaml.SingleQuotedStyle != 0 {
+		n.Style = n.Style&^yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!merge" && n.Style&yaml.TaggedStyle == 0 {
+		n.Tag = ""
+	}
+	for _, c := range n.Content {
+		normalizeNodes(c)
+	}
+}
+
+// writeFileAtomic replaces path with data through a temporary file in
+// the same directory, keeping the original permissions.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o644)
+	if st, err := os.Stat(path); err == nil {
+		mode = st.Mode().Perm()
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
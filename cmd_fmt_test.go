@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files of the tests")
+
+// golden compares got with the file at path, or writes it there under
+// -update.
+func golden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n--- got\n%s--- want\n%s", path, got, want)
+	}
+}
+
+func TestFmtGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "fmt", "*.yaml"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no inputs: %v", err)
+	}
+	for _, in := range inputs {
+		t.Run(filepath.Base(in), func(t *testing.T) {
+			code, out, errOut := runCLI(t, "fmt", in)
+			if code != exitOK {
+				t.Fatalf("exit %d: %s", code, errOut)
+			}
+			golden(t, strings.TrimSuffix(in, ".yaml")+".golden", []byte(out))
+			// The output is formatted already.
+			file := filepath.Join(t.TempDir(), "out.yaml")
+			if err := os.WriteFile(file, []byte(out), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if code, list, _ := runCLI(t, "fmt", "--check", file); code != exitOK {
+				t.Errorf("fmt --check on the output: exit %d, listed %q", code, list)
+			}
+		})
+	}
+}
+
+// failWriter fails every write.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestFmtWriteError(t *testing.T) {
+	var stderr bytes.Buffer
+	code := run([]string{"fmt", filepath.Join("testdata", "fmt", "trailing-separator.yaml")}, failWriter{}, &stderr)
+	if code != exitIO {
+		t.Errorf("exit %d, want %d", code, exitIO)
+	}
+	if !strings.Contains(stderr.String(), "broken pipe") {
+		t.Errorf("stderr %q does not report the write error", stderr.String())
+	}
+}
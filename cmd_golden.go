@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// expectedSuffix names the file holding the findings a case must
+// produce: case.yaml is checked against case.expected.json.
+const expectedSuffix = ".expected.json"
+
+// goldenFinding is the part of a finding a case pins down. File and
+// Fingerprint are left out so that cases can be moved around.
+type goldenFinding struct {
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (g goldenFinding) String() string {
+	pos := fmt.Sprintf("%d:%d", g.Line, g.Column)
+	msg := g.Message
+	if g.Field != "" {
+		msg = g.Field + " " + msg
+	}
+	return fmt.Sprintf("%-7s %-7s [%s] %s", pos, g.Severity, g.Code, msg)
+}
+
+// matches reports whether g and o are the same finding, allowing their
+// lines to differ by up to tolerance.
+func (g goldenFinding) matches(o goldenFinding, tolerance int) bool {
+	d := g.Line - o.Line
+	if d < 0 {
+		d = -d
+	}
+	g.Line, o.Line = 0, 0
+	return g == o && d <= tolerance
+}
+
+// runGolden implements `test [flags] DIR`: every case.yaml under DIR
+// that has a case.expected.json next to it is validated with the
+// active configuration and its findings compared with the expected
+// ones.
+func runGolden(prog string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(prog+" test", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "read policy configuration from `FILE`")
+	tolerance := fs.Int("line-tolerance", 0, "accept findings whose line is off by up to `N` lines")
+	update := fs.Bool("update", false, "rewrite the expected files with the findings produced, creating missing ones")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s test [--config FILE] [--line-tolerance N] [--update] DIR\n", prog)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 || *tolerance < 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	var opts validator.Options
+	var messages messageCatalog
+	if *configPath != "" {
+		cfg, err := loadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		cfg.apply(&opts)
+		messages = cfg.Messages
+	}
+
+	cases, err := goldenCases(fs.Arg(0), *update)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitIO
+	}
+	if len(cases) == 0 {
+		fmt.Fprintf(stderr, "%s: no test cases (want pairs of case.yaml and case%s)\n", fs.Arg(0), expectedSuffix)
+		return exitUsage
+	}
+	code, failed := exitOK, 0
+	for _, path := range cases {
+		got, err := goldenFindings(path, opts, messages)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			code = worseExit(code, exitIO)
+			continue
+		}
+		expPath := strings.TrimSuffix(path, filepath.Ext(path)) + expectedSuffix
+		want, err := readExpected(expPath)
+		if err != nil && !(*update && errors.Is(err, os.ErrNotExist)) {
+			fmt.Fprintln(stderr, err)
+			code = worseExit(code, exitIO)
+			continue
+		}
+		missing, unexpected := diffFindings(want, got, *tolerance)
+		if len(missing) == 0 && len(unexpected) == 0 && err == nil {
+			fmt.Fprintf(stdout, "ok   %s\n", path)
+			continue
+		}
+		if *update {
+			if err := writeExpected(expPath, got); err != nil {
+				fmt.Fprintln(stderr, err)
+				code = worseExit(code, exitIO)
+				continue
+			}
+			fmt.Fprintf(stdout, "upd  %s\n", path)
+			continue
+		}
+		failed++
+		fmt.Fprintf(stdout, "FAIL %s\n", path)
+		for _, g := range missing {
+			fmt.Fprintf(stdout, "  - %s\n", g)
+		}
+		for _, g := range unexpected {
+			fmt.Fprintf(stdout, "  + %s\n", g)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(stdout, "%d of %d cases failed (- expected but not produced, + produced but not expected)\n", failed, len(cases))
+		code = worseExit(code, exitInvalid)
+	}
+	return code
+}
+
+// goldenCases lists the manifests under dir that have an expected file,
+// or every manifest when all is set.
+func goldenCases(dir string, all bool) ([]string, error) {
+	var out []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !hasManifestExt(path) {
+			return err
+		}
+		exp := strings.TrimSuffix(path, filepath.Ext(path)) + expectedSuffix
+		if _, err := os.Stat(exp); err == nil || all {
+			out = append(out, path)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// goldenFindings validates path and returns its findings as a case
+// records them. Parse failures are findings too, so a case can expect
+// them; only unreadable inputs are errors.
+func goldenFindings(path string, opts validator.Options, messages messageCatalog) ([]goldenFinding, error) {
+	res, err := validator.ValidateFile(context.Background(), path, opts)
+	switch {
+	case err == nil:
+	case isIOError(err):
+		return nil, err
+	default:
+		res = validator.ParseResult(path, err)
+	}
+	out := []goldenFinding{}
+	for _, e := range res.Findings {
+		e = messages.render(e)
+		out = append(out, goldenFinding{Line: e.Line, Column: e.Column, Severity: e.Severity.String(),
+			Code: e.Code, Rule: e.Rule, Field: e.Field, Message: e.Message})
+	}
+	return out, nil
+}
+
+// diffFindings pairs every expected finding with an equal produced one
+// and returns those left over on either side.
+func diffFindings(want, got []goldenFinding, tolerance int) (missing, unexpected []goldenFinding) {
+	used := make([]bool, len(got))
+	for _, w := range want {
+		found := false
+		for i, g := range got {
+			if !used[i] && w.matches(g, tolerance) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, w)
+		}
+	}
+	for i, g := range got {
+		if !used[i] {
+			unexpected = append(unexpected, g)
+		}
+	}
+	return missing, unexpected
+}
+
+func readExpected(path string) ([]goldenFinding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []goldenFinding
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+func writeExpected(path string, findings []goldenFinding) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(findings); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// scaffolds maps the kinds `init` can generate to their templates.
+// Every template must produce a manifest that passes validation.
+var scaffolds = map[string]*template.Template{
+	"pod": template.Must(template.New("pod").Funcs(scaffoldFuncs).Parse(`apiVersion: v1
+kind: Pod
+metadata:
+  name: {{.Name}}
+  labels:
+    app: {{.Name}}
+spec:
+  containers:
+    - name: {{.ContainerName}}
+      image: {{yaml .Image}}
+      ports:
+        - containerPort: 8080
+          protocol: TCP
+      resources:
+        requests:
+          cpu: 100m
+          memory: 128Mi
+        limits:
+          cpu: 500m
+          memory: 256Mi
+      # livenessProbe:
+      #   httpGet:
+      #     path: /healthz
+      #     port: 8080
+      # readinessProbe:
+      #   httpGet:
+      #     path: /ready
+      #     port: 8080
+`)),
+	"deployment": template.Must(template.New("deployment").Funcs(scaffoldFuncs).Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Name}}
+  labels:
+    app: {{.Name}}
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{.Name}}
+  template:
+    metadata:
+      labels:
+        app: {{.Name}}
+    spec:
+      containers:
+        - name: {{.ContainerName}}
+          image: {{yaml .Image}}
+          ports:
+            - containerPort: 8080
+              protocol: TCP
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              cpu: 500m
+              memory: 256Mi
+          # livenessProbe:
+          #   httpGet:
+          #     path: /healthz
+          #     port: 8080
+          # readinessProbe:
+          #   httpGet:
+          #     path: /ready
+          #     port: 8080
+`)),
+}
+
+// scaffoldFuncs lets templates emit user input as safe YAML scalars.
+var scaffoldFuncs = template.FuncMap{
+	"yaml": func(s string) (string, error) {
+		b, err := yaml.Marshal(s)
+		return strings.TrimSpace(string(b)), err
+	},
+}
+
+type scaffoldData struct {
+	Name, ContainerName, Image string
+}
+
+// runInit implements `init KIND --name NAME --image IMAGE [--out FILE]
+// [--force]`. Flags may come before or after KIND.
+func runInit(prog string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(prog+" init", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	name := fs.String("name", "", "resource `NAME`")
+	image := fs.String("image", "", "container `IMAGE` reference")
+	out := fs.String("out", "", "write the manifest to `FILE` instead of stdout")
+	force := fs.Bool("force", false, "overwrite the --out file if it exists")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s init <%s> --name NAME --image IMAGE [--out FILE [--force]]\n", prog, strings.Join(scaffoldKinds(), "|"))
+		fs.PrintDefaults()
+	}
+	var kinds []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return exitOK
+			}
+			return exitUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		kinds, args = append(kinds, fs.Arg(0)), fs.Args()[1:]
+	}
+	if len(kinds) != 1 || *name == "" || *image == "" {
+		fs.Usage()
+		return exitUsage
+	}
+	tmpl, ok := scaffolds[strings.ToLower(kinds[0])]
+	if !ok {
+		fmt.Fprintf(stderr, "init: unknown kind %q (want one of %s)\n", kinds[0], strings.Join(scaffoldKinds(), ", "))
+		return exitUsage
+	}
+
+	data := scaffoldData{Name: dnsName(*name), ContainerName: dnsName(*name), Image: *image}
+	if data.Name == "" {
+		fmt.Fprintf(stderr, "init: --name %q has no usable characters\n", *name)
+		return exitUsage
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		fmt.Fprintln(stderr, "init:", err)
+		return exitUsage
+	}
+	// The scaffold must pass the validator it is meant to help with; a
+	// bad --image (or a stale template) is caught here, not later.
+	res, err := validator.ValidateBytes(context.Background(), "<init>", buf.Bytes(), validator.Options{})
+	if err != nil || !res.Valid() {
+		fmt.Fprintln(stderr, "init: generated manifest does not validate:")
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+		} else {
+			printResult(stderr, res)
+		}
+		return exitUsage
+	}
+
+	if *out == "" {
+		if _, err := stdout.Write(buf.Bytes()); err != nil {
+			fmt.Fprintln(stderr, "init:", err)
+			return exitIO
+		}
+		return exitOK
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(*out, flags, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		fmt.Fprintf(stderr, "init: %s exists; use --force to overwrite it\n", *out)
+		return exitIO
+	}
+	if err == nil {
+		_, err = f.Write(buf.Bytes())
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "init:", err)
+		return exitIO
+	}
+	return exitOK
+}
+
+func scaffoldKinds() []string {
+	kinds := make([]string, 0, len(scaffolds))
+	for k := range scaffolds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// dnsName lowercases s and replaces runs of other characters with
+// dashes, giving a valid DNS-1123 name for the resource and its
+// container alike.
+func dnsName(s string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(s) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			if pending && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pending = false
+			b.WriteRune(r)
+			continue
+		}
+		pending = true
+	}
+	return b.String()
+}
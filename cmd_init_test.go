@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+func TestInitScaffoldsValidate(t *testing.T) {
+	for _, kind := range scaffoldKinds() {
+		t.Run(kind, func(t *testing.T) {
+			code, out, errOut := runCLI(t, "init", kind, "--name", "Web API", "--image", "registry.example.com/team/web:1.0")
+			if code != exitOK {
+				t.Fatalf("exit %d: %s", code, errOut)
+			}
+			res, err := validator.ValidateBytes(context.Background(), kind+".yaml", []byte(out), validator.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Findings) > 0 {
+				t.Errorf("scaffold has findings: %v\n%s", res.Findings, out)
+			}
+			if len(res.Documents) != 1 || res.Documents[0].Name != "web-api" {
+				t.Errorf("Documents = %+v, want one named web-api", res.Documents)
+			}
+		})
+	}
+}
+
+func TestInitArgs(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "pod.yaml")
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"kind first", []string{"pod", "--name", "web", "--image", "nginx:1.25"}, exitOK},
+		{"flags first", []string{"--name", "web", "--image", "nginx:1.25", "deployment"}, exitOK},
+		{"force before kind", []string{"--force", "deployment", "--name", "web", "--image", "nginx:1.25"}, exitOK},
+		{"kind case", []string{"Pod", "--name", "web", "--image", "nginx:1.25"}, exitOK},
+		{"-h", []string{"-h"}, exitOK},
+		{"--help", []string{"--help"}, exitOK},
+		{"help after kind", []string{"pod", "--help"}, exitOK},
+		{"no kind", []string{"--name", "web", "--image", "nginx:1.25"}, exitUsage},
+		{"two kinds", []string{"pod", "deployment", "--name", "web", "--image", "nginx:1.25"}, exitUsage},
+		{"unknown kind", []string{"job", "--name", "web", "--image", "nginx:1.25"}, exitUsage},
+		{"no image", []string{"pod", "--name", "web"}, exitUsage},
+		{"bad flag", []string{"pod", "--nmae", "web"}, exitUsage},
+		{"bad image", []string{"pod", "--name", "web", "--image", "NGINX"}, exitUsage},
+		{"write", []string{"pod", "--name", "web", "--image", "nginx:1.25", "--out", out}, exitOK},
+		{"exists", []string{"pod", "--name", "web", "--image", "nginx:1.25", "--out", out}, exitIO},
+		{"overwrite", []string{"--force", "pod", "--name", "web", "--image", "nginx:1.25", "--out", out}, exitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, errOut := runCLI(t, append([]string{"init"}, tt.args...)...); code != tt.want {
+				t.Errorf("exit %d, want %d; stderr:\n%s", code, tt.want, errOut)
+			}
+		})
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Error(err)
+	}
+}
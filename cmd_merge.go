@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// runMerge implements `merge [--format F] REPORT...`: it reads the
+// --format=json or ndjson reports of shards of one run and writes them
+// as a single run in any output format.
+func runMerge(prog string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(prog+" merge", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "json", "merged `format`: text (on stderr), or json, ndjson, sarif, checkstyle, tap, markdown or codeclimate (on stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s merge [--format FORMAT] REPORT.json...\n", prog)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	switch *format {
+	case "text", "json", "ndjson", "sarif", "checkstyle", "tap", "markdown", "codeclimate":
+	default:
+		fmt.Fprintf(stderr, "unknown format %q (want text, json, ndjson, sarif, checkstyle, tap, markdown or codeclimate)\n", *format)
+		return exitUsage
+	}
+
+	var reports []*validator.Report
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitIO
+		}
+		rep, err := validator.ReadJSONReport(data)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			return exitUsage
+		}
+		reports = append(reports, rep)
+	}
+	merged := validator.MergeReports(reports...)
+
+	rep := newReporter(*format, prog, false, nil, stdout, stderr)
+	fr, _ := rep.(validator.FileReporter)
+	var file string
+	for _, e := range merged.Findings {
+		if fr != nil && (file == "" || e.File != file) {
+			fr.StartFile(e.File)
+		}
+		file = e.File
+		rep.Report(e)
+	}
+	if rr, ok := rep.(validator.ResourceReporter); ok {
+		for _, r := range merged.Resources {
+			rr.Resource(r)
+		}
+	}
+	if err := rep.Summary(merged.Stats); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitIO
+	}
+	fmt.Fprintln(stderr, merged.Stats)
+	if merged.Stats.Errors > 0 {
+		return exitInvalid
+	}
+	return exitOK
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// runPath implements `path FILE EXPR...` and `path --list FILE [PREFIX]`,
+// which print where fields of a manifest are, as "file:line:col value".
+func runPath(prog string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(prog+" path", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	list := fs.Bool("list", false, "list every path at or below PREFIX instead of looking paths up")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s path FILE EXPR...\n", prog)
+		fmt.Fprintf(stderr, "       %s path --list FILE [PREFIX]\n", prog)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() < 1 || (!*list && fs.NArg() < 2) || (*list && fs.NArg() > 2) {
+		fs.Usage()
+		return exitUsage
+	}
+	file := fs.Arg(0)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitIO
+	}
+	x, err := validator.IndexBytes(file, data)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitParse
+	}
+	exprs := fs.Args()[1:]
+	if *list {
+		paths, err := x.ListPaths(fs.Arg(1))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		exprs = paths
+	}
+	code := exitOK
+	for _, expr := range exprs {
+		e, err := x.Lookup(expr)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			code = exitInvalid
+			continue
+		}
+		line := fmt.Sprintf("%s:%d:%d", file, e.Line, e.Column)
+		if *list {
+			line += " " + e.Path
+		}
+		if e.Scalar {
+			line += " " + e.Value
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return code
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPathCommand(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"pod.yaml": "base: &base\n  image: nginx:1.25\n" + strings.Replace(testPod, "    image: nginx:1.25\n", "    <<: *base\n", 1),
+		"bad.yaml": "key: [unclosed\n",
+	})
+	pod := filepath.Join(dir, "pod.yaml")
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string // with FILE standing for the input
+	}{
+		{"lookup", []string{pod, "spec.containers[0].name", "metadata.name"}, exitOK,
+			"FILE:9:11 web\nFILE:6:9 web\n"},
+		{"merged key", []string{pod, "spec.containers[name=web].image"}, exitOK,
+			"FILE:2:10 nginx:1.25\n"},
+		{"not a scalar", []string{pod, "spec.containers"}, exitOK, "FILE:9:3\n"},
+		{"unknown path", []string{pod, "spec.nope", "kind"}, exitInvalid, "FILE:4:7 Pod\n"},
+		{"list", []string{"--list", pod, "spec.containers[0]"}, exitOK,
+			"FILE:9:5 spec.containers[name=web]\nFILE:9:11 spec.containers[name=web].name web\nFILE:2:10 spec.containers[name=web].image nginx:1.25\n"},
+		{"not YAML", []string{filepath.Join(dir, "bad.yaml"), "key"}, exitParse, ""},
+		{"missing file", []string{filepath.Join(dir, "gone.yaml"), "key"}, exitIO, ""},
+		{"no expression", []string{pod}, exitUsage, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, append([]string{"path"}, tt.args...)...)
+			if code != tt.code {
+				t.Errorf("exit %d, want %d:\n%s", code, tt.code, stderr)
+			}
+			if want := strings.ReplaceAll(tt.stdout, "FILE", pod); stdout != want {
+				t.Errorf("stdout\n%s\nwant\n%s", stdout, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// runRules implements `rules [--config FILE]`, which lists every code
+// with the check it stands for and the message override the config
+// gives it, so that the list reads like the findings do.
+func runRules(prog string, args []string, stdout, stderr io.Writer) int {
+	fs, configPath := catalogFlagSet(prog+" rules", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s rules [--config FILE]\n", prog)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	catalog, err := loadCatalog(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	for _, ch := range validator.Checks() {
+		fmt.Fprintf(stdout, "%s  %s\n", ch.Code, describeCheck(ch))
+		writeOverride(stdout, catalog.lookup(ch.Code, ch.Rule))
+	}
+	return exitOK
+}
+
+// runExplain implements `explain [--config FILE] CODE|RULE`, which
+// describes one check and the message its findings get.
+func runExplain(prog string, args []string, stdout, stderr io.Writer) int {
+	fs, configPath := catalogFlagSet(prog+" explain", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s explain [--config FILE] CODE|RULE\n", prog)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	catalog, err := loadCatalog(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	id := fs.Arg(0)
+	for _, ch := range validator.Checks() {
+		if ch.Code != id && ch.Rule != id {
+			continue
+		}
+		fmt.Fprintf(stdout, "%s  %s\n", ch.Code, describeCheck(ch))
+		if m := catalog.lookup(ch.Code, ch.Rule); m != nil {
+			writeOverride(stdout, m)
+		} else {
+			fmt.Fprintln(stdout, "    message: built in")
+		}
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "unknown code or rule %q\n", id)
+	return exitUsage
+}
+
+// catalogFlagSet returns the flags of the commands that show the
+// message catalog.
+func catalogFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs, fs.String("config", "", "show the message overrides of the configuration `FILE`")
+}
+
+// loadCatalog returns the message catalog of the configuration at path,
+// or an empty one when path is empty.
+func loadCatalog(path string) (messageCatalog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Messages, nil
+}
+
+// describeCheck names what ch checks: its rule, or the category and
+// fields of its findings.
+func describeCheck(ch validator.Check) string {
+	switch {
+	case ch.Rule == "require:":
+		return "require: (fields made mandatory by the config)"
+	case ch.Rule != "":
+		return ch.Rule
+	case ch.Fields == nil:
+		return ch.Category.String() + " (any other finding)"
+	case len(ch.Fields) == 1 && ch.Fields[0] == "":
+		return ch.Category.String() + " (the document)"
+	}
+	return ch.Category.String() + " " + strings.Join(ch.Fields, ", ")
+}
+
+// writeOverride writes the message template and hint of m, if any, as
+// indented lines.
+func writeOverride(w io.Writer, m *messageOverride) {
+	if m == nil {
+		return
+	}
+	if m.Message != "" {
+		fmt.Fprintf(w, "    message: %s\n", m.Message)
+	}
+	if m.Hint != "" {
+		fmt.Fprintf(w, "    hint: %s\n", m.Hint)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCatalog = `messages:
+  PV010:
+    message: "container name '{{.Field}}' at {{.Pos}} breaks the naming policy"
+    hint: see the naming runbook
+  latest-tag:
+    hint: see the registry policy
+`
+
+func TestRulesShowsOverrides(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.yaml": testCatalog})
+	code, out, errOut := runCLI(t, "rules", "--config", filepath.Join(dir, "config.yaml"))
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	for _, want := range []string{
+		"PV010  format containers[].name\n    message: container name '{{.Field}}' at {{.Pos}} breaks the naming policy\n    hint: see the naming runbook\n",
+		"PV130  latest-tag\n    hint: see the registry policy\nPV131  host-port\n",
+		"PV011  format containers[].image\nPV012  ",
+		"PV901  required (any other finding)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.yaml": testCatalog})
+	config := filepath.Join(dir, "config.yaml")
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"code with override", []string{"--config", config, "PV010"}, exitOK,
+			"PV010  format containers[].name\n    message: container name '{{.Field}}' at {{.Pos}} breaks the naming policy\n    hint: see the naming runbook\n"},
+		{"rule with hint", []string{"--config", config, "latest-tag"}, exitOK, "PV130  latest-tag\n    hint: see the registry policy\n"},
+		{"code of a rule", []string{"--config", config, "PV130"}, exitOK, "PV130  latest-tag\n    hint: see the registry policy\n"},
+		{"no override", []string{"--config", config, "PV021"}, exitOK, "PV021  format resources.*.memory\n    message: built in\n"},
+		{"no config", []string{"PV010"}, exitOK, "PV010  format containers[].name\n    message: built in\n"},
+		{"unknown", []string{"PV999"}, exitUsage, ""},
+		{"no argument", nil, exitUsage, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out, errOut := runCLI(t, append([]string{"explain"}, tt.args...)...)
+			if code != tt.code || out != tt.want {
+				t.Errorf("exit %d, output %q (stderr %q); want %d, %q", code, out, errOut, tt.code, tt.want)
+			}
+		})
+	}
+}
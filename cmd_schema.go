@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// runOutputSchema implements `output-schema`, which prints the JSON
+// Schema of the --format=json output.
+func runOutputSchema(prog string, args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		fmt.Fprintf(stderr, "usage: %s output-schema\n", prog)
+		return exitUsage
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(validator.OutputSchema()); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitIO
+	}
+	return exitOK
+}
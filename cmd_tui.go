@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// snippetContext is the number of source lines shown above and below
+// the selected finding.
+const snippetContext = 3
+
+// runTUI implements `tui [flags] PATH...`: it validates every input once
+// and then lets the user browse the results. Navigation never
+// re-validates; only opening a file in $EDITOR leaves the program.
+func runTUI(prog string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(prog+" tui", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	optFlags := addOptionFlags(fs)
+	followSymlinks := fs.Bool("follow-symlinks", true, "follow symbolic links when walking directories; false skips them")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s tui [--config FILE] [--follow-symlinks=false] PATH...\n", prog)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	logger, _ := newLogger(stderr, "warn", "text")
+	opts, messages, ok := optFlags.options(logger, stderr)
+	if !ok {
+		return exitUsage
+	}
+	w := &walker{followSymlinks: *followSymlinks, glob: true, log: logger}
+	names, _ := newPathMode("as-given", "")
+	lang, _ := findingLanguage("")
+	col := &resultCollector{}
+	r := &runner{opts: opts, maxArchive: defaultMaxArchiveSize, rep: col, messages: messages, lang: lang, fetchTimeout: defaultFetchTimeout,
+		names: names, log: logger, stdin: os.Stdin, stdout: io.Discard, stderr: stderr, sources: map[string][]byte{}}
+	code := exitOK
+	for _, arg := range fs.Args() {
+		paths, err := w.expand(arg)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			code = worseExit(code, exitNoFiles)
+		}
+		for _, path := range paths {
+			var c int
+			switch {
+			case path == stdinArg:
+				c = r.validateStdin()
+			case isArchive(path) && !isURL(path):
+				c = r.validateArchive(path)
+			default:
+				c = r.validatePath(path, nil)
+			}
+			code = worseExit(code, c)
+		}
+	}
+	results := col.results
+
+	if !isTerminal(stdout) || !isTerminal(os.Stdin) {
+		fmt.Fprintln(stderr, "tui: not a terminal; printing findings instead")
+		for _, res := range results {
+			printResult(stderr, res)
+		}
+		return code
+	}
+	t, err := openTerminal(stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, "tui:", err)
+		return exitIO
+	}
+	defer t.restore()
+	m := &browser{results: results, sources: r.sources}
+	m.browse(t)
+	return code
+}
+
+// resultCollector is the Reporter of tui: it gathers the findings of
+// a run into one Result per input, in the order the inputs come.
+type resultCollector struct {
+	results []*validator.Result
+	index   map[string]int
+}
+
+func (c *resultCollector) StartFile(name string) { c.result(name) }
+
+func (c *resultCollector) Report(e *validator.ValidationError) {
+	res := c.result(e.File)
+	res.Findings = append(res.Findings, e)
+}
+
+func (c *resultCollector) Summary(validator.Stats) error { return nil }
+
+// result returns the Result for the input called name, adding it on
+// first use.
+func (c *resultCollector) result(name string) *validator.Result {
+	if c.index == nil {
+		c.index = map[string]int{}
+	}
+	i, ok := c.index[name]
+	if !ok {
+		i = len(c.results)
+		c.index[name] = i
+		c.results = append(c.results, &validator.Result{File: name})
+	}
+	return c.results[i]
+}
+
+// browser is the state of the findings browser.
+type browser struct {
+	results []*validator.Result
+	sources map[string][]byte // inputs not read from a file of their name
+
+	file     int // selected file; -1 while the file list is shown
+	cursor   int // selected row in the current list
+	severity string
+	rule     string // "" shows every rule
+	status   string
+}
+
+// browse reads keys from t until the user quits.
+func (b *browser) browse(t *terminal) {
+	b.file = -1
+	for {
+		t.draw(b.render(t.rows, t.cols))
+		key, err := t.readKey()
+		if err != nil || key == "q" || key == "\x03" {
+			return
+		}
+		b.status = ""
+		switch key {
+		case "j", "down":
+			b.cursor++
+		case "k", "up":
+			b.cursor--
+		case "\r", "l", "right":
+			if b.file < 0 && len(b.results) > 0 {
+				b.file, b.cursor = b.cursor, 0
+			}
+		case "h", "left", "\x7f", "esc":
+			if b.file >= 0 {
+				b.file, b.cursor = -1, b.file
+			}
+		case "s":
+			b.severity = next([]string{"", "error", "warning"}, b.severity)
+			b.cursor = 0
+		case "r":
+			b.rule = next(append([]string{""}, b.rules()...), b.rule)
+			b.cursor = 0
+		case "o":
+			b.open(t)
+		}
+		b.clamp()
+	}
+}
+
+// open starts $EDITOR at the selected finding, handing it the terminal.
+func (b *browser) open(t *terminal) {
+	e := b.selected()
+	editor := os.Getenv("EDITOR")
+	if e == nil || editor == "" || b.sources[e.File] != nil || strings.Contains(e.File, "!") {
+		b.status = "nothing to open (is $EDITOR set?)"
+		return
+	}
+	line := max(e.Line, 1)
+	t.restore()
+	cmd := exec.Command(editor, "+"+strconv.Itoa(line), e.File)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		b.status = "editor: " + err.Error()
+	}
+	if err := t.raw(); err != nil {
+		b.status = err.Error()
+	}
+}
+
+// findings returns the selected file's findings that pass the filters.
+func (b *browser) findings(file int) []*validator.ValidationError {
+	var out []*validator.ValidationError
+	for _, e := range b.results[file].Findings {
+		if b.severity != "" && e.Severity.String() != b.severity {
+			continue
+		}
+		if b.rule != "" && ruleLabel(e) != b.rule {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func (b *browser) selected() *validator.ValidationError {
+	if b.file < 0 {
+		return nil
+	}
+	fs := b.findings(b.file)
+	if b.cursor < 0 || b.cursor >= len(fs) {
+		return nil
+	}
+	return fs[b.cursor]
+}
+
+// rules lists the rule labels present in the run, for the rule filter.
+func (b *browser) rules() []string {
+	var out []string
+	for _, res := range b.results {
+		for _, e := range res.Findings {
+			if l := ruleLabel(e); !slices.Contains(out, l) {
+				out = append(out, l)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// ruleLabel names what produced e: its rule, or its category for
+// findings that have none.
+func ruleLabel(e *validator.ValidationError) string {
+	if e.Rule != "" {
+		return e.Rule
+	}
+	return e.Category.String()
+}
+
+func (b *browser) clamp() {
+	n := len(b.results)
+	if b.file >= 0 {
+		n = len(b.findings(b.file))
+	}
+	b.cursor = min(max(b.cursor, 0), max(n-1, 0))
+}
+
+// render lays out one screen of at most rows lines.
+func (b *browser) render(rows, cols int) []string {
+	var lines []string
+	filter := "all severities"
+	if b.severity != "" {
+		filter = b.severity + "s"
+	}
+	if b.rule != "" {
+		filter += ", rule " + b.rule
+	}
+	if b.file < 0 {
+		lines = append(lines, fmt.Sprintf("%d files (%s)", len(b.results), filter))
+		for i, res := range b.results {
+			lines = append(lines, row(i == b.cursor, clip(fmt.Sprintf("%4d  %s", len(b.findings(i)), res.File), cols)))
+		}
+	} else {
+		fs := b.findings(b.file)
+		lines = append(lines, fmt.Sprintf("%s: %d findings (%s)", b.results[b.file].File, len(fs), filter))
+		for i, e := range fs {
+			lines = append(lines, row(i == b.cursor, clip(fmt.Sprintf("%5d  %-7s %s", e.Line, e.Severity, e), cols)))
+		}
+	}
+	var pane []string
+	if e := b.selected(); e != nil {
+		pane = b.snippet(e)
+	}
+	help := "j/k move  enter open  h back  s severity  r rule  o editor  q quit"
+	if b.status != "" {
+		help = b.status
+	}
+	// Keep the cursor row on screen above the snippet pane.
+	room := max(rows-len(pane)-2, 2)
+	if len(lines) > room {
+		start := min(max(b.cursor+1-room/2, 1), len(lines)-room+1)
+		lines = append(lines[:1], lines[start:start+room-1]...)
+	}
+	for len(lines) < rows-len(pane)-1 {
+		lines = append(lines, "")
+	}
+	for _, l := range append(pane, help) {
+		lines = append(lines, clip(l, cols))
+	}
+	lines[0] = clip(lines[0], cols)
+	return lines
+}
+
+// clip shortens s to at most cols runes.
+func clip(s string, cols int) string {
+	if r := []rune(s); len(r) > cols {
+		return string(r[:cols])
+	}
+	return s
+}
+
+func row(selected bool, s string) string {
+	if selected {
+		return "\x1b[7m" + s + "\x1b[0m"
+	}
+	return s
+}
+
+// snippet returns the source around e with a caret under its column.
+// Findings without a line, or whose source is gone, have no snippet.
+func (b *browser) snippet(e *validator.ValidationError) []string {
+	if e.Line == 0 {
+		return nil
+	}
+	lines := sourceLines(e.File)
+	if src, ok := b.sources[e.File]; ok {
+		lines = snippetLines(src)
+	}
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1] // after the final newline
+	}
+	if e.Line > len(lines) {
+		return nil
+	}
+	out := []string{strings.Repeat("─", 40)}
+	for n := max(e.Line-snippetContext, 1); n <= min(e.Line+snippetContext, len(lines)); n++ {
+		text := lines[n-1]
+		out = append(out, fmt.Sprintf("%5d | %s", n, text))
+		if n == e.Line && e.Column > 0 {
+			out = append(out, gutter(0)+caretIndent(text, e.Column)+"^")
+		}
+	}
+	return out
+}
+
+func next(options []string, cur string) string {
+	i := slices.Index(options, cur)
+	return options[(i+1)%len(options)]
+}
+
+// terminal puts the controlling terminal in raw mode through stty,
+// which keeps the program free of platform-specific ioctls.
+type terminal struct {
+	out        io.Writer
+	in         *bufio.Reader
+	saved      string
+	rows, cols int
+}
+
+func openTerminal(out io.Writer) (*terminal, error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("cannot query terminal: %w", err)
+	}
+	t := &terminal{out: out, in: bufio.NewReader(os.Stdin), saved: strings.TrimSpace(saved), rows: 24, cols: 80}
+	var rows, cols int
+	if size, err := stty("size"); err == nil {
+		if _, err := fmt.Sscan(size, &rows, &cols); err == nil && rows > 0 && cols > 0 {
+			t.rows, t.cols = rows, cols
+		}
+	}
+	return t, t.raw()
+}
+
+func (t *terminal) raw() error {
+	_, err := stty("raw", "-echo")
+	fmt.Fprint(t.out, "\x1b[?1049h\x1b[?25l")
+	return err
+}
+
+func (t *terminal) restore() {
+	fmt.Fprint(t.out, "\x1b[?25h\x1b[?1049l")
+	stty(t.saved)
+}
+
+func (t *terminal) draw(lines []string) {
+	fmt.Fprint(t.out, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n"))
+}
+
+// readKey returns one key press, naming the arrow keys and a lone
+// escape.
+func (t *terminal) readKey() (string, error) {
+	c, err := t.in.ReadByte()
+	if err != nil || c != 0x1b {
+		return string(c), err
+	}
+	if t.in.Buffered() == 0 {
+		return "esc", nil
+	}
+	seq := make([]byte, 2)
+	if _, err := io.ReadFull(t.in, seq); err != nil {
+		return "", err
+	}
+	switch seq[1] {
+	case 'A':
+		return "up", nil
+	case 'B':
+		return "down", nil
+	case 'C':
+		return "right", nil
+	case 'D':
+		return "left", nil
+	}
+	return "", nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
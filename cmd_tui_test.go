@@ -0,0 +1,152 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// TestTUIOptions checks that tui, printing findings when not on a
+// terminal, validates with the same options as a plain run.
+func TestTUIOptions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"pod.yaml":    strings.Replace(testPod, "nginx:1.25", "nginx:latest", 1),
+		"config.yaml": "require:\n- metadata.labels.team\n",
+	})
+	pod, config := filepath.Join(dir, "pod.yaml"), filepath.Join(dir, "config.yaml")
+	tests := []struct {
+		name    string
+		args    []string
+		code    int
+		want    []string
+		notWant []string
+	}{
+		{"defaults", []string{pod}, exitOK, []string{"[PV130]"}, []string{"[PV137]"}},
+		{"config require", []string{"--config", config, pod}, exitInvalid, []string{"[PV137] metadata.labels.team is required"}, nil},
+		{"disabled rule", []string{"--disable-rules", "latest-tag", pod}, exitOK, nil, []string{"[PV130]"}},
+		{"max file size", []string{"--max-file-size", "16", pod}, exitIO, []string{"[PV907]"}, nil},
+		{"bad config", []string{"--config", filepath.Join(dir, "missing.yaml"), pod}, exitUsage, []string{"cannot read config"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errOut := runCLI(t, append([]string{"tui"}, tt.args...)...)
+			if code != tt.code {
+				t.Errorf("exit %d, want %d: %s", code, tt.code, errOut)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(errOut, s) {
+					t.Errorf("output lacks %q:\n%s", s, errOut)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(errOut, s) {
+					t.Errorf("output has %q:\n%s", s, errOut)
+				}
+			}
+		})
+	}
+}
+
+// badNamePod is testPod with a container name that breaks PV010 on
+// line 7.
+var badNamePod = strings.Replace(testPod, "- name: web", "- name: Web", 1)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzBytes(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return gzipBytes(t, buf.Bytes())
+}
+
+// TestTUIInputs checks that tui reads every kind of input a plain run
+// does and reports unparsable ones the same way.
+func TestTUIInputs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"bad.yaml":    "key: [unclosed\n",
+		"pod.yaml.gz": string(gzipBytes(t, []byte(badNamePod))),
+		"pods.tgz":    string(tarGzBytes(t, "pod.yaml", []byte(badNamePod))),
+		"stdin.yaml":  badNamePod,
+	})
+	in := func(name string) string { return filepath.Join(dir, name) }
+	stdin, err := os.Open(in("stdin.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	saved := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = saved }()
+
+	code, _, errOut := runCLI(t, "tui", in("bad.yaml"), in("pod.yaml.gz"), in("pods.tgz"), "-")
+	if code != exitParse {
+		t.Errorf("exit %d, want %d", code, exitParse)
+	}
+	for _, want := range []string{
+		in("bad.yaml") + ":1 [PV908] cannot unmarshal file content",
+		in("pod.yaml.gz") + ":7:5 [PV010]",
+		in("pods.tgz") + "!pod.yaml:7:5 [PV010]",
+		"<stdin>:7:5 [PV010]",
+	} {
+		if !strings.Contains(errOut, want) {
+			t.Errorf("output lacks %q:\n%s", want, errOut)
+		}
+	}
+	if strings.Contains(errOut, in("bad.yaml")+": "+in("bad.yaml")) {
+		t.Errorf("file name repeated in the message:\n%s", errOut)
+	}
+}
+
+func TestBrowserSnippet(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"pod.yaml":    badNamePod,
+		"pod.yaml.gz": string(gzipBytes(t, []byte(badNamePod))),
+	})
+	member := filepath.Join(dir, "pods.tgz") + "!pod.yaml"
+	b := &browser{sources: map[string][]byte{member: []byte(badNamePod), "<stdin>": []byte(badNamePod)}}
+	want := []string{
+		strings.Repeat("─", 40),
+		"    4 |   name: web",
+		"    5 | spec:",
+		"    6 |   containers:",
+		"    7 |   - name: Web",
+		"      |     ^",
+		"    8 |     image: nginx:1.25",
+	}
+	for _, file := range []string{filepath.Join(dir, "pod.yaml"), filepath.Join(dir, "pod.yaml.gz"), member, "<stdin>"} {
+		got := b.snippet(&validator.ValidationError{File: file, Line: 7, Column: 5})
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: snippet\n%s\nwant\n%s", file, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+	if got := b.snippet(&validator.ValidationError{File: filepath.Join(dir, "missing.yaml"), Line: 7}); got != nil {
+		t.Errorf("snippet of a missing file: %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// ANSI SGR sequences used by the colored text format.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// useColor resolves --color against the environment: auto colors a
+// terminal unless NO_COLOR is set or TERM is dumb; always and never
+// ignore both.
+func useColor(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return isTerminal(w) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb", nil
+	}
+	return false, fmt.Errorf("invalid --color %q: want auto, always or never", mode)
+}
+
+// quotedValue matches the values messages quote, as in "has invalid
+// format '12XB'".
+var quotedValue = regexp.MustCompile(`'[^']*'`)
+
+// colorTextReporter writes the text format with the position in bold,
+// the field in cyan, quoted values in red and missing fields in yellow.
+type colorTextReporter struct {
+	w   io.Writer
+	err error
+}
+
+func (t *colorTextReporter) Report(e *validator.ValidationError) { t.write(colorText(e)) }
+
+func (t *colorTextReporter) write(line string) {
+	if _, err := io.WriteString(t.w, line+"\n"); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *colorTextReporter) Summary(s validator.Stats) error {
+	if s.Omitted > 0 {
+		t.write(s.OmittedNotice())
+	}
+	return t.err
+}
+
+func colorText(e *validator.ValidationError) string {
+	pos := e.File + ":"
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		pos = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	case e.Line > 0:
+		pos = fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	msg := quotedValue.ReplaceAllString(e.Message, ansiBold+ansiRed+"$0"+ansiReset)
+	if e.Category == validator.CategoryRequired {
+		msg = ansiYellow + e.Message + ansiReset
+	}
+	if e.Field != "" {
+		msg = ansiCyan + e.Field + ansiReset + " " + msg
+	}
+	if e.Occurrences > 1 {
+		msg += fmt.Sprintf(" (x%d)", e.Occurrences)
+	}
+	if e.Code != "" {
+		msg = "[" + e.Code + "] " + msg
+	}
+	if e.Severity == validator.SeverityWarning {
+		msg = ansiBold + ansiYellow + "warning:" + ansiReset + " " + msg
+	}
+	return ansiBold + pos + ansiReset + " " + msg
+}
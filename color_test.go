@@ -0,0 +1,75 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+func TestColorText(t *testing.T) {
+	tests := []struct {
+		name string
+		e    validator.ValidationError
+		want string
+	}{
+		{"quoted value in red",
+			validator.ValidationError{File: "pod.yaml", Line: 5, Column: 9, Field: "metadata.name", Code: "PV010",
+				Message: "has invalid format 'Web_Server'", Category: validator.CategoryFormat},
+			"\x1b[1mpod.yaml:5:9\x1b[0m [PV010] \x1b[36mmetadata.name\x1b[0m has invalid format \x1b[1m\x1b[31m'Web_Server'\x1b[0m"},
+		{"required in yellow",
+			validator.ValidationError{File: "pod.yaml", Line: 1, Column: 1, Field: "kind", Code: "PV152",
+				Message: "is required (found: 'apiVersion')", Category: validator.CategoryRequired},
+			"\x1b[1mpod.yaml:1:1\x1b[0m [PV152] \x1b[36mkind\x1b[0m \x1b[33mis required (found: 'apiVersion')\x1b[0m"},
+		{"warning",
+			validator.ValidationError{File: "pod.yaml", Line: 7, Field: "spec.containers[name=web].image", Code: "PV130",
+				Message: "uses the 'latest' tag", Category: validator.CategoryFormat, Severity: validator.SeverityWarning},
+			"\x1b[1mpod.yaml:7\x1b[0m \x1b[1m\x1b[33mwarning:\x1b[0m [PV130] \x1b[36mspec.containers[name=web].image\x1b[0m uses the \x1b[1m\x1b[31m'latest'\x1b[0m tag"},
+		{"no position or field",
+			validator.ValidationError{File: "gone.yaml", Message: "read error", Category: validator.CategoryIO},
+			"\x1b[1mgone.yaml:\x1b[0m read error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := colorText(&tt.e); got != tt.want {
+				t.Errorf("colorText = %q\nwant        %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunColor(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"pod.yaml": strings.Replace(testPod, "- name: web", "- name: Web_Server", 1)})
+	tests := []struct {
+		name    string
+		args    []string
+		noColor string // the NO_COLOR environment variable
+		want    bool
+	}{
+		{"auto off a terminal", nil, "", false},
+		{"always", []string{"--color", "always"}, "", true},
+		{"always despite NO_COLOR", []string{"--color", "always"}, "1", true},
+		{"never", []string{"--color", "never"}, "", false},
+		{"--no-color", []string{"--no-color"}, "", false},
+		{"--no-color wins over always", []string{"--color", "always", "--no-color"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			code, _, stderr := runCLI(t, append(tt.args, filepath.Join(dir, "pod.yaml"))...)
+			if code != exitInvalid {
+				t.Fatalf("exit %d, want %d:\n%s", code, exitInvalid, stderr)
+			}
+			if got := strings.Contains(stderr, "\x1b["); got != tt.want {
+				t.Errorf("escape sequences: %t, want %t:\n%q", got, tt.want, stderr)
+			}
+			if tt.want && !strings.Contains(stderr, "\x1b[1m\x1b[31m'Web_Server'\x1b[0m") {
+				t.Errorf("the offending value is not highlighted:\n%q", stderr)
+			}
+		})
+	}
+	if _, err := useColor("sometimes", nil); err == nil {
+		t.Error("useColor accepted --color sometimes")
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// config is the file read by --config.
+type config struct {
+	Images   *validator.ImagePolicy    `yaml:"images"`
+	Require  []validator.RequiredField `yaml:"require"`
+	Messages messageCatalog            `yaml:"messages"`
+	Limits   validator.Limits          `yaml:"limits"`
+}
+
+// loadConfig reads and checks the configuration file at path. Unknown
+// keys are rejected so that typos do not silently disable a policy.
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config: %w", err)
+	}
+	// Keys left out of limits keep their defaults.
+	cfg := config{Limits: validator.DefaultLimits}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Images != nil {
+		if err := cfg.Images.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for i := range cfg.Require {
+		var err error
+		if cfg.Require[i], err = cfg.Require[i].Compile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := cfg.Messages.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// apply copies the configuration into opts.
+func (c *config) apply(opts *validator.Options) {
+	opts.ImagePolicy = c.Images
+	opts.Require = c.Require
+	opts.Limits = &c.Limits
+}
+
+// optionFlags are the flags that make up the validator.Options of a
+// run, shared by the commands that validate.
+type optionFlags struct {
+	maxFileSize               sizeFlag
+	selectExpr                *string
+	nested, coerce            *bool
+	k8sVersion                *string
+	disableRules, enableRules *string
+	kinds, skipKinds          *string
+	imageLock                 *string
+	requireLocked             *bool
+	configPath                *string
+}
+
+// addOptionFlags defines the option flags on fs.
+func addOptionFlags(fs *flag.FlagSet) *optionFlags {
+	f := &optionFlags{maxFileSize: sizeFlag(validator.DefaultMaxFileSize)}
+	fs.Var(&f.maxFileSize, "max-file-size", "refuse inputs larger than `SIZE` (e.g. 10MiB, 512K); 0 disables the limit")
+	f.selectExpr = fs.String("select", "", "only report findings under the field at `PATH`, e.g. 'spec.containers[name=web]'")
+	f.nested = fs.Bool("nested", false, "also validate the ConfigMap data values under keys ending in .yaml or .yml as manifests, reported as FILE[data/KEY]")
+	f.coerce = fs.Bool("coerce-scalars", false, "accept unquoted numbers and booleans where a string is required, with a warning")
+	f.k8sVersion = fs.String("k8s-version", "", "report APIs and fields deprecated or removed as of Kubernetes `VERSION` (e.g. 1.29)")
+	f.disableRules = fs.String("disable-rules", "", "comma-separated rule IDs or groups (e.g. windows) to switch off")
+	f.enableRules = fs.String("enable-rules", "", "comma-separated opt-in rule IDs or groups (e.g. probe-port) to switch on")
+	f.kinds = fs.String("kinds", "", "only validate documents of these comma-separated `KINDS`, e.g. Pod,apps/Deployment")
+	f.skipKinds = fs.String("skip-kinds", "", "do not validate documents of these comma-separated `KINDS`")
+	f.imageLock = fs.String("image-lock", "", "require images of the repositories listed in lockfile `FILE` to pin the locked digest")
+	f.requireLocked = fs.Bool("require-locked", false, "with --image-lock, warn about images whose repository the lockfile does not list")
+	f.configPath = fs.String("config", "", "read policy configuration from `FILE`")
+	return f
+}
+
+// options builds the Options the flags ask for, along with the message
+// catalog of --config. Problems are written to stderr, in which case ok
+// is false and the command should exit with exitUsage.
+func (f *optionFlags) options(logger *slog.Logger, stderr io.Writer) (opts validator.Options, messages messageCatalog, ok bool) {
+	opts = validator.Options{MaxFileSize: int64(f.maxFileSize), Logger: logger, Select: *f.selectExpr, CoerceScalars: *f.coerce, Nested: *f.nested}
+	if *f.disableRules != "" {
+		opts.DisableRules = strings.Split(*f.disableRules, ",")
+	}
+	if *f.enableRules != "" {
+		opts.EnableRules = strings.Split(*f.enableRules, ",")
+	}
+	if *f.kinds != "" {
+		opts.Kinds = strings.Split(*f.kinds, ",")
+	}
+	if *f.skipKinds != "" {
+		opts.SkipKinds = strings.Split(*f.skipKinds, ",")
+	}
+	if err := validator.CheckKindFilters(opts.Kinds, opts.SkipKinds); err != nil {
+		fmt.Fprintln(stderr, err)
+		return opts, nil, false
+	}
+	if *f.k8sVersion != "" {
+		v, err := validator.ParseKubeVersion(*f.k8sVersion)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return opts, nil, false
+		}
+		opts.KubernetesVersion = v
+	}
+	if *f.imageLock != "" {
+		data, err := os.ReadFile(*f.imageLock)
+		if err != nil {
+			fmt.Fprintln(stderr, "cannot read image lock:", err)
+			return opts, nil, false
+		}
+		lock, err := validator.ParseImageLock(data)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", *f.imageLock, err)
+			return opts, nil, false
+		}
+		lock.RequireLocked = *f.requireLocked
+		opts.ImageLock = lock
+	} else if *f.requireLocked {
+		fmt.Fprintln(stderr, "--require-locked needs --image-lock")
+		return opts, nil, false
+	}
+	if *f.configPath != "" {
+		cfg, err := loadConfig(*f.configPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return opts, nil, false
+		}
+		cfg.apply(&opts)
+		messages = cfg.Messages
+		messages.warnUnknown(logger, cfg.Require)
+	}
+	return opts, messages, true
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around each hunk.
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' keeps it, '-' deletes it
+// from the old text and '+' inserts it from the new one.
+type diffOp struct {
+	kind byte
+	line string // with its line ending, if it has one
+}
+
+// unifiedDiff returns the diff turning a into b in the unified format
+// patch reads, with both sides named name, or "" when they are equal.
+func unifiedDiff(name string, a, b []byte) string {
+	ops := diffLines(splitLines(string(a)), splitLines(string(b)))
+	var out strings.Builder
+	// ai and bi count the lines of each side before ops[i].
+	ai, bi := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		ai[i+1], bi[i+1] = ai[i], bi[i]
+		if op.kind != '+' {
+			ai[i+1]++
+		}
+		if op.kind != '-' {
+			bi[i+1]++
+		}
+	}
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk while the next change is close enough for
+		// their context to touch.
+		start, end := max(i-diffContext, 0), i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				if j > end+2*diffContext {
+					break
+				}
+				end = j + 1
+			}
+		}
+		end = min(end+diffContext, len(ops))
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", name, name)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(ai[start], ai[end]-ai[start]), hunkRange(bi[start], bi[end]-bi[start]))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange renders the "start,count" of one side of a hunk header,
+// where start counts from 1 and an empty side names the line before it.
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits s after every newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns a shortest edit script turning a into b, found with
+// Myers' O(ND) algorithm.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	off := n + m + 1
+	v := make([]int, 2*off+1)
+	var trace [][]int
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var rev []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			rev = append(rev, diffOp{' ', a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				rev = append(rev, diffOp{'+', b[y]})
+			} else {
+				x--
+				rev = append(rev, diffOp{'-', a[x]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	ops := make([]diffOp, len(rev))
+	for i, op := range rev {
+		ops[len(rev)-1-i] = op
+	}
+	return ops
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// defaultFetchTimeout bounds how long fetching one URL may take.
+const defaultFetchTimeout = 10 * time.Second
+
+// isURL reports whether arg is an http or https URL to fetch rather
+// than a path.
+func isURL(arg string) bool {
+	u, err := url.Parse(arg)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// fetch downloads rawURL within the runner's timeout, reading no more
+// of the body than Options.MaxFileSize allows. Failures wrap
+// validator.ErrIO, so they are reported like unreadable files.
+func (r *runner) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	if r.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.fetchTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", rawURL, validator.ErrIO, err)
+	}
+	req.Header.Set("Accept", "application/yaml, application/json;q=0.9, */*;q=0.8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("no response within %v", r.fetchTimeout)
+		}
+		return nil, fmt.Errorf("%s: %w: %w", rawURL, validator.ErrIO, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %w: HTTP %s", rawURL, validator.ErrIO, resp.Status)
+	}
+	data, err := validator.ReadAll(rawURL, resp.Body, r.opts.MaxFileSize)
+	if err != nil && !errors.Is(err, validator.ErrTooLarge) {
+		err = fmt.Errorf("%s: %w: %w", rawURL, validator.ErrIO, err)
+	}
+	return data, err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// readFileList reads the --file-list at name, or from stdin for "-":
+// one path per line, blank lines and lines starting with # left out.
+// A path listed more than once is returned once, where first listed.
+func readFileList(name string, stdin io.Reader) ([]string, error) {
+	var data []byte
+	var err error
+	if name == stdinArg {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || slices.Contains(paths, line) {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	return paths, nil
+}
+
+// expandListed is expand for an entry of --file-list, which is taken
+// literally: it is neither a pattern nor standard input.
+func (w *walker) expandListed(entry string) ([]string, error) {
+	if isURL(entry) {
+		return []string{entry}, nil
+	}
+	return w.expandPath(entry)
+}
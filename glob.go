@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// errNoMatches means a glob pattern matches no files.
+var errNoMatches = errors.New("pattern matches no files")
+
+// isGlob reports whether arg is to be expanded as a glob pattern: it has
+// glob metacharacters and names no existing file, so that a path such
+// as "pods[1].yaml" is still taken literally.
+func isGlob(arg string) bool {
+	if !strings.ContainsAny(arg, "*?[") {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return err != nil
+}
+
+// glob returns the paths matching pattern in lexical order. Each
+// element of pattern has the syntax of path.Match, with "/" separating
+// elements on every platform, and an element that is "**" matches any
+// number of directories. Neither descends into symlinked directories,
+// and wildcards only match hidden names when the element starts with a
+// dot; "**" also skips the directories walking does.
+func glob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(pattern)
+	vol := filepath.VolumeName(pattern)
+	pattern = pattern[len(vol):]
+	root := "."
+	if strings.HasPrefix(pattern, "/") {
+		root, pattern = "/", strings.TrimLeft(pattern, "/")
+	}
+	if vol != "" {
+		root = vol + strings.TrimPrefix(root, ".")
+	}
+	elems := strings.Split(pattern, "/")
+	for _, el := range elems {
+		if _, err := path.Match(el, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+	}
+	matches := globFrom([]string{root}, elems)
+	slices.Sort(matches)
+	return slices.Compact(matches), nil
+}
+
+// globFrom returns the paths below dirs that match elems.
+func globFrom(dirs, elems []string) []string {
+	if len(elems) == 0 {
+		return dirs
+	}
+	el, rest := elems[0], elems[1:]
+	var next []string
+	for _, dir := range dirs {
+		switch {
+		case el == "**":
+			next = append(next, dir)
+			next = append(next, subdirs(dir)...)
+		case el == "" || !strings.ContainsAny(el, "*?[\\"):
+			p := globJoin(dir, el)
+			if _, err := os.Lstat(p); err == nil {
+				next = append(next, p)
+			}
+		default:
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), ".") && !strings.HasPrefix(el, ".") {
+					continue
+				}
+				if ok, _ := path.Match(el, e.Name()); ok {
+					next = append(next, globJoin(dir, e.Name()))
+				}
+			}
+		}
+	}
+	if len(rest) > 0 {
+		// Only directories can hold what the rest matches.
+		next = slices.DeleteFunc(next, func(p string) bool {
+			st, err := os.Stat(p)
+			return err != nil || !st.IsDir()
+		})
+	}
+	return globFrom(next, rest)
+}
+
+// subdirs returns every directory below dir, depth first in lexical
+// order, leaving out symlinks and the directories walking skips.
+func subdirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") || slices.Contains(skippedDirs, e.Name()) {
+			continue
+		}
+		p := globJoin(dir, e.Name())
+		out = append(out, p)
+		out = append(out, subdirs(p)...)
+	}
+	return out
+}
+
+// globJoin joins like filepath.Join, but keeps the paths of a relative
+// pattern free of a leading "./".
+func globJoin(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
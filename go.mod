@@ -0,0 +1,5 @@
+module github.com/abdddev/go-magistr-lesson2-tpl
+
+go 1.24
+
+require gopkg.in/yaml.v3 v3.0.1
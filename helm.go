@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// stdinArg is the argument that stands for standard input, and
+// stdinName the name findings give it unless --stdin-filename names it.
+const (
+	stdinArg  = "-"
+	stdinName = "<stdin>"
+)
+
+// helmSourcePrefix opens the comment helm template writes before each
+// document it renders, naming the template the document came from.
+const helmSourcePrefix = "# Source: "
+
+// helmChunk is the part of a helm template stream rendered from one
+// template, or a document of the stream without a Source comment.
+type helmChunk struct {
+	name string
+	data []byte
+}
+
+// validateStdin validates standard input, returning the exit code it
+// warrants. With --helm, or when the stream opens with a Source
+// comment as helm template output does, the documents of each template
+// are validated as an input of their own named after the template (see
+// splitHelm), so findings point at the template to fix.
+func (r *runner) validateStdin() int {
+	name := cmp.Or(r.stdinName, stdinName)
+	data, err := validator.ReadAll(name, r.stdin, r.opts.MaxFileSize)
+	if err == nil {
+		data, err = validator.Decompress(name, data, r.opts.MaxFileSize)
+	}
+	if err != nil {
+		if !errors.Is(err, validator.ErrTooLarge) && !errors.Is(err, validator.ErrIO) {
+			err = fmt.Errorf("%s: %w: %w", name, validator.ErrIO, err)
+		}
+		return r.validatePath(name, &outcome{err: err})
+	}
+	var chunks []helmChunk
+	if r.helm || isHelmStream(data) {
+		chunks = splitHelm(name, data)
+	}
+	if len(chunks) == 0 {
+		o := &outcome{src: data}
+		o.res, o.err = validator.ValidateBytes(context.Background(), name, data, r.opts)
+		r.snippets.provide(name, data)
+		return r.validatePath(name, o)
+	}
+	code, errs := exitOK, 0
+	for _, c := range chunks {
+		o := &outcome{src: c.data}
+		r.snippets.provide(c.name, c.data)
+		o.res, o.err = validator.ValidateBytes(context.Background(), c.name, c.data, r.opts)
+		cc, n := r.finish(c.name, o)
+		code, errs = worseExit(code, cc), errs+n
+	}
+	r.prog.advance(errs)
+	return code
+}
+
+// isHelmStream reports whether the first comment or content of data,
+// past any document separators, is a Source comment.
+func isHelmStream(data []byte) bool {
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		text := strings.TrimSpace(line)
+		if text == "" || text == "---" {
+			continue
+		}
+		return strings.HasPrefix(text, helmSourcePrefix)
+	}
+	return false
+}
+
+// splitHelm splits a helm template stream at its document separators,
+// skipping documents that hold no more than comments. A document that
+// opens with a Source comment is named after the template it names,
+// and its lines are counted from the one after the comment, so they
+// line up with the template where it renders line for line. Documents
+// rendered from one template in a row are kept together. A document
+// without a Source comment is named "NAME#N", NAME being that of the
+// stream and N the position of the document among those of the stream.
+func splitHelm(stream string, data []byte) []helmChunk {
+	var chunks []helmChunk
+	var cur []byte
+	name, started, pos := "", false, 0
+	flush := func() {
+		if !hasYAMLContent(cur) {
+			return
+		}
+		pos++
+		if name == "" {
+			chunks = append(chunks, helmChunk{fmt.Sprintf("%s#%d", stream, pos), cur})
+			return
+		}
+		if n := len(chunks); n > 0 && chunks[n-1].name == name {
+			chunks[n-1].data = append(append(chunks[n-1].data, "---\n"...), cur...)
+			return
+		}
+		chunks = append(chunks, helmChunk{name, cur})
+	}
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		text := strings.TrimRight(line, "\r\n")
+		switch {
+		case text == "---" || strings.HasPrefix(text, "--- "):
+			flush()
+			cur, name, started = nil, "", false
+			if text != "---" {
+				// Content after the separator belongs to the document.
+				cur = append(cur, line...)
+				started = true
+			}
+		case !started && name == "" && strings.HasPrefix(text, helmSourcePrefix):
+			name = strings.TrimSpace(strings.TrimPrefix(text, helmSourcePrefix))
+			cur = nil
+		default:
+			if t := strings.TrimSpace(text); t != "" && !strings.HasPrefix(t, "#") {
+				started = true
+			}
+			cur = append(cur, line...)
+		}
+	}
+	flush()
+	return chunks
+}
+
+// hasYAMLContent reports whether data holds more than blank lines,
+// comments and document end markers.
+func hasYAMLContent(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		t := string(bytes.TrimSpace(line))
+		if t != "" && t != "..." && !strings.HasPrefix(t, "#") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the ignore file looked for at the root of each
+// walked directory.
+const ignoreFileName = ".podvalidateignore"
+
+// ignoreList holds the patterns of an ignore file, in the syntax of
+// .gitignore: blank lines and lines starting with # are skipped, ! negates
+// a pattern, a trailing / matches only directories, a pattern holding a
+// / is anchored at the walk root, and ** matches any number of
+// directories. The last pattern that matches a path decides it.
+type ignoreList struct {
+	file  string
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	text    string // the pattern as written, for logging
+	line    int
+	elems   []string // "/"-separated elements, each in path.Match syntax
+	negate  bool
+	dirOnly bool
+}
+
+// loadIgnore reads the ignore file at name. It returns nil and no error
+// when optional is set and the file does not exist.
+func loadIgnore(name string, optional bool, log *slog.Logger) (*ignoreList, error) {
+	data, err := os.ReadFile(name)
+	if optional && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseIgnore(name, data, log), nil
+}
+
+// parseIgnore parses the content of the ignore file called file.
+// Malformed patterns are logged and dropped.
+func parseIgnore(file string, data []byte, log *slog.Logger) *ignoreList {
+	l := &ignoreList{file: file}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, ok := parsePattern(line)
+		if !ok {
+			continue
+		}
+		r.line = i + 1
+		if !validElems(r.elems) {
+			log.Warn("skipping malformed ignore pattern", "file", file, "line", r.line, "pattern", r.text)
+			continue
+		}
+		l.rules = append(l.rules, r)
+	}
+	return l
+}
+
+// parsePattern parses one pattern, reporting false when it matches
+// nothing, as "/" alone does.
+func parsePattern(text string) (ignoreRule, bool) {
+	r, line := ignoreRule{text: text}, text
+	switch {
+	case strings.HasPrefix(line, "!"):
+		r.negate, line = true, line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly, line = true, strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return r, false
+	}
+	// A pattern without an inner slash matches at any depth.
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	r.elems = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	return r, true
+}
+
+// parseExcludes parses the --exclude patterns, which take the syntax of
+// ignore file patterns without negation.
+func parseExcludes(patterns []string) (*ignoreList, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	l := &ignoreList{file: "--exclude"}
+	for _, p := range patterns {
+		r, ok := parsePattern(p)
+		switch {
+		case r.negate:
+			return nil, fmt.Errorf("--exclude %q: negated patterns are only supported in ignore files", p)
+		case ok && !validElems(r.elems):
+			return nil, fmt.Errorf("--exclude %q: %w", p, path.ErrBadPattern)
+		case ok:
+			l.rules = append(l.rules, r)
+		}
+	}
+	return l, nil
+}
+
+func validElems(elems []string) bool {
+	for _, el := range elems {
+		if _, err := path.Match(el, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether rel, a "/"-separated path relative to the walk
+// root, is ignored, and the pattern that decided it.
+func (l *ignoreList) match(rel string, isDir bool) (bool, *ignoreRule) {
+	var decided *ignoreRule
+	name := strings.Split(rel, "/")
+	for i := range l.rules {
+		r := &l.rules[i]
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if matchElems(r.elems, name) {
+			decided = r
+		}
+	}
+	return decided != nil && !decided.negate, decided
+}
+
+// matchElems matches the elements of a path against those of a pattern,
+// where a "**" element stands for any number of path elements.
+func matchElems(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pat[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignoreFor returns the ignore patterns for a walk rooted at dir: those
+// of --ignore-file when given, otherwise those of the ignore file at
+// dir, if any.
+func (w *walker) ignoreFor(dir string) *ignoreList {
+	if w.ignoreFile != nil {
+		return w.ignoreFile
+	}
+	name := filepath.Join(dir, ignoreFileName)
+	l, err := loadIgnore(name, true, w.log)
+	if err != nil {
+		w.log.Warn("cannot read ignore file", "file", name, "error", err)
+	}
+	return l
+}
+
+// isIgnored reports whether the walk leaves out p, a directory or a
+// manifest below the walk root, because of the --exclude patterns or,
+// failing those, the ignore patterns; it counts and logs what it leaves
+// out. A negation in the ignore patterns cannot bring back what
+// --exclude leaves out, since the ignore patterns are not consulted.
+func (w *walker) isIgnored(p string, info os.FileInfo) bool {
+	if (w.exclude == nil && w.rules == nil) || (!info.IsDir() && !(info.Mode().IsRegular() && w.isManifest(p))) {
+		return false
+	}
+	rel, err := filepath.Rel(w.root, p)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	var r *ignoreRule
+	var excluded, ignored bool
+	if w.exclude != nil {
+		excluded, r = w.exclude.match(rel, info.IsDir())
+	}
+	if !excluded && w.rules != nil {
+		ignored, r = w.rules.match(rel, info.IsDir())
+	}
+	if !excluded && !ignored {
+		return false
+	}
+	if excluded {
+		w.excluded = append(w.excluded, p)
+		w.log.Debug("excluding", "path", p, "pattern", r.text)
+		return true
+	}
+	w.ignored = append(w.ignored, p)
+	w.log.Debug("ignoring", "path", p, "file", w.rules.file, "line", r.line, "pattern", r.text)
+	return true
+}
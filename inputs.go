@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// manifestExts are the file extensions picked up when walking
+// directories unless --ext says otherwise, also under a further ".gz"
+// for gzipped manifests. Files named explicitly are validated whatever
+// their extension.
+var manifestExts = []string{".yaml", ".yml"}
+
+// skippedDirs are the directories not descended into when walking, on
+// top of hidden ones such as .git.
+var skippedDirs = []string{"vendor"}
+
+// errNoManifests means a directory argument holds no manifests.
+var errNoManifests = errors.New("no YAML files found")
+
+// walker expands command-line paths into the files to validate.
+type walker struct {
+	followSymlinks bool
+	glob           bool        // expand arguments that are glob patterns
+	ignoreFile     *ignoreList // --ignore-file, used instead of each root's ignore file
+	exclude        *ignoreList // --exclude, applied below every root ahead of its ignore patterns
+	exts           []string    // --ext, used instead of manifestExts
+	log            *slog.Logger
+
+	files    []os.FileInfo // real files already queued, for deduplication
+	dirs     []os.FileInfo // real directories already walked
+	matched  int           // manifests met while walking and not left out, duplicates included
+	ignored  []string      // paths the ignore patterns left out
+	excluded []string      // paths the --exclude patterns left out
+
+	root  string      // the directory argument being walked
+	rules *ignoreList // the ignore patterns of root, if any
+}
+
+// expand returns the files named by arg: arg itself when it is not a
+// directory, otherwise the manifests below it in lexical order. The
+// returned paths are spelled through arg, even where a followed
+// symlink leads elsewhere, so findings point where the user looked. It
+// fails with errNoManifests for a directory without any, unless the
+// directory was already walked. Files and directories matched by the
+// --exclude patterns or the ignore patterns for the directory are left
+// out, and a directory they leave nothing of fails the same way;
+// arguments themselves never are left out. An http or https URL,
+// to be fetched, and "-" for standard input are returned as is.
+//
+// When w.glob is set and arg is a pattern (see isGlob), each of its
+// matches is expanded in turn, and a pattern that matches nothing
+// fails with errNoMatches. Directories among the matches may hold no
+// manifests, as "deploy/**" matches every directory below deploy.
+func (w *walker) expand(arg string) ([]string, error) {
+	if isURL(arg) || arg == stdinArg {
+		return []string{arg}, nil
+	}
+	if !w.glob || !isGlob(arg) {
+		return w.expandPath(arg)
+	}
+	matches, err := glob(arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%s: %w", arg, errNoMatches)
+	}
+	var out []string
+	for _, m := range matches {
+		p, _ := w.expandPath(m)
+		out = append(out, p...)
+	}
+	return out, nil
+}
+
+// expandPath is expand for an argument that is not a pattern.
+func (w *walker) expandPath(arg string) ([]string, error) {
+	st, err := os.Stat(arg)
+	if err != nil || !st.IsDir() {
+		// Let validation report the read error for this path.
+		if err == nil && w.seenFile(st) {
+			return nil, nil
+		}
+		return []string{arg}, nil
+	}
+	if w.seenDir(st) {
+		return nil, nil
+	}
+	var out []string
+	before, leftOut := w.matched, len(w.ignored)+len(w.excluded)
+	w.root, w.rules = arg, w.ignoreFor(arg)
+	w.walkDir(arg, st, nil, &out)
+	if w.matched == before {
+		if n := len(w.ignored) + len(w.excluded) - leftOut; n > 0 {
+			return nil, fmt.Errorf("%s: %w (the ignore and --exclude patterns leave out %s)", arg, errNoManifests, plural(n, "path"))
+		}
+		return nil, fmt.Errorf("%s: %w (looking for *%s)", arg, errNoManifests, strings.Join(w.extensions(), ", *"))
+	}
+	return out, nil
+}
+
+// walkDir appends the manifests below dir to out. ancestors holds the
+// real directories on the current path, to tell cycles from mere
+// duplicates. With w.followSymlinks, links are walked as what they
+// point to, a dangling link named like a manifest being queued so that
+// it fails as unreadable; without it, links are skipped.
+func (w *walker) walkDir(dir string, st os.FileInfo, ancestors []os.FileInfo, out *[]string) {
+	if w.seenDir(st) {
+		return
+	}
+	w.dirs = append(w.dirs, st)
+	ancestors = append(ancestors, st)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// Surface it like an unreadable file.
+		*out = append(*out, dir)
+		return
+	}
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if !w.followSymlinks {
+				w.log.Debug("skipping symlink", "path", p)
+				continue
+			}
+			target, err := os.Stat(p)
+			if err != nil && w.isManifest(p) {
+				// Let validation report it like an unreadable file.
+				w.matched++
+				*out = append(*out, p)
+				continue
+			}
+			if err != nil {
+				w.log.Warn("skipping dangling symlink", "path", p, "error", err)
+				continue
+			}
+			info = target
+		}
+		switch {
+		case info.IsDir() && (strings.HasPrefix(e.Name(), ".") || slices.Contains(skippedDirs, e.Name())):
+			w.log.Debug("skipping directory", "path", p)
+		case w.isIgnored(p, info):
+		case info.IsDir():
+			if containsFile(ancestors, info) {
+				w.log.Warn("skipping symlink cycle", "path", p)
+				continue
+			}
+			w.walkDir(p, info, ancestors, out)
+		case info.Mode().IsRegular() && w.isManifest(p):
+			w.matched++
+			if w.seenFile(info) {
+				w.log.Debug("skipping duplicate of an already queued file", "path", p)
+				continue
+			}
+			*out = append(*out, p)
+		}
+	}
+}
+
+// seenFile records st and reports whether it was already queued.
+func (w *walker) seenFile(st os.FileInfo) bool {
+	if containsFile(w.files, st) {
+		return true
+	}
+	w.files = append(w.files, st)
+	return false
+}
+
+func (w *walker) seenDir(st os.FileInfo) bool { return containsFile(w.dirs, st) }
+
+func containsFile(list []os.FileInfo, st os.FileInfo) bool {
+	for _, o := range list {
+		if os.SameFile(o, st) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasManifestExt(p string) bool { return hasExt(p, manifestExts) }
+
+// extensions returns the file extensions the walk picks up.
+func (w *walker) extensions() []string {
+	if len(w.exts) > 0 {
+		return w.exts
+	}
+	return manifestExts
+}
+
+// isManifest reports whether the walk picks up the file at p.
+func (w *walker) isManifest(p string) bool { return hasExt(p, w.extensions()) }
+
+// hasExt reports whether the name p ends in one of exts, which are
+// lower case, optionally followed by ".gz".
+func hasExt(p string, exts []string) bool {
+	p = strings.TrimSuffix(strings.ToLower(filepath.Base(p)), ".gz")
+	for _, e := range exts {
+		if strings.HasSuffix(p, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExts parses the values of --ext, each a comma-separated list of
+// extensions such as ".json" or "tpl.yaml".
+func parseExts(values []string) ([]string, error) {
+	var exts []string
+	for _, v := range values {
+		for _, e := range strings.Split(v, ",") {
+			e = strings.ToLower(strings.TrimSpace(e))
+			if e == "" || e == "." || strings.ContainsAny(e, `/\`) {
+				return nil, fmt.Errorf("invalid --ext %q: want an extension such as .json", v)
+			}
+			if !strings.HasPrefix(e, ".") {
+				e = "." + e
+			}
+			if !slices.Contains(exts, e) {
+				exts = append(exts, e)
+			}
+		}
+	}
+	return exts, nil
+}
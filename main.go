@@ -1,3 +1,800 @@
 package main
 
-func main() {}
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// Exit codes reported by the program. A run that meets several of
+// these failures exits with the one worseExit ranks highest.
+const (
+	exitOK      = 0
+	exitInvalid = 1 // findings that fail the run
+	exitUsage   = 2 // bad flags, arguments or configuration
+	exitIO      = 3 // an input could not be read
+	exitParse   = 4 // an input is not a YAML document
+	exitNoFiles = 5 // a directory holds no manifests that are not left out, or a pattern matches nothing
+)
+
+// exitCodeHelp documents the exit codes in the usage message.
+const exitCodeHelp = `exit status:
+  0  every input is valid
+  1  an input has findings that fail the run (see --fail-on; never for --fail-on=never)
+  2  usage error: bad flags, arguments or configuration
+  3  an input could not be read or fetched, or the --output report could not be written
+  4  an input is not a YAML document
+  5  a directory holds no YAML files, or only ignored or excluded ones, or a pattern matches no files
+when several apply, 2 wins over 3, then 5, 4 and 1: an unreadable input
+outranks findings, yet the other inputs are still checked (see --strict-io)
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// sizeFlag is a flag.Value accepting byte counts with unit suffixes.
+type sizeFlag int64
+
+func (f *sizeFlag) String() string { return validator.FormatSize(int64(*f)) }
+
+func (f *sizeFlag) Set(s string) error {
+	n, err := validator.ParseSize(s)
+	if err != nil {
+		return err
+	}
+	*f = sizeFlag(n)
+	return nil
+}
+
+// listFlag is a flag.Value collecting the values of a repeated flag.
+type listFlag []string
+
+func (f *listFlag) String() string { return strings.Join(*f, ", ") }
+
+func (f *listFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	name := filepath.Base(os.Args[0])
+	if len(args) > 0 {
+		switch args[0] {
+		case "init":
+			return runInit(name, args[1:], stdout, stderr)
+		case "fmt":
+			return runFmt(name, args[1:], stdout, stderr)
+		case "tui":
+			return runTUI(name, args[1:], stdout, stderr)
+		case "test":
+			return runGolden(name, args[1:], stdout, stderr)
+		case "merge":
+			return runMerge(name, args[1:], stdout, stderr)
+		case "path":
+			return runPath(name, args[1:], stdout, stderr)
+		case "output-schema":
+			return runOutputSchema(name, args[1:], stdout, stderr)
+		case "rules":
+			return runRules(name, args[1:], stdout, stderr)
+		case "explain":
+			return runExplain(name, args[1:], stdout, stderr)
+		}
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	optFlags := addOptionFlags(fs)
+	logLevel := fs.String("log-level", "warn", "operational log `level`: debug, info, warn or error")
+	logFormat := fs.String("log-format", "text", "operational log `format`: text or json")
+	var verbose bool
+	fs.BoolVar(&verbose, "verbose", false, "log what is being done, and each check on each field, to stderr (same as --log-level=debug)")
+	fs.BoolVar(&verbose, "v", false, "same as --verbose")
+	setDefaults := fs.Bool("set-defaults", false, "print the manifest with well-known defaults filled in to stdout")
+	maxArchiveSize := sizeFlag(defaultMaxArchiveSize)
+	fs.Var(&maxArchiveSize, "max-archive-size", "refuse to read more than `SIZE` from one tar archive after decompression; 0 disables the limit")
+	crossRefs := fs.Bool("cross-refs", false, "warn about Service selectors and Ingress backends that match nothing in the input set")
+	followSymlinks := fs.Bool("follow-symlinks", true, "follow symbolic links when walking directories; false skips them")
+	helm := fs.Bool("helm", false, "read standard input as helm template output, naming findings after the template in each document's '# Source:' comment (the default when the input starts with one)")
+	ignoreFile := fs.String("ignore-file", "", "leave out of directory walks what the gitignore-style patterns in `FILE` match, instead of using each directory's "+ignoreFileName)
+	var excludes listFlag
+	fs.Var(&excludes, "exclude", "leave out of directory walks what the gitignore-style `PATTERN` matches below each walked directory, whatever ignore files negate; files named as arguments are never left out (repeatable)")
+	var extFlags listFlag
+	fs.Var(&extFlags, "ext", "pick up files ending in `EXT` when walking directories instead of .yaml and .yml, e.g. .tpl.yaml or .json (repeatable or comma-separated)")
+	fileList := fs.String("file-list", "", "also validate the paths listed one per line in `FILE`, or on standard input for -, after the arguments; blank lines and # comments are skipped")
+	stdinFilename := fs.String("stdin-filename", "", "name findings about standard input (the - argument) `PATH` instead of "+stdinName)
+	noGlob := fs.Bool("no-glob", false, "take arguments literally instead of expanding glob patterns such as 'deploy/**/*.yaml'")
+	strictIO := fs.Bool("strict-io", false, "stop at the first input that cannot be read")
+	fetchTimeout := fs.Duration("fetch-timeout", defaultFetchTimeout, "give up fetching an http(s) URL argument after `DURATION` (0 waits indefinitely)")
+	jobs := fs.Int("jobs", runtime.GOMAXPROCS(0), "validate up to `N` inputs at a time; findings are reported in input order whatever N is")
+	progressInterval := fs.Duration("progress-interval", 10*time.Second, "when stderr is not a terminal, report batch progress every `DURATION` (0 disables)")
+	format := fs.String("format", "text", "findings `format`: text (on stderr), or json, ndjson, sarif, checkstyle, tap, markdown or codeclimate (on stdout)")
+	output := fs.String("output", "", "write the --format report to `PATH`, replacing it once the run is over, or to stdout for -; the summary stays on stderr")
+	fix := fs.Bool("fix", false, "rewrite input files in place to fix what can be fixed (pinning locked image digests), then validate them")
+	diff := fs.Bool("diff", false, "with --fix, print the fixes as a unified diff on stdout instead of rewriting files")
+	maxErrors := fs.Int("max-errors", 0, "stop reporting after `N` findings; 0 reports all")
+	failOn := fs.String("fail-on", "error", "lowest finding `severity` that fails the run: error or warning, or never to exit 0 whatever the findings (inputs that cannot be read or parsed still fail)")
+	colorMode := fs.String("color", "auto", "color text findings: `auto` (on a terminal, unless NO_COLOR is set), always or never")
+	noColor := fs.Bool("no-color", false, "same as --color=never")
+	var quiet bool
+	fs.BoolVar(&quiet, "quiet", false, "print nothing but unreadable inputs and usage errors; the exit status tells the outcome (overrides --format)")
+	fs.BoolVar(&quiet, "q", false, "same as --quiet")
+	noSnippets := fs.Bool("no-snippets", false, "do not show the source line and a caret under each text finding")
+	msgTemplate := fs.String("msg-template", "", "render text findings with Go text/`TEMPLATE` over .File, .Line, .Col, .Pos, .Resource, .Code, .Rule, .Severity, .Field, .Path, .Message and .Count, or name a preset: gcc, msvc or default (disables color)")
+	pathModeFlag := fs.String("path-mode", "as-given", "write input names in findings by `MODE`: as-given, absolute, or relative to --base-dir")
+	langFlag := fs.String("lang", "", "write finding messages in `LANG`: en or ru (default from LC_ALL, LC_MESSAGES or LANG, else en)")
+	baseDir := fs.String("base-dir", "", "with --path-mode=relative, the `DIR` names are relative to (default the working directory)")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s [flags] <path-to-yaml | archive.tgz | URL | ->...\n", name)
+		fmt.Fprintf(stderr, "       %s init <kind> --name NAME --image IMAGE [--out FILE [--force]]\n", name)
+		fmt.Fprintf(stderr, "       %s fmt [--check | --write] FILE...\n", name)
+		fmt.Fprintf(stderr, "       %s tui [--follow-symlinks=false] PATH...\n", name)
+		fmt.Fprintf(stderr, "       %s test [--config FILE] [--line-tolerance N] [--update] DIR\n", name)
+		fmt.Fprintf(stderr, "       %s path [--list] FILE EXPR...\n", name)
+		fmt.Fprintf(stderr, "       %s merge [--format FORMAT] REPORT.json...\n", name)
+		fmt.Fprintf(stderr, "       %s output-schema\n", name)
+		fmt.Fprintf(stderr, "       %s rules [--config FILE]\n", name)
+		fmt.Fprintf(stderr, "       %s explain [--config FILE] CODE|RULE\n", name)
+		fs.PrintDefaults()
+		fmt.Fprint(stderr, "\n"+exitCodeHelp)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 && *fileList == "" {
+		fs.Usage()
+		return exitUsage
+	}
+	if *fileList == stdinArg && slices.Contains(fs.Args(), stdinArg) {
+		fmt.Fprintln(stderr, "--file-list - cannot be combined with -, as both read standard input")
+		return exitUsage
+	}
+	if *stdinFilename != "" && !slices.Contains(fs.Args(), stdinArg) {
+		fmt.Fprintln(stderr, "--stdin-filename needs - among the inputs")
+		return exitUsage
+	}
+
+	switch *format {
+	case "text", "json", "ndjson", "sarif", "checkstyle", "tap", "markdown", "codeclimate":
+	default:
+		fmt.Fprintf(stderr, "unknown format %q (want text, json, ndjson, sarif, checkstyle, tap, markdown or codeclimate)\n", *format)
+		return exitUsage
+	}
+	// The report goes to stdout for the structured formats and to stderr
+	// for text, unless --output sends it elsewhere. A file is written
+	// from reportFile once the run is over, so it is never left half
+	// written.
+	var reportFile bytes.Buffer
+	toFile := *output != "" && *output != "-"
+	reportOut, textOut, stdoutBy := stdout, stderr, ""
+	switch {
+	case toFile:
+		reportOut, textOut = &reportFile, &reportFile
+	case *output == "-":
+		textOut, stdoutBy = stdout, "--output -"
+	case *format != "text":
+		stdoutBy = "--format=" + *format
+	}
+	if *output != "" && quiet {
+		fmt.Fprintln(stderr, "--output cannot be combined with --quiet")
+		return exitUsage
+	}
+	if stdoutBy != "" && *setDefaults {
+		fmt.Fprintf(stderr, "--set-defaults cannot be combined with %s, as both write to stdout\n", stdoutBy)
+		return exitUsage
+	}
+
+	if *diff && !*fix {
+		fmt.Fprintln(stderr, "--diff needs --fix")
+		return exitUsage
+	}
+	if *diff && (stdoutBy != "" || *setDefaults) {
+		fmt.Fprintln(stderr, "--diff cannot be combined with --set-defaults or a report on stdout (a --format other than text, or --output -), as both write to stdout")
+		return exitUsage
+	}
+	var msgTmpl *template.Template
+	if *msgTemplate != "" {
+		if *format != "text" {
+			fmt.Fprintf(stderr, "--msg-template cannot be combined with --format=%s\n", *format)
+			return exitUsage
+		}
+		t, err := parseMsgTemplate(*msgTemplate)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		msgTmpl = t
+	}
+	names, err := newPathMode(*pathModeFlag, *baseDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	lang, err := findingLanguage(*langFlag)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *maxErrors < 0 {
+		fmt.Fprintf(stderr, "invalid --max-errors %d: want 0 or more\n", *maxErrors)
+		return exitUsage
+	}
+	var failOnWarning bool
+	switch *failOn {
+	case "error", "never":
+	case "warning":
+		failOnWarning = true
+	default:
+		fmt.Fprintf(stderr, "invalid --fail-on %q: want error, warning or never\n", *failOn)
+		return exitUsage
+	}
+	if *jobs < 1 {
+		fmt.Fprintf(stderr, "invalid --jobs %d: want at least 1\n", *jobs)
+		return exitUsage
+	}
+	if *noColor {
+		*colorMode = "never"
+	}
+	color, err := useColor(*colorMode, textOut)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if verbose {
+		*logLevel = "debug"
+	} else if quiet && !flagSet(fs, "log-level") {
+		*logLevel = "error"
+	}
+	logger, err := newLogger(stderr, *logLevel, *logFormat)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	opts, messages, ok := optFlags.options(logger, stderr)
+	if !ok {
+		return exitUsage
+	}
+	w := &walker{followSymlinks: *followSymlinks, glob: !*noGlob, log: logger}
+	if *ignoreFile != "" {
+		l, err := loadIgnore(*ignoreFile, false, logger)
+		if err != nil {
+			fmt.Fprintln(stderr, "cannot read ignore file:", err)
+			return exitUsage
+		}
+		w.ignoreFile = l
+	}
+	exclude, err := parseExcludes(excludes)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	w.exclude = exclude
+	if w.exts, err = parseExts(extFlags); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	var paths []string
+	code := exitOK
+	for _, arg := range fs.Args() {
+		p, err := w.expand(arg)
+		switch {
+		case errors.Is(err, path.ErrBadPattern):
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		case err != nil:
+			fmt.Fprintln(stderr, err)
+			code = worseExit(code, exitNoFiles)
+		}
+		paths = append(paths, p...)
+	}
+	if *fileList != "" {
+		listed, err := readFileList(*fileList, os.Stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, "cannot read file list:", err)
+			return exitUsage
+		}
+		for _, entry := range listed {
+			p, err := w.expandListed(entry)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				code = worseExit(code, exitNoFiles)
+			}
+			paths = append(paths, p...)
+		}
+	}
+	prog := newProgress(stderr, len(paths), *progressInterval, quiet)
+	var rep validator.Reporter
+	var snippets *snippetReporter
+	if quiet {
+		rep = &quietReporter{w: stderr}
+	} else {
+		rep = newReporter(*format, name, color, msgTmpl, reportOut, textOut)
+	}
+	if names.mode != "as-given" {
+		rep = &pathReporter{Reporter: rep, paths: names}
+	}
+	if *format == "text" && !quiet && !*noSnippets {
+		snippets = &snippetReporter{Reporter: rep, w: textOut, color: color}
+		rep = snippets
+	}
+	r := &runner{opts: opts, maxArchive: int64(maxArchiveSize), setDefaults: *setDefaults, fix: *fix, diff: *diff, rep: rep, failOnWarning: failOnWarning, maxFindings: *maxErrors, messages: messages, lang: lang, fetchTimeout: *fetchTimeout, helm: *helm, stdinName: *stdinFilename, snippets: snippets, names: names, log: logger, prog: prog, stdin: os.Stdin, stdout: stdout, stderr: stderr}
+	if *format == "text" && !quiet {
+		r.headers = textOut
+		if !*setDefaults && !*diff {
+			r.confirm = stdout
+		}
+	}
+	r.stats.Ignored, r.stats.Excluded = len(w.ignored), len(w.excluded)
+	if *crossRefs {
+		r.refs = &validator.RefSet{}
+	}
+	p := r.startPool(paths, *jobs)
+	for i, path := range paths {
+		var c int
+		switch {
+		case path == stdinArg:
+			c = r.validateStdin()
+		case isArchive(path) && !isURL(path):
+			c = r.validateArchive(path)
+		default:
+			c = r.validatePath(path, p.wait(i))
+		}
+		code = worseExit(code, c)
+		if c == exitIO && *strictIO {
+			break
+		}
+	}
+	p.stop()
+	prog.clear()
+	if r.refs != nil {
+		for _, e := range r.refs.Check() {
+			r.reportFinding(e)
+			r.stats.Warnings++
+			if r.failOnWarning {
+				code = worseExit(code, exitInvalid)
+			}
+		}
+	}
+	if r.fix && !quiet {
+		r.fixSummary()
+	}
+	if r.stats.Skipped > 0 {
+		logger.Info("documents skipped by kind filters", "count", r.stats.Skipped)
+	}
+	// Say so when --fail-on decided the exit status.
+	var threshold string
+	switch {
+	case *failOn == "never" && code == exitInvalid:
+		code, threshold = exitOK, " (not failing: --fail-on=never)"
+	case failOnWarning && code == exitInvalid && r.stats.Errors == 0:
+		threshold = " (failing on warnings: --fail-on=warning)"
+	}
+	if err := r.rep.Summary(r.stats); err != nil {
+		fmt.Fprintln(stderr, err)
+		return worseExit(code, exitIO)
+	}
+	if toFile {
+		if err := writeFileAtomic(*output, reportFile.Bytes()); err != nil {
+			fmt.Fprintln(stderr, "cannot write report:", err)
+			code = worseExit(code, exitIO)
+		}
+	}
+	if !quiet {
+		fmt.Fprintln(stderr, r.stats.String()+threshold)
+	}
+	return code
+}
+
+// runner holds what validating each input of a run needs.
+type runner struct {
+	opts          validator.Options
+	maxArchive    int64
+	setDefaults   bool
+	fix           bool // pin locked digests in place before validating
+	diff          bool // with fix, print a diff instead of writing
+	rep           validator.Reporter
+	failOnWarning bool
+	maxFindings   int // 0 for no limit
+	messages      messageCatalog
+	lang          string        // language of finding messages
+	fetchTimeout  time.Duration // bound on fetching one URL argument
+	helm          bool          // split standard input by helm Source comments
+	stdinName     string        // --stdin-filename
+	snippets      *snippetReporter
+	sources       map[string][]byte // sources of inputs not read from a file of their name, kept for tui; nil otherwise
+	stdin         io.Reader
+	names         pathMode
+	headers       io.Writer         // where document headers go, or nil
+	confirm       io.Writer         // where OK lines go, or nil
+	refs          *validator.RefSet // nil unless --cross-refs
+	log           *slog.Logger
+	prog          *progress
+	stdout        io.Writer
+	stderr        io.Writer
+
+	stats     validator.Stats
+	reported  int
+	fixes     map[string]int // fixes applied per rule
+	fixed     int            // files fixed
+	defaulted int            // inputs printed by --set-defaults
+}
+
+// newReporter returns the Reporter for --format: text goes to stderr,
+// rendered by tmpl or, when it is nil, by the default template or in
+// color if color is set, and the structured formats to stdout.
+func newReporter(format, tool string, color bool, tmpl *template.Template, stdout, stderr io.Writer) validator.Reporter {
+	switch format {
+	case "json":
+		return validator.NewJSONReporter(stdout)
+	case "ndjson":
+		return validator.NewNDJSONReporter(stdout)
+	case "sarif":
+		return validator.NewSARIFReporter(stdout, tool)
+	case "checkstyle":
+		return validator.NewCheckstyleReporter(stdout)
+	case "tap":
+		return validator.NewTAPReporter(stdout)
+	case "markdown":
+		return validator.NewMarkdownReporter(stdout)
+	case "codeclimate":
+		return validator.NewCodeClimateReporter(stdout)
+	}
+	if tmpl == nil {
+		if color {
+			return &colorTextReporter{w: stderr}
+		}
+		tmpl = defaultMsgTemplate
+	}
+	return &templateReporter{w: stderr, tmpl: tmpl}
+}
+
+// quietReporter is the Reporter of --quiet: it drops every finding but
+// those about inputs that could not be read, which it prints as text.
+type quietReporter struct {
+	w   io.Writer
+	err error
+}
+
+func (q *quietReporter) Report(e *validator.ValidationError) {
+	if e.Category != validator.CategoryIO {
+		return
+	}
+	if _, err := fmt.Fprintln(q.w, validator.FormatText(e)); err != nil && q.err == nil {
+		q.err = err
+	}
+}
+
+func (q *quietReporter) Summary(validator.Stats) error { return q.err }
+
+// flagSet reports whether the flag called name was given on the
+// command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
+
+// validatePath validates one input, a file or a URL, and prints its
+// findings, returning the exit code it warrants on its own. o is the
+// outcome of validating it ahead of time, or nil to validate it now.
+func (r *runner) validatePath(path string, o *outcome) int {
+	if o == nil {
+		if r.fix && !isURL(path) {
+			if code := r.fixFile(path); code != exitOK {
+				return code
+			}
+		}
+		o = r.check(context.Background(), path)
+	}
+	code, errs := r.finish(path, o)
+	r.prog.advance(errs)
+	return code
+}
+
+// finish reports the outcome o of validating the input called name and
+// hands its source to --cross-refs and --set-defaults, returning the
+// exit code it warrants and its error count. The source is read again
+// from the file name unless o holds it.
+func (r *runner) finish(name string, o *outcome) (int, int) {
+	code, errs := r.report(name, o.res, o.err)
+	r.keep(name, o.src)
+	if o.err != nil || (!r.setDefaults && r.refs == nil) {
+		return code, errs
+	}
+	src := o.src
+	if src == nil {
+		var err error
+		if src, err = os.ReadFile(name); err == nil {
+			src, err = validator.Decompress(name, src, r.opts.MaxFileSize)
+		}
+		if err != nil {
+			fmt.Fprintln(r.stderr, err)
+			return exitIO, errs
+		}
+	}
+	if r.refs != nil {
+		r.refs.Add(name, src)
+	}
+	if r.setDefaults {
+		// Several inputs print as one stream of documents.
+		if r.defaulted > 0 {
+			if _, err := io.WriteString(r.stdout, "---\n"); err != nil {
+				return exitIO, errs
+			}
+		}
+		r.defaulted++
+		code = worseExit(code, printDefaulted(r.stdout, r.stderr, name, src))
+	}
+	return code, errs
+}
+
+// keep records src as the source of the input called name when the
+// runner keeps sources.
+func (r *runner) keep(name string, src []byte) {
+	if r.sources != nil && src != nil {
+		r.sources[name] = src
+	}
+}
+
+// fixFile applies validator.ApplyFixes to every document of path and
+// writes the file back when anything changed, or with --diff prints
+// the change instead. The diff is taken against the encoder output, so
+// applying it yields exactly what --fix would have written.
+func (r *runner) fixFile(path string) int {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		// Validating reports the read error.
+		return exitOK
+	}
+	if validator.IsGzip(path, src) {
+		r.log.Warn("not fixing a compressed file", "file", path)
+		return exitOK
+	}
+	var fixes []validator.Fix
+	out, err := rewriteYAML(src, func(doc *yaml.Node) {
+		fixes = append(fixes, validator.ApplyFixes(doc, r.opts)...)
+	})
+	if err != nil || len(fixes) == 0 {
+		return exitOK
+	}
+	if r.diff {
+		r.prog.clear()
+		if _, err := io.WriteString(r.stdout, unifiedDiff(path, src, out)); err != nil {
+			return exitIO
+		}
+	} else {
+		st, err := os.Stat(path)
+		if err == nil {
+			err = os.WriteFile(path, out, st.Mode().Perm())
+		}
+		if err != nil {
+			fmt.Fprintln(r.stderr, err)
+			return exitIO
+		}
+	}
+	if r.fixes == nil {
+		r.fixes = make(map[string]int)
+	}
+	r.fixed++
+	for _, f := range fixes {
+		r.fixes[f.Rule]++
+		r.log.Info("fixed", "file", path, "line", f.Line, "field", f.Field, "rule", f.Rule)
+	}
+	return exitOK
+}
+
+// fixSummary prints how many fixes --fix applied, per rule.
+func (r *runner) fixSummary() {
+	if r.fixed == 0 {
+		fmt.Fprintln(r.stderr, "nothing to fix")
+		return
+	}
+	verb := "fixed"
+	if r.diff {
+		verb = "would fix"
+	}
+	rules := slices.Sorted(maps.Keys(r.fixes))
+	var total int
+	for i, rule := range rules {
+		total += r.fixes[rule]
+		rules[i] += ": " + strconv.Itoa(r.fixes[rule])
+	}
+	fmt.Fprintf(r.stderr, "%s %s in %s (%s)\n", verb, plural(total, "finding"), plural(r.fixed, "file"), strings.Join(rules, ", "))
+}
+
+// plural renders n and noun, adding an s unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// report prints what validating one input produced and returns the
+// exit code it warrants along with its error count.
+func (r *runner) report(name string, res *validator.Result, err error) (int, int) {
+	if err != nil {
+		r.prog.clear()
+		if errors.Is(err, validator.ErrBadSelector) {
+			fmt.Fprintln(r.stderr, err)
+			return exitUsage, 1
+		}
+		// Unreadable inputs become findings so the rest of the run can
+		// go on; the exit code still ranks them above invalid files.
+		if isIOError(err) {
+			r.emit(validator.IOResult(name, err))
+			return exitIO, 1
+		}
+		r.emit(validator.ParseResult(name, err))
+		return exitParse, 1
+	}
+	if len(res.Findings) > 0 {
+		r.prog.clear()
+	}
+	r.emit(res)
+	if !res.Valid() {
+		return exitInvalid, len(res.Errors())
+	}
+	if r.failOnWarning && len(res.Warnings()) > 0 {
+		return exitInvalid, 0
+	}
+	return exitOK, 0
+}
+
+// emit hands every finding of res to the reporter and counts res in the
+// run's stats. In text output, the findings of an input of several
+// documents are grouped under a header naming each document, and each
+// document without errors is confirmed with an OK line on stdout.
+func (r *runner) emit(res *validator.Result) {
+	if fr, ok := r.rep.(validator.FileReporter); ok && res.File != "" && !res.Skipped {
+		fr.StartFile(res.File)
+	}
+	doc := 0
+	for _, e := range res.Findings {
+		if r.headers != nil && len(res.Documents) > 1 && e.Document > 0 && e.Document != doc && !r.capped() {
+			doc = e.Document
+			r.documentHeader(res, doc)
+		}
+		r.reportFinding(e)
+	}
+	passing := res.ValidDocuments()
+	if r.failOnWarning {
+		passing = withoutWarnings(res, passing)
+	}
+	rr, _ := r.rep.(validator.ResourceReporter)
+	for _, d := range passing {
+		if rr != nil {
+			rr.Resource(validator.Resource{File: res.File, Document: d})
+		}
+		if r.confirm != nil {
+			fmt.Fprintln(r.confirm, confirmation(r.names.render(res.File), d))
+		}
+	}
+	r.stats.Count(res)
+	// Under --fail-on=warning a warning fails its document as an error
+	// would, so the summary counts it with the invalid ones.
+	if w := len(res.ValidDocuments()) - len(passing); w > 0 {
+		r.stats.InvalidDocuments += w
+		if res.Valid() {
+			r.stats.Invalid++
+		}
+	}
+}
+
+// withoutWarnings returns the documents of docs that res has no warning
+// for.
+func withoutWarnings(res *validator.Result, docs []validator.Document) []validator.Document {
+	warned := make(map[int]bool)
+	for _, e := range res.Warnings() {
+		warned[e.Document] = true
+	}
+	var out []validator.Document
+	for _, d := range docs {
+		if !warned[d.Index] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// confirmation renders the OK line of document d of the input called
+// name, as in "OK pod.yaml: Pod/web (2 containers, 3 ports)".
+func confirmation(name string, d validator.Document) string {
+	what := d.Label()
+	if d.Containers > 0 {
+		what += fmt.Sprintf(" (%s, %s)", plural(d.Containers, "container"), plural(d.Ports, "port"))
+	}
+	return fmt.Sprintf("OK %s: %s", name, what)
+}
+
+// documentHeader introduces the findings of document i of res, as in
+// "pods.yaml (document 3 of 5, kind=Pod, name=web):".
+func (r *runner) documentHeader(res *validator.Result, i int) {
+	h := fmt.Sprintf("%s (document %d of %d", r.names.render(res.File), i, len(res.Documents))
+	for _, d := range res.Documents {
+		if d.Index != i {
+			continue
+		}
+		if d.Kind != "" {
+			h += ", kind=" + d.Kind
+		}
+		if d.Name != "" {
+			h += ", name=" + d.Name
+		}
+	}
+	fmt.Fprintln(r.headers, h+"):")
+}
+
+// capped reports whether --max-errors findings have been reported.
+func (r *runner) capped() bool { return r.maxFindings > 0 && r.reported >= r.maxFindings }
+
+// reportFinding hands e to the reporter in --lang with the message
+// catalog applied, or counts it as omitted once --max-errors findings
+// have been reported.
+func (r *runner) reportFinding(e *validator.ValidationError) {
+	if r.capped() {
+		r.stats.Omitted++
+		return
+	}
+	r.reported++
+	r.rep.Report(r.messages.render(validator.Localize(e, r.lang)))
+}
+
+// isIOError reports whether err means the input could not be read.
+func isIOError(err error) bool {
+	return errors.Is(err, validator.ErrIO) || errors.Is(err, validator.ErrTooLarge) || errors.Is(err, validator.ErrEncoding)
+}
+
+// worseExit returns whichever of two exit codes takes precedence: usage
+// errors, then I/O errors, then directories without manifests, then
+// parse errors, then validation failures.
+func worseExit(a, b int) int {
+	rank := map[int]int{exitOK: 0, exitInvalid: 1, exitParse: 2, exitNoFiles: 3, exitIO: 4, exitUsage: 5}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// printResult writes every finding of res in the human format.
+func printResult(w io.Writer, res *validator.Result) {
+	rep := validator.NewTextReporter(w)
+	for _, e := range res.Findings {
+		rep.Report(e)
+	}
+}
+
+// printDefaulted writes src, read from the input called name, to w with
+// defaults materialized.
+func printDefaulted(w, stderr io.Writer, name string, src []byte) int {
+	out, err := rewriteYAML(src, func(doc *yaml.Node) { validator.SetDefaults(doc) })
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return exitParse
+	}
+	if _, err := w.Write(out); err != nil {
+		return exitIO
+	}
+	return exitOK
+}
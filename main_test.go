@@ -0,0 +1,565 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx:1.25
+`
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+
+// writeFiles writes files, by name, into a new temporary directory and
+// returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runCLI runs the command line args and returns its exit code and
+// output.
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestRunJobsRequire validates Pods and Deployments under a --config
+// require policy on 8 workers; run with -race.
+func TestRunJobsRequire(t *testing.T) {
+	files := map[string]string{"policy/config.yaml": "require:\n- metadata.labels.team\n"}
+	for i := range 32 {
+		src := testPod
+		if i%2 == 1 {
+			src = testDeployment
+		}
+		files[fmt.Sprintf("in/%02d.yaml", i)] = src
+	}
+	dir := writeFiles(t, files)
+	code, _, errOut := runCLI(t, "--jobs", "8", "--config", filepath.Join(dir, "policy/config.yaml"), filepath.Join(dir, "in"))
+	if code != exitInvalid {
+		t.Fatalf("exit %d, want %d; stderr:\n%s", code, exitInvalid, errOut)
+	}
+	if n := strings.Count(errOut, "] metadata.labels.team is required"); n != 16 {
+		t.Errorf("%d Pod findings, want 16:\n%s", n, errOut)
+	}
+	if n := strings.Count(errOut, "] spec.template.metadata.labels.team is required"); n != 16 {
+		t.Errorf("%d Deployment template findings, want 16:\n%s", n, errOut)
+	}
+}
+
+// mixedInputs returns a directory of several hundred valid, invalid,
+// unparsable and multi-document manifests, in nested directories, and
+// a link to a file that does not exist.
+func mixedInputs(t *testing.T) string {
+	t.Helper()
+	files := map[string]string{}
+	for i := range 300 {
+		var src string
+		switch i % 5 {
+		case 0:
+			src = testPod
+		case 1:
+			src = strings.Replace(testPod, "nginx:1.25", "nginx", 1)
+		case 2:
+			src = strings.Replace(testDeployment, "app: web\n    spec", "app: api\n    spec", 1)
+		case 3:
+			src = "apiVersion: v1\nkind: Pod\nmetadata: [\n"
+		case 4:
+			src = testPod + "---\n" + strings.Replace(testPod, "image: nginx:1.25", "ports:\n    - containerPort: 0", 1)
+		}
+		files[fmt.Sprintf("d%d/f%03d.yaml", i%3, i)] = src
+	}
+	dir := writeFiles(t, files)
+	if err := os.Symlink(filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "d1", "dangling.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// TestRunJobsDeterministic checks that the output does not depend on
+// the number of workers, for every report format.
+func TestRunJobsDeterministic(t *testing.T) {
+	dir := mixedInputs(t)
+	for _, format := range []string{"text", "json", "ndjson", "sarif", "checkstyle", "tap", "markdown", "codeclimate"} {
+		t.Run(format, func(t *testing.T) {
+			code1, out1, err1 := runCLI(t, "--jobs", "1", "--format", format, dir)
+			for range 5 {
+				code8, out8, err8 := runCLI(t, "--jobs", "8", "--format", format, dir)
+				if code8 != code1 || out8 != out1 || err8 != err1 {
+					t.Fatalf("--jobs 8 differs from --jobs 1:\nexit %d vs %d\n--- stdout\n%s\n--- want\n%s\n--- stderr\n%s\n--- want\n%s",
+						code8, code1, out8, out1, err8, err1)
+				}
+			}
+			if code1 != exitIO {
+				t.Errorf("exit %d, want %d", code1, exitIO)
+			}
+			// The unreadable link stops none of the other inputs.
+			for _, name := range []string{"dangling.yaml", "f298.yaml", "f299.yaml"} {
+				if !strings.Contains(out1+err1, name) {
+					t.Errorf("no finding about %s:\n%s%s", name, out1, err1)
+				}
+			}
+		})
+	}
+}
+
+// TestRunArgumentOrder checks that inputs are reported in the order of
+// the command line, then by position within each input.
+func TestRunArgumentOrder(t *testing.T) {
+	bad := strings.Replace(testPod, "image: nginx:1.25", "image: nginx\n    ports:\n    - containerPort: 0", 1)
+	dir := writeFiles(t, map[string]string{"a.yaml": bad, "b.yaml": bad, "c.yaml": bad})
+	a, b, c := filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml"), filepath.Join(dir, "c.yaml")
+	_, _, errOut := runCLI(t, "--jobs", "8", c, a, b)
+	var order []string
+	for _, line := range strings.Split(errOut, "\n") {
+		if file, pos, ok := strings.Cut(line, ".yaml:"); ok && strings.HasPrefix(file, dir) {
+			order = append(order, filepath.Base(file)+":"+strings.Fields(pos)[0])
+		}
+	}
+	want := []string{"c:8:5", "c:10:22", "a:8:5", "a:10:22", "b:8:5", "b:10:22"}
+	if strings.Join(order, " ") != strings.Join(want, " ") {
+		t.Errorf("findings in order %v, want %v:\n%s", order, want, errOut)
+	}
+}
+
+// TestTextGolden checks the text output for the inputs under
+// testdata/rules, which between them break every family of checks and
+// mismatch every kind of node. An input's "# args: ..." first line
+// adds flags to the run.
+func TestTextGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "rules", "*.yaml"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no inputs: %v", err)
+	}
+	for _, in := range inputs {
+		t.Run(filepath.Base(in), func(t *testing.T) {
+			src, err := os.ReadFile(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := []string{"--lang", "en", "--color", "never"}
+			first, _, _ := strings.Cut(string(src), "\n")
+			if flags, ok := strings.CutPrefix(first, "# args: "); ok {
+				args = append(args, strings.Fields(flags)...)
+			}
+			code, out, errOut := runCLI(t, append(args, in)...)
+			if code != exitInvalid {
+				t.Errorf("exit %d, want %d", code, exitInvalid)
+			}
+			if out != "" {
+				t.Errorf("stdout %q, want findings on stderr only", out)
+			}
+			golden(t, strings.TrimSuffix(in, ".yaml")+".golden", []byte(errOut))
+		})
+	}
+}
+
+func TestWorseExit(t *testing.T) {
+	// From least to most severe, as the usage message documents.
+	order := []int{exitOK, exitInvalid, exitParse, exitNoFiles, exitIO, exitUsage}
+	for i, a := range order {
+		for j, b := range order {
+			want := a
+			if j > i {
+				want = b
+			}
+			if got := worseExit(a, b); got != want {
+				t.Errorf("worseExit(%d, %d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"valid.yaml":                testPod,
+		"invalid.yaml":              strings.Replace(testPod, "nginx:1.25", "nginx:1.25\n    ports:\n    - containerPort: 0", 1),
+		"notyaml.yaml":              "key: [unclosed\n",
+		"empty/.keep":               "",
+		"ignored/pod.yaml":          testPod,
+		"ignored/" + ignoreFileName: "*.yaml\n",
+		"excluded/pod.yaml":         testPod,
+		"warning.yaml":              strings.Replace(testPod, "nginx:1.25", "nginx:latest", 1),
+		"config.yaml":               "require: [metadata..name]\n",
+		"not-a-report.json":         "{}\n",
+	})
+	in := func(name string) string { return filepath.Join(dir, name) }
+	_, report, _ := runCLI(t, "--format", "json", in("invalid.yaml"))
+	if err := os.WriteFile(in("shard.json"), []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"valid", []string{in("valid.yaml")}, exitOK},
+		{"warning only", []string{in("warning.yaml")}, exitOK},
+		{"warning with --fail-on warning", []string{"--fail-on", "warning", in("warning.yaml")}, exitInvalid},
+		{"invalid", []string{in("invalid.yaml")}, exitInvalid},
+		{"invalid with --fail-on never", []string{"--fail-on", "never", in("invalid.yaml")}, exitOK},
+		{"unknown flag", []string{"--no-such-flag", in("valid.yaml")}, exitUsage},
+		{"bad config", []string{"--config", in("config.yaml"), in("valid.yaml")}, exitUsage},
+		{"missing file", []string{in("missing.yaml")}, exitIO},
+		{"not YAML", []string{in("notyaml.yaml")}, exitParse},
+		{"empty directory", []string{in("empty")}, exitNoFiles},
+		{"pattern matching nothing", []string{in("*.yml")}, exitNoFiles},
+		{"every file ignored", []string{in("ignored")}, exitNoFiles},
+		{"every file excluded", []string{"--exclude", "pod.yaml", in("excluded")}, exitNoFiles},
+		{"excluded directory and a file", []string{"--exclude", "pod.yaml", in("excluded"), in("valid.yaml")}, exitNoFiles},
+		{"excluded file named as argument", []string{"--exclude", "pod.yaml", in("excluded/pod.yaml")}, exitOK},
+		{"invalid and not YAML", []string{in("invalid.yaml"), in("notyaml.yaml")}, exitParse},
+		{"not YAML and empty directory", []string{in("notyaml.yaml"), in("empty")}, exitNoFiles},
+		{"empty directory and missing file", []string{in("empty"), in("missing.yaml")}, exitIO},
+		{"missing file and bad config", []string{"--config", in("config.yaml"), in("missing.yaml")}, exitUsage},
+		{"merge of a failing report", []string{"merge", in("shard.json")}, exitInvalid},
+		{"merge of a non-report", []string{"merge", in("shard.json"), in("not-a-report.json")}, exitUsage},
+		{"merge of a missing report", []string{"merge", in("shard.json"), in("missing.json")}, exitIO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, errOut := runCLI(t, tt.args...); code != tt.want {
+				t.Errorf("exit %d, want %d; stderr:\n%s", code, tt.want, errOut)
+			}
+		})
+	}
+}
+
+// TestRunUnreadableInputs checks that inputs that cannot be read are
+// reported, counted and ranked above findings without stopping the
+// run, and that --strict-io does stop it.
+func TestRunUnreadableInputs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a-valid.yaml":   testPod,
+		"b-invalid.yaml": strings.Replace(testPod, "nginx:1.25", "nginx:1.25\n    ports:\n    - containerPort: 0", 1),
+		"c-valid.yaml":   testDeployment,
+		"locked.yaml":    testPod,
+	})
+	in := func(name string) string { return filepath.Join(dir, name) }
+	if err := os.Symlink(in("gone.yaml"), in("dangling.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{in("a-valid.yaml"), in("missing.yaml"), in("b-invalid.yaml"), in("dangling.yaml")}
+	unreadable := 2
+	// Root reads the file whatever its mode.
+	if os.Getuid() != 0 {
+		if err := os.Chmod(in("locked.yaml"), 0); err != nil {
+			t.Fatal(err)
+		}
+		args, unreadable = append(args, in("locked.yaml")), unreadable+1
+	}
+	args = append(args, in("c-valid.yaml"))
+
+	code, _, errOut := runCLI(t, args...)
+	if code != exitIO {
+		t.Errorf("exit %d, want %d", code, exitIO)
+	}
+	want := fmt.Sprintf("%d files checked, 2 valid, %d invalid, %d errors, 0 warnings, %d unreadable\n",
+		len(args), unreadable+1, unreadable+1, unreadable)
+	if !strings.HasSuffix(errOut, want) {
+		t.Errorf("summary is not %q:\n%s", want, errOut)
+	}
+	for _, name := range []string{"missing.yaml", "dangling.yaml", "b-invalid.yaml:10:22"} {
+		if !strings.Contains(errOut, in(name)) {
+			t.Errorf("%s is not reported:\n%s", name, errOut)
+		}
+	}
+
+	code, out, _ := runCLI(t, append([]string{"--format", "json"}, args...)...)
+	var doc struct {
+		Resources []struct{ File string }
+		Summary   struct{ Files, Valid, Invalid, Unreadable int }
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if s := doc.Summary; code != exitIO || s.Files != len(args) || s.Valid != 2 || s.Invalid != unreadable+1 || s.Unreadable != unreadable {
+		t.Errorf("--format json: exit %d, summary %+v", code, s)
+	}
+	// The input after the unreadable ones is checked too.
+	if len(doc.Resources) != 2 || doc.Resources[1].File != in("c-valid.yaml") {
+		t.Errorf("--format json: resources %+v, want a-valid.yaml and c-valid.yaml", doc.Resources)
+	}
+
+	code, _, errOut = runCLI(t, append([]string{"--strict-io"}, args...)...)
+	if code != exitIO || strings.Contains(errOut, in("b-invalid.yaml")) || strings.Contains(errOut, in("c-valid.yaml")) {
+		t.Errorf("--strict-io: exit %d, went on past the first unreadable input:\n%s", code, errOut)
+	}
+}
+
+// TestRunMaxFileSizeGzip checks that --max-file-size holds a .gz input
+// to its decompressed size.
+func TestRunMaxFileSizeGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(testPod + strings.Repeat("# padding\n", 1000)))
+	zw.Close()
+	path := filepath.Join(t.TempDir(), "pod.yaml.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, _, errOut := runCLI(t, "--max-file-size", "4KiB", path)
+	if code != exitIO || !strings.Contains(errOut, path+": [PV907] input exceeds the maximum size of 4KiB") {
+		t.Errorf("exit %d, want %d; stderr:\n%s", code, exitIO, errOut)
+	}
+	if code, _, errOut := runCLI(t, "--max-file-size", "16KiB", path); code != exitOK {
+		t.Errorf("under the limit: exit %d; stderr:\n%s", code, errOut)
+	}
+}
+
+// TestRunMessageCatalog checks that catalog entries apply by code
+// before rule ID, with the fields of --msg-template.
+func TestRunMessageCatalog(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"pod.yaml": strings.Replace(strings.Replace(testPod, "- name: web", "- name: Web", 1), "nginx:1.25", "nginx:latest", 1),
+		"config.yaml": `messages:
+  PV010:
+    message: "name at {{.Pos}} (column {{.Col}}) breaks the naming policy"
+  PV130:
+    hint: by code
+  latest-tag:
+    hint: by rule
+`,
+	})
+	pod := filepath.Join(dir, "pod.yaml")
+	code, _, errOut := runCLI(t, "--lang", "en", "--color", "never", "--config", filepath.Join(dir, "config.yaml"), pod)
+	if code != exitInvalid {
+		t.Fatalf("exit %d, want %d: %s", code, exitInvalid, errOut)
+	}
+	for _, want := range []string{
+		pod + ":7:5 [PV010] spec.containers[name=Web].name name at " + pod + ":7:5 (column 5) breaks the naming policy\n",
+		"pin a version; by code\n",
+	} {
+		if !strings.Contains(errOut, want) {
+			t.Errorf("output lacks %q:\n%s", want, errOut)
+		}
+	}
+	if strings.Contains(errOut, "by rule") || strings.Contains(errOut, "unknown") {
+		t.Errorf("rule entry applied or codes warned about as unknown:\n%s", errOut)
+	}
+}
+
+func TestRunQuiet(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"valid.yaml":   testPod,
+		"invalid.yaml": strings.Replace(testPod, "nginx:1.25", "nginx:1.25\n    ports:\n    - containerPort: 0", 1),
+		"warning.yaml": strings.Replace(testPod, "nginx:1.25", "nginx:latest", 1),
+	})
+	in := func(name string) string { return filepath.Join(dir, name) }
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr int // lines expected on stderr
+	}{
+		{"invalid", []string{"-q", in("invalid.yaml")}, exitInvalid, 0},
+		{"valid", []string{"--quiet", in("valid.yaml")}, exitOK, 0},
+		{"warning", []string{"-q", in("warning.yaml")}, exitOK, 0},
+		{"warning with --fail-on warning", []string{"-q", "--fail-on", "warning", in("warning.yaml")}, exitInvalid, 0},
+		{"quiet wins over --format json", []string{"-q", "--format", "json", in("invalid.yaml")}, exitInvalid, 0},
+		{"quiet wins over --format sarif", []string{"--format", "sarif", "-q", in("invalid.yaml"), in("valid.yaml")}, exitInvalid, 0},
+		{"missing file", []string{"-q", in("invalid.yaml"), in("missing.yaml")}, exitIO, 1},
+		{"bad flag value", []string{"-q", "--fail-on", "sometimes", in("valid.yaml")}, exitUsage, 1},
+		{"with --output", []string{"-q", "--output", in("out.json"), in("valid.yaml")}, exitUsage, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit %d, want %d", code, tt.code)
+			}
+			if stdout != "" {
+				t.Errorf("stdout %q, want none", stdout)
+			}
+			if lines := strings.Count(stderr, "\n"); lines != tt.stderr {
+				t.Errorf("stderr has %d lines, want %d:\n%s", lines, tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestRunFailOnWarningConfirmation(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"warning.yaml": strings.Replace(testPod, "nginx:1.25", "nginx:latest", 1),
+	})
+	file := filepath.Join(dir, "warning.yaml")
+	tests := []struct {
+		name    string
+		args    []string
+		ok      bool
+		summary string
+	}{
+		{"default", []string{file}, true, "1 file checked, 1 valid, 0 invalid"},
+		{"--fail-on warning", []string{"--fail-on", "warning", file}, false, "1 file checked, 0 valid, 1 invalid"},
+		{"--fail-on never", []string{"--fail-on", "never", file}, true, "1 file checked, 1 valid, 0 invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stdout, stderr := runCLI(t, tt.args...)
+			if ok := strings.Contains(stdout, "OK "); ok != tt.ok {
+				t.Errorf("OK line printed = %v, want %v:\n%s", ok, tt.ok, stdout)
+			}
+			if !strings.Contains(stderr, tt.summary) {
+				t.Errorf("summary does not read %q:\n%s", tt.summary, stderr)
+			}
+		})
+	}
+}
+
+func TestRunVerbose(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"pod.yaml": strings.Replace(testPod, "nginx:1.25", "nginx:1.25\n    ports:\n    - containerPort: 0", 1),
+	})
+	pod := filepath.Join(dir, "pod.yaml")
+	code, _, stderr := runCLI(t, "-v", "--log-format", "json", "--no-snippets", pod)
+	if code != exitInvalid {
+		t.Fatalf("exit %d, want %d", code, exitInvalid)
+	}
+	// The findings and the summary are text; every other line is a log
+	// record.
+	checked := map[string]string{}
+	var text []string
+	for _, line := range strings.Split(strings.TrimSuffix(stderr, "\n"), "\n") {
+		var rec struct{ Level, Msg, File, Field, Result string }
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			text = append(text, line)
+			continue
+		}
+		if rec.Level != "DEBUG" && rec.Level != "INFO" {
+			t.Errorf("record at level %s: %s", rec.Level, line)
+		}
+		if rec.Msg == "checked" {
+			if rec.File != pod {
+				t.Errorf("record of file %q, want %q", rec.File, pod)
+			}
+			checked[rec.Field] = rec.Result
+		}
+	}
+	tests := []struct {
+		field, result string
+	}{
+		{"apiVersion", "string ok"},
+		{"metadata.name", "string ok, length ok"},
+		{"metadata.labels", "absent, skipped"},
+		{"spec.hostNetwork", "absent, skipped"},
+		{"spec.containers[name=web].name", "string ok, length ok, format ok"},
+		{"spec.containers[name=web].image", "string ok, format ok, tag ok"},
+		{"spec.containers[name=web].ports[0].containerPort", "range error"},
+		{"spec.containers[name=web].livenessProbe", "absent, skipped"},
+	}
+	for _, tt := range tests {
+		if got, ok := checked[tt.field]; !ok || got != tt.result {
+			t.Errorf("%s: logged %q, want %q", tt.field, got, tt.result)
+		}
+	}
+	if len(text) != 2 || !strings.Contains(text[0], "containerPort") || !strings.HasPrefix(text[1], "1 file checked") {
+		t.Errorf("text lines %q, want one finding and the summary", text)
+	}
+
+	// In the default log format every record starts with its level.
+	_, _, stderr = runCLI(t, "--verbose", "--no-snippets", pod)
+	want := fmt.Sprintf("level=DEBUG msg=checked file=%s field=\"spec.containers[name=web].image\" result=\"string ok, format ok, tag ok\"", pod)
+	if !strings.Contains(stderr, want) {
+		t.Errorf("stderr does not log %s:\n%s", want, stderr)
+	}
+	if code, _, stderr := runCLI(t, pod); code != exitInvalid || strings.Contains(stderr, "msg=checked") {
+		t.Errorf("without --verbose: exit %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestRunExclude(t *testing.T) {
+	invalid := strings.Replace(testPod, "nginx:1.25", "nginx:1.25\n    ports:\n    - containerPort: 0", 1)
+	root := writeFiles(t, map[string]string{
+		"repo/a.yaml":             testPod,
+		"repo/app-generated.yaml": invalid,
+		"repo/testdata/t.yaml":    invalid,
+		"repo/sub/s.yaml":         testPod,
+		// The negation brings app-generated.yaml back, but not past
+		// --exclude.
+		"repo/" + ignoreFileName: "*-generated.yaml\n!app-generated.yaml\n",
+	})
+	repo := filepath.Join(root, "repo")
+	tests := []struct {
+		name    string
+		args    []string
+		code    int
+		summary string
+	}{
+		{"no exclusions", []string{repo}, exitInvalid,
+			"4 files checked, 2 valid, 2 invalid, 2 errors, 0 warnings"},
+		{"repeated", []string{"--exclude", "testdata/**", "--exclude", "*-generated.yaml", repo}, exitOK,
+			"2 files checked, 2 valid, 0 invalid, 0 errors, 0 warnings, 2 excluded"},
+		{"wins over a negation in the ignore file", []string{"--exclude", "*-generated.yaml", repo}, exitInvalid,
+			"3 files checked, 2 valid, 1 invalid, 1 error, 0 warnings, 1 excluded"},
+		{"file arguments win", []string{"--exclude", "*-generated.yaml", "--exclude", "testdata/", repo, filepath.Join(repo, "app-generated.yaml")}, exitInvalid,
+			"3 files checked, 2 valid, 1 invalid, 1 error, 0 warnings, 2 excluded"},
+		// Patterns match below the walked directory, so sub/*.yaml
+		// matches in repo but not in repo/sub.
+		{"relative to the root", []string{"--exclude", "sub/*.yaml", "--exclude", "testdata/", "--exclude", "*-generated.yaml", repo}, exitOK,
+			"1 file checked, 1 valid, 0 invalid, 0 errors, 0 warnings, 3 excluded"},
+		{"relative to another root", []string{"--exclude", "sub/*.yaml", filepath.Join(repo, "sub")}, exitOK,
+			"1 file checked, 1 valid, 0 invalid, 0 errors, 0 warnings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, append([]string{"--no-snippets"}, tt.args...)...)
+			if code != tt.code {
+				t.Errorf("exit %d, want %d", code, tt.code)
+			}
+			if !strings.Contains(stderr, "\n"+tt.summary+"\n") && !strings.HasPrefix(stderr, tt.summary+"\n") {
+				t.Errorf("stderr does not end in %q:\n%s", tt.summary, stderr)
+			}
+		})
+	}
+
+	_, _, stderr := runCLI(t, "-v", "--exclude", "*-generated.yaml", repo)
+	want := fmt.Sprintf("level=DEBUG msg=excluding path=%s pattern=*-generated.yaml", filepath.Join(repo, "app-generated.yaml"))
+	if !strings.Contains(stderr, want) {
+		t.Errorf("--verbose does not log %s:\n%s", want, stderr)
+	}
+	if code, _, stderr := runCLI(t, "--exclude", "!a.yaml", repo); code != exitUsage {
+		t.Errorf("negated --exclude: exit %d, want %d:\n%s", code, exitUsage, stderr)
+	}
+}
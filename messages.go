@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// messageOverride changes how the findings of one check read. Message
+// is a text/template over the same fields as --msg-template (.File,
+// .Line, .Col, .Pos, .Code, .Rule, .Field, .Message, ...) that replaces
+// the finding's message; Hint is appended to the message, e.g. a
+// pointer to a runbook.
+type messageOverride struct {
+	Message string `yaml:"message"`
+	Hint    string `yaml:"hint"`
+
+	tmpl *template.Template
+}
+
+// messageCatalog maps codes or rule IDs to their overrides. It is
+// applied when findings are rendered, so validation itself never sees
+// it.
+type messageCatalog map[string]*messageOverride
+
+// compile parses the templates, rendering each once against a sample
+// finding so that misspelt fields fail at startup rather than mid-run.
+func (c messageCatalog) compile() error {
+	for _, id := range slices.Sorted(maps.Keys(c)) {
+		m := c[id]
+		field := "messages[" + id + "]"
+		if m == nil || (m.Message == "" && m.Hint == "") {
+			return &validator.ConfigError{Field: field, Err: errors.New("needs a message or a hint")}
+		}
+		if m.Message == "" {
+			continue
+		}
+		t, err := template.New(id).Option("missingkey=error").Parse(m.Message)
+		if err == nil {
+			err = t.Execute(io.Discard, newMsgFinding(&validator.ValidationError{File: "f", Line: 1, Column: 1}))
+		}
+		if err != nil {
+			return &validator.ConfigError{Field: field + ".message", Err: err}
+		}
+		m.tmpl = t
+	}
+	return nil
+}
+
+// warnUnknown logs catalog entries that name no code or rule this run
+// can report, which usually means a typo.
+func (c messageCatalog) warnUnknown(log *slog.Logger, require []validator.RequiredField) {
+	known := make(map[string]bool)
+	for _, ch := range validator.Checks() {
+		known[ch.Code] = true
+	}
+	for _, id := range validator.BuiltinRules() {
+		known[id] = true
+	}
+	for _, r := range require {
+		known[r.Rule] = true
+	}
+	for _, id := range slices.Sorted(maps.Keys(c)) {
+		if !known[id] {
+			log.Warn("message catalog names an unknown code or rule", "id", id)
+		}
+	}
+}
+
+// lookup returns the override for the findings of code or rule, the
+// code's taking precedence.
+func (c messageCatalog) lookup(code, rule string) *messageOverride {
+	if m := c[code]; code != "" && m != nil {
+		return m
+	}
+	if rule == "" {
+		return nil
+	}
+	return c[rule]
+}
+
+// render returns e with its override applied. e itself is left
+// untouched so every output format starts from the same finding.
+func (c messageCatalog) render(e *validator.ValidationError) *validator.ValidationError {
+	m := c.lookup(e.Code, e.Rule)
+	if m == nil {
+		return e
+	}
+	out := *e
+	if m.tmpl != nil {
+		var b strings.Builder
+		if err := m.tmpl.Execute(&b, newMsgFinding(e)); err == nil {
+			out.Message = b.String()
+		}
+	}
+	if m.Hint != "" {
+		out.Message += "; " + m.Hint
+	}
+	return &out
+}
+
+// findingLanguage resolves --lang: an explicit language must be one the
+// validator has messages in, while an empty one takes the language of
+// LC_ALL, LC_MESSAGES or LANG and falls back to English.
+func findingLanguage(lang string) (string, error) {
+	if lang != "" {
+		l, ok := validator.ParseLanguage(lang)
+		if !ok {
+			return "", fmt.Errorf("unsupported --lang %q: want %s", lang, strings.Join(validator.Languages(), " or "))
+		}
+		return l, nil
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			if l, ok := validator.ParseLanguage(v); ok {
+				return l, nil
+			}
+			break
+		}
+	}
+	return "en", nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// msgPresets are the --msg-template values that name a template. The
+// default one writes exactly what validator.FormatText does.
+var msgPresets = map[string]string{
+	"default": `{{.Pos}} {{if eq .Severity "warning"}}warning: {{end}}{{with .Code}}[{{.}}] {{end}}` +
+		`{{with .Field}}{{.}} {{end}}{{.Message}}{{if gt .Count 1}} (x{{.Count}}){{end}}`,
+	// gcc-style "file:line:col: error: message", which most editors and
+	// CI log parsers recognize.
+	"gcc": `{{.File}}:{{if .Line}}{{.Line}}:{{if .Col}}{{.Col}}:{{end}}{{end}} {{.Severity}}: ` +
+		`{{with .Field}}{{.}} {{end}}{{.Message}}{{with .Code}} [{{.}}]{{end}}`,
+	// Visual Studio style "file(line,col): error CODE: message".
+	"msvc": `{{.File}}({{.Line}},{{.Col}}): {{.Severity}}{{with .Code}} {{.}}{{end}}: {{with .Field}}{{.}} {{end}}{{.Message}}`,
+}
+
+var defaultMsgTemplate = template.Must(template.New("msg-template").Parse(msgPresets["default"]))
+
+// msgFinding is what a --msg-template is executed against.
+type msgFinding struct {
+	File      string
+	Line, Col int // 0 when unknown
+	// Pos is "file:line:col", shortened to what is known, and "file:"
+	// when no line is. Its file is followed by " [Resource]" when the
+	// finding names one, as in validator.FormatText.
+	Pos      string
+	Resource string // "Kind/name" of the document, in inputs of several
+	Code     string
+	Rule     string
+	Severity string // error or warning
+	Field    string
+	Path     string // JSONPath of Field, with indices
+	Message  string
+	Count    int // occurrences the finding stands for, at least 1
+}
+
+func newMsgFinding(e *validator.ValidationError) msgFinding {
+	file := e.File
+	if e.Resource != "" {
+		file += " [" + e.Resource + "]"
+	}
+	pos := file + ":"
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		pos = fmt.Sprintf("%s:%d:%d", file, e.Line, e.Column)
+	case e.Line > 0:
+		pos = fmt.Sprintf("%s:%d", file, e.Line)
+	}
+	return msgFinding{File: e.File, Line: e.Line, Col: e.Column, Pos: pos, Resource: e.Resource, Code: e.Code, Rule: e.Rule,
+		Severity: e.Severity.String(), Field: e.Field, Path: e.Path, Message: e.Message, Count: max(e.Occurrences, 1)}
+}
+
+// parseMsgTemplate parses --msg-template, a preset name or a template,
+// executing it once against a sample finding so that misspelt fields
+// fail at startup rather than mid-run.
+func parseMsgTemplate(s string) (*template.Template, error) {
+	text, ok := msgPresets[s]
+	if !ok {
+		text = s
+	}
+	t, err := template.New("msg-template").Parse(text)
+	if err == nil {
+		err = t.Execute(io.Discard, newMsgFinding(&validator.ValidationError{File: "f", Line: 1, Column: 1}))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid --msg-template: %v (or name a preset: %s)",
+			err, strings.Join(slices.Sorted(maps.Keys(msgPresets)), ", "))
+	}
+	return t, nil
+}
+
+// templateReporter writes one line per finding, rendered by a
+// --msg-template.
+type templateReporter struct {
+	w    io.Writer
+	tmpl *template.Template
+	err  error
+}
+
+func (t *templateReporter) Report(e *validator.ValidationError) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, newMsgFinding(e)); err != nil {
+		if t.err == nil {
+			t.err = err
+		}
+		return
+	}
+	t.write(b.String())
+}
+
+func (t *templateReporter) write(line string) {
+	if _, err := io.WriteString(t.w, line+"\n"); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *templateReporter) Summary(s validator.Stats) error {
+	if s.Omitted > 0 {
+		t.write(s.OmittedNotice())
+	}
+	return t.err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// pathMode renders input names in findings the way --path-mode asks:
+// as given on the command line, absolute, or relative to a base
+// directory.
+type pathMode struct {
+	mode string // as-given, absolute or relative
+	base string // absolute base directory, for relative
+}
+
+// newPathMode checks --path-mode and --base-dir; an empty base is the
+// working directory.
+func newPathMode(mode, base string) (pathMode, error) {
+	switch mode {
+	case "as-given", "absolute":
+		if base != "" {
+			return pathMode{}, errors.New("--base-dir needs --path-mode=relative")
+		}
+		return pathMode{mode: mode}, nil
+	case "relative":
+		if base == "" {
+			base = "."
+		}
+		abs, err := filepath.Abs(base)
+		if err != nil {
+			return pathMode{}, fmt.Errorf("invalid --base-dir %q: %v", base, err)
+		}
+		return pathMode{mode: mode, base: abs}, nil
+	}
+	return pathMode{}, fmt.Errorf("invalid --path-mode %q: want relative, absolute or as-given", mode)
+}
+
+// render returns name as the mode writes it. Names of archive members,
+// "archive.tgz!member", keep their member part. A name outside the base
+// directory is left as given rather than climbing out of it with "..".
+func (p pathMode) render(name string) string {
+	if p.mode == "" || p.mode == "as-given" || isURL(name) {
+		return name
+	}
+	file, member, inArchive := strings.Cut(name, "!")
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return name
+	}
+	file = abs
+	if p.mode == "relative" {
+		rel, err := filepath.Rel(p.base, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return name
+		}
+		file = rel
+	}
+	if inArchive {
+		file += "!" + member
+	}
+	return file
+}
+
+// pathReporter hands findings to the Reporter it wraps with their file
+// names rendered by paths. The findings themselves keep the name the
+// input was read under, so that snippets can still open it.
+type pathReporter struct {
+	validator.Reporter
+	paths pathMode
+}
+
+func (p *pathReporter) Report(e *validator.ValidationError) {
+	out := *e
+	out.File = p.paths.render(e.File)
+	p.Reporter.Report(&out)
+}
+
+func (p *pathReporter) Resource(r validator.Resource) {
+	if rr, ok := p.Reporter.(validator.ResourceReporter); ok {
+		r.File = p.paths.render(r.File)
+		rr.Resource(r)
+	}
+}
+
+func (p *pathReporter) StartFile(name string) {
+	if fr, ok := p.Reporter.(validator.FileReporter); ok {
+		fr.StartFile(p.paths.render(name))
+	}
+}
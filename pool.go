@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// outcome is what validating one input yields, ahead of reporting it.
+type outcome struct {
+	res *validator.Result
+	err error
+	src []byte // the body of a fetched URL
+}
+
+// check validates path, fetching it first when it is a URL. It only
+// reads the runner's settings, so several inputs can be checked at once.
+func (r *runner) check(ctx context.Context, path string) *outcome {
+	o := &outcome{}
+	if !isURL(path) {
+		o.res, o.err = validator.ValidateFile(ctx, path, r.opts)
+		return o
+	}
+	o.src, o.err = r.fetch(ctx, path)
+	if o.err == nil {
+		o.res, o.err = validator.ValidateBytes(ctx, path, o.src, r.opts)
+	}
+	return o
+}
+
+// pool checks inputs on several goroutines while the run reports them
+// one at a time in their order, so that the output does not depend on
+// the number of workers. A failing input only fails its own outcome.
+type pool struct {
+	outcomes []*outcome
+	done     []chan struct{} // closed once the outcome is in; nil for inputs not pooled
+	ahead    chan struct{}   // bounds the outcomes held before they are reported
+	cancel   context.CancelFunc
+}
+
+// startPool starts checking the files and URLs among paths on jobs
+// workers, at most twice as many ahead of the one being reported.
+// Standard input and archives, which report part by part, are left to
+// the caller, as is everything when jobs is 1 or --fix rewrites files
+// first.
+func (r *runner) startPool(paths []string, jobs int) *pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &pool{outcomes: make([]*outcome, len(paths)), done: make([]chan struct{}, len(paths)), cancel: cancel}
+	if jobs <= 1 || r.fix {
+		return p
+	}
+	for i, path := range paths {
+		if path != stdinArg && (isURL(path) || !isArchive(path)) {
+			p.done[i] = make(chan struct{})
+		}
+	}
+	p.ahead = make(chan struct{}, 2*jobs)
+	work := make(chan int)
+	go func() {
+		defer close(work)
+		for i := range paths {
+			if p.done[i] == nil {
+				continue
+			}
+			select {
+			case p.ahead <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case work <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range jobs {
+		go func() {
+			for i := range work {
+				p.outcomes[i] = r.check(ctx, paths[i])
+				close(p.done[i])
+			}
+		}()
+	}
+	return p
+}
+
+// wait returns the outcome of input i once it is in, or nil when the
+// input is not pooled and is to be checked by the caller.
+func (p *pool) wait(i int) *outcome {
+	if p.done[i] == nil {
+		return nil
+	}
+	<-p.done[i]
+	<-p.ahead
+	return p.outcomes[i]
+}
+
+// stop abandons the inputs not yet reported.
+func (p *pool) stop() { p.cancel() }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// progressThreshold is the number of queued inputs above which a run
+// reports progress.
+const progressThreshold = 20
+
+// progress reports how far a batch run has got. On a terminal it keeps
+// a single line updated in place; elsewhere it prints a line every
+// interval so CI logs show the run is alive.
+type progress struct {
+	w        io.Writer
+	tty      bool
+	total    int
+	interval time.Duration
+
+	done, errors int
+	last         time.Time
+	shown        bool // whether the in-place line is on screen
+}
+
+// newProgress returns nil, which is a valid no-op progress, when the
+// run is too small or progress is disabled.
+func newProgress(w io.Writer, total int, interval time.Duration, disabled bool) *progress {
+	if disabled || total <= progressThreshold {
+		return nil
+	}
+	return &progress{w: w, tty: isTerminal(w), total: total, interval: interval, last: time.Now()}
+}
+
+// advance records one finished input with its error count.
+func (p *progress) advance(errors int) {
+	if p == nil {
+		return
+	}
+	p.done++
+	p.errors += errors
+	switch {
+	case p.tty:
+		p.draw()
+	case p.interval > 0 && time.Since(p.last) >= p.interval:
+		p.last = time.Now()
+		fmt.Fprintln(p.w, p.line())
+	}
+}
+
+// clear removes the in-place line so findings can be printed; the next
+// advance draws it again below them.
+func (p *progress) clear() {
+	if p == nil || !p.shown {
+		return
+	}
+	fmt.Fprint(p.w, "\r\x1b[K")
+	p.shown = false
+}
+
+func (p *progress) draw() {
+	fmt.Fprint(p.w, "\r\x1b[K"+p.line())
+	p.shown = true
+}
+
+func (p *progress) line() string {
+	return fmt.Sprintf("validated %d/%d files, %d errors", p.done, p.total, p.errors)
+}
+
+// isTerminal reports whether w is a character device such as a TTY.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	st, err := f.Stat()
+	return err == nil && st.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/abdddev/go-magistr-lesson2-tpl/validator"
+)
+
+// snippetReporter follows every text finding with the source line it
+// points at and a caret under its column, the way compilers do.
+// Findings come grouped by input, so only the lines of the current
+// input are kept.
+type snippetReporter struct {
+	validator.Reporter
+	w     io.Writer
+	color bool
+
+	file  string
+	lines []string // nil when file has no snippets
+
+	given map[string][]byte // sources of inputs not read from a file of their name
+}
+
+// provide has the snippets of the input called name taken from src,
+// which was validated under that name without being read from it, as
+// with standard input.
+func (s *snippetReporter) provide(name string, src []byte) {
+	if s == nil {
+		return
+	}
+	if s.given == nil {
+		s.given = make(map[string][]byte)
+	}
+	s.given[name] = src
+}
+
+func (s *snippetReporter) Report(e *validator.ValidationError) {
+	s.Reporter.Report(e)
+	if e.Line == 0 {
+		return
+	}
+	if e.File != s.file {
+		s.file, s.lines = e.File, sourceLines(e.File)
+		if src, ok := s.given[e.File]; ok {
+			s.lines = snippetLines(src)
+		}
+	}
+	if e.Line > len(s.lines) {
+		return
+	}
+	src := s.lines[e.Line-1]
+	out := gutter(e.Line) + src + "\n"
+	if e.Column > 0 {
+		caret := "^"
+		if s.color {
+			caret = ansiBold + ansiRed + caret + ansiReset
+		}
+		out += gutter(0) + caretIndent(src, e.Column) + caret + "\n"
+	}
+	io.WriteString(s.w, out)
+}
+
+// sourceLines reads name and splits it into lines. Archive members and
+// unreadable inputs have none.
+func sourceLines(name string) []string {
+	if strings.Contains(name, "!") {
+		return nil
+	}
+	src, err := os.ReadFile(name)
+	if err == nil {
+		src, err = validator.Decompress(name, src, 0)
+	}
+	if err != nil {
+		return nil
+	}
+	return snippetLines(src)
+}
+
+func snippetLines(src []byte) []string {
+	return strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+}
+
+// gutter returns the line-number column in front of a snippet line;
+// zero leaves the number out.
+func gutter(line int) string {
+	if line == 0 {
+		return "      | "
+	}
+	return strings.Repeat(" ", max(5-len(strconv.Itoa(line)), 0)) + strconv.Itoa(line) + " | "
+}
+
+// caretIndent returns the whitespace that puts a caret under the
+// 1-based character column col of src. Tabs are copied so the caret
+// lines up however wide the terminal renders them.
+func caretIndent(src string, col int) string {
+	var b strings.Builder
+	for i, r := range []rune(src) {
+		if i >= col-1 {
+			break
+		}
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleUnusedAnchor is the rule ID of unusedAnchors findings.
+const ruleUnusedAnchor = "unused-anchor"
+
+// unusedAnchors reports a warning for every anchor in doc that no alias
+// refers to. An alias binds to the closest preceding definition of its
+// name, so a shadowed anchor that is never used before being redefined
+// is reported too.
+func unusedAnchors(doc *yaml.Node) []*ValidationError {
+	type def struct {
+		node  *yaml.Node
+		field string
+	}
+	var defs []def
+	used := make(map[*yaml.Node]bool)
+	var walk func(n *yaml.Node, field string)
+	walk = func(n *yaml.Node, field string) {
+		if n.Kind == yaml.AliasNode {
+			used[n.Alias] = true
+			return
+		}
+		if n.Anchor != "" {
+			defs = append(defs, def{n, field})
+		}
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := joinKey(field, n.Content[i].Value)
+				walk(n.Content[i], key)
+				walk(n.Content[i+1], key)
+			}
+		case yaml.SequenceNode:
+			for i, it := range n.Content {
+				walk(it, itemPath(field, n, i))
+			}
+		}
+	}
+	walk(doc, "")
+	var out []*ValidationError
+	for _, d := range defs {
+		if !used[d.node] {
+			e := newError(CategoryFormat, d.field, d.node, "defines anchor '&%s', which no alias uses", d.node.Anchor)
+			e.Severity, e.Rule = SeverityWarning, ruleUnusedAnchor
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+var (
+	unknownAnchorRe = regexp.MustCompile(`unknown anchor '([^']*)' referenced`)
+	anchorDefRe     = regexp.MustCompile(`(?:^|[\s\[{,:-])&([^\s,\[\]{}]+)`)
+)
+
+// UnknownAliasError reports an alias that refers to no anchor. The
+// parser only names the alias, so Line is that of its first occurrence
+// in the source and Suggestion the closest anchor that is defined.
+type UnknownAliasError struct {
+	Alias      string
+	Line       int
+	Suggestion string
+}
+
+func (e *UnknownAliasError) Error() string {
+	msg := fmt.Sprintf("alias '*%s' refers to no anchor", e.Alias)
+	if e.Line > 0 {
+		msg = fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '&%s'?)", e.Suggestion)
+	}
+	return msg
+}
+
+// explainParseError replaces yaml.v3's terse unknown-anchor error with
+// one that locates the alias and suggests an anchor; other errors are
+// returned unchanged. data holds the input from line firstLine on.
+func explainParseError(data []byte, firstLine int, err error) error {
+	m := unknownAnchorRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	e := &UnknownAliasError{Alias: m[1]}
+	use := regexp.MustCompile(`(?:^|[\s\[{,:-])\*` + regexp.QuoteMeta(e.Alias) + `(?:$|[\s,\]}])`)
+	for i, line := range strings.Split(string(data), "\n") {
+		if e.Line == 0 && use.MatchString(line) {
+			e.Line = firstLine + i
+		}
+	}
+	best := -1
+	for _, d := range anchorDefRe.FindAllStringSubmatch(string(data), -1) {
+		if dist := editDistance(e.Alias, d[1]); dist <= max(len(e.Alias)/3, 2) && (best < 0 || dist < best) {
+			best, e.Suggestion = dist, d[1]
+		}
+	}
+	return e
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnusedAnchors(t *testing.T) {
+	tests := []struct {
+		name string
+		meta string // what follows metadata.name
+		want []string
+	}{
+		{"used", "  labels: &labels {app: web}\n  annotations: *labels\n", nil},
+		{"unused", "  labels: &labels {app: web}\n",
+			[]string{"5 metadata.labels '&labels'"}},
+		{"nested, outer unused", "  labels: &outer\n    app: &inner web\n  annotations:\n    copy: *inner\n",
+			[]string{"5 metadata.labels '&outer'"}},
+		{"nested, inner unused", "  labels: &outer\n    app: &inner web\n  annotations: *outer\n",
+			[]string{"6 metadata.labels.app '&inner'"}},
+		{"shadowed before use", "  annotations:\n    a: &note one\n    b: &note two\n    c: *note\n",
+			[]string{"6 metadata.annotations.a '&note'"}},
+		{"shadowed after use", "  annotations:\n    a: &note one\n    b: *note\n    c: &note two\n",
+			[]string{"8 metadata.annotations.c '&note'"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := strings.Replace(validPod, "  name: web\nspec", "  name: web\n"+tt.meta+"spec", 1)
+			res := mustValidate(t, "pod.yaml", src, Options{})
+			var got []string
+			for _, e := range res.ByRule(ruleUnusedAnchor) {
+				if e.Severity != SeverityWarning {
+					t.Errorf("%v is an error, want a warning", e)
+				}
+				anchor := e.Message[strings.Index(e.Message, "'"):strings.Index(e.Message, ",")]
+				got = append(got, fmt.Sprintf("%d %s %s", e.Line, e.Field, anchor))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("findings %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownAliasError(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want UnknownAliasError
+	}{
+		{"misspelled", "a: &labels {app: web}\nb: *lables\n", UnknownAliasError{Alias: "lables", Line: 2, Suggestion: "labels"}},
+		{"closest of several", "a: &web x\nb: &webs y\nc: *wbs\n", UnknownAliasError{Alias: "wbs", Line: 3, Suggestion: "webs"}},
+		{"nothing close", "a: &labels x\nb: *ports\n", UnknownAliasError{Alias: "ports", Line: 2}},
+		{"in a sequence", "a: &item x\nb: [*iten]\n", UnknownAliasError{Alias: "iten", Line: 2, Suggestion: "item"}},
+		{"in a later document", validPod + "---\na: &name x\nb: *nme\n", UnknownAliasError{Alias: "nme", Line: 13, Suggestion: "name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateBytes(context.Background(), "in.yaml", []byte(tt.src), Options{})
+			var ue *UnknownAliasError
+			if !errors.As(err, &ue) || !errors.Is(err, ErrNotYAML) {
+				t.Fatalf("err = %v, want an ErrNotYAML *UnknownAliasError", err)
+			}
+			if *ue != tt.want {
+				t.Errorf("UnknownAliasError = %+v, want %+v", *ue, tt.want)
+			}
+		})
+	}
+}
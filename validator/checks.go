@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"errors"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checker carries what the built-in validators of one document share:
+// the run options and the report callback.
+type checker struct {
+	opts   *Options
+	report ReportFunc
+	// trace records checks that passed; report adds the ones that
+	// failed.
+	trace *trace
+
+	// namespace and labels of the document, for scoped policies.
+	namespace string
+	labels    map[string]string
+	// hostNetwork is set while checking the containers of a pod spec
+	// with hostNetwork: true.
+	hostNetwork bool
+}
+
+// requireString reports a missing or non-string field key of m and
+// returns the value node when it is a usable string.
+func (c *checker) requireString(m *yaml.Node, key, path string) *yaml.Node {
+	field := joinKey(path, key)
+	n := getField(m, key)
+	if isNull(n) {
+		c.report(required(field, m))
+		return nil
+	}
+	return c.stringValue(n, field, true)
+}
+
+// optional returns the value of key of m, noting in the trace when the
+// field is absent and its checks are skipped.
+func (c *checker) optional(m *yaml.Node, key, path string) *yaml.Node {
+	n := getField(m, key)
+	if isNull(n) {
+		c.trace.note(joinKey(path, key), "absent, skipped")
+		return nil
+	}
+	return n
+}
+
+// Length limits Kubernetes puts on names.
+const (
+	maxDNSLabel     = 63  // container and volume names
+	maxDNSSubdomain = 253 // metadata.name
+	maxPortName     = 15  // IANA service names, used for ports
+)
+
+// requireName is requireString for names of at most max characters.
+func (c *checker) requireName(m *yaml.Node, key, path string, max int) *yaml.Node {
+	n := c.requireString(m, key, path)
+	if n != nil && !c.nameLength(n, joinKey(path, key), max) {
+		return nil
+	}
+	return n
+}
+
+// nameLength reports the name in string n when it is longer than max,
+// stating both lengths as the apiserver counts them, in bytes.
+func (c *checker) nameLength(n *yaml.Node, field string, max int) bool {
+	if len(n.Value) <= max {
+		c.trace.note(field, "length ok")
+		return true
+	}
+	c.report(newError(CategoryRange, field, n, "is %d characters, maximum is %d", len(n.Value), max))
+	return false
+}
+
+// dnsLabelRe is the RFC 1123 label syntax of container names.
+var dnsLabelRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// dnsLabel reports the name in string n unless it is an RFC 1123 label.
+func (c *checker) dnsLabel(n *yaml.Node, field string) {
+	if dnsLabelRe.MatchString(n.Value) {
+		c.trace.note(field, "format ok")
+		return
+	}
+	c.report(newError(CategoryFormat, field, n,
+		"has invalid format '%s': must be lowercase letters, digits and '-', starting and ending with a letter or digit", n.Value))
+}
+
+// requireEnum is requireString for fields restricted to a fixed set of
+// values, which are never subject to scalar coercion.
+func (c *checker) requireEnum(m *yaml.Node, key, path string) *yaml.Node {
+	field := joinKey(path, key)
+	n := getField(m, key)
+	if isNull(n) {
+		c.report(required(field, m))
+		return nil
+	}
+	return c.stringValue(n, field, false)
+}
+
+// stringValue checks that n is a string. When coercible is set and
+// Options.CoerceScalars is enabled, plain ints, floats and bools are
+// accepted as their string rendering with a warning instead of an
+// error. Enum-like fields pass coercible=false so that coercion cannot
+// mask a wrong value.
+func (c *checker) stringValue(n *yaml.Node, field string, coercible bool) *yaml.Node {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		c.trace.note(field, "string ok")
+		return n
+	}
+	if coercible && c.opts.CoerceScalars && n.Kind == yaml.ScalarNode && n.Style == 0 {
+		switch n.Tag {
+		case "!!int", "!!float", "!!bool":
+			w := newError(CategoryType, field, n, "should be string (found %s); quote it as \"%s\"", describeNode(n), n.Value)
+			w.Severity = SeverityWarning
+			c.report(w)
+			return n
+		}
+	}
+	c.report(typeMismatch(field, n, "string"))
+	return nil
+}
+
+// optionalMapping returns the mapping stored under key, reporting a
+// type mismatch when the field is present but not a mapping.
+func (c *checker) optionalMapping(m *yaml.Node, key, path string) *yaml.Node {
+	n := c.optional(m, key, path)
+	if n == nil {
+		return nil
+	}
+	if n.Kind != yaml.MappingNode {
+		c.report(typeMismatch(joinKey(path, key), n, "object"))
+		return nil
+	}
+	c.trace.note(joinKey(path, key), "object ok")
+	return n
+}
+
+// optionalSequence is optionalMapping for sequences.
+func (c *checker) optionalSequence(m *yaml.Node, key, path string) *yaml.Node {
+	n := c.optional(m, key, path)
+	if n == nil {
+		return nil
+	}
+	if n.Kind != yaml.SequenceNode {
+		c.report(typeMismatch(joinKey(path, key), n, "array"))
+		return nil
+	}
+	c.trace.note(joinKey(path, key), "array ok")
+	return n
+}
+
+// optionalBool checks key of m with requireBool when it is present.
+func (c *checker) optionalBool(m *yaml.Node, key, path string) {
+	if n := c.optional(m, key, path); n != nil {
+		c.requireBool(n, joinKey(path, key))
+	}
+}
+
+// requireBool accepts only the plain scalars true and false. YAML 1.1
+// spellings such as yes, on or True resolve inconsistently across
+// parsers (yaml.v3 reads yes as a string but True as a bool), so they
+// are rejected with a message naming the accepted spelling instead of
+// being silently coerced either way.
+func (c *checker) requireBool(n *yaml.Node, field string) (value, ok bool) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!bool" && n.Style == 0 {
+		switch n.Value {
+		case "true":
+			c.trace.note(field, "boolean ok")
+			return true, true
+		case "false":
+			c.trace.note(field, "boolean ok")
+			return false, true
+		}
+	}
+	quoted := n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+	switch {
+	case n.Kind == yaml.ScalarNode && quoted && (n.Value == "true" || n.Value == "false"):
+		c.report(newError(CategoryType, field, n, "must be boolean (found string '%s' — remove the quotes)", n.Value))
+	case n.Kind == yaml.ScalarNode:
+		c.report(newError(CategoryType, field, n, "must be boolean (found '%s' — use true/false)", n.Value))
+	default:
+		c.report(typeMismatch(field, n, "boolean"))
+	}
+	return false, false
+}
+
+// requireIntRange checks that n is an integer within [lo, hi] and
+// returns it. Values that do not fit are reported as out of range, not
+// as a type mismatch.
+func (c *checker) requireIntRange(n *yaml.Node, field string, lo, hi int64) (int64, bool) {
+	v, err := parseInt(n, 64)
+	switch {
+	case errors.Is(err, errNotInt):
+		c.report(typeMismatch(field, n, "int"))
+		return 0, false
+	case err != nil || v < lo || v > hi:
+		c.report(outOfRange(field, n))
+		return 0, false
+	}
+	c.trace.note(field, "range ok")
+	return v, true
+}
+
+// quantity checks that n holds a resource quantity the apiserver would
+// accept.
+func (c *checker) quantity(n *yaml.Node, field string) (Quantity, bool) {
+	if n.Kind != yaml.ScalarNode || (n.Tag != "!!str" && n.Tag != "!!int" && n.Tag != "!!float") {
+		c.report(typeMismatch(field, n, "quantity"))
+		return Quantity{}, false
+	}
+	q, _, err := parseQuantity(n.Value)
+	if errors.Is(err, errQuantityRange) || (err == nil && !q.IsInt64()) {
+		c.report(outOfRange(field, n))
+		return Quantity{}, false
+	}
+	if err != nil || q.Cmp(Quantity{}) < 0 {
+		c.report(invalidFormat(field, n))
+		return Quantity{}, false
+	}
+	c.trace.note(field, "quantity ok")
+	return q, true
+}
+
+// cpuQuantity is quantity for CPU amounts, which the apiserver tracks
+// in millicores: float spellings such as 0.5, .5 or 1e-1 are accepted,
+// but values finer than 1m are rejected instead of being rounded.
+func (c *checker) cpuQuantity(n *yaml.Node, field string) (Quantity, bool) {
+	q, ok := c.quantity(n, field)
+	if !ok {
+		return q, false
+	}
+	if _, exact, _ := parseQuantity(n.Value); !exact {
+		c.report(newError(CategoryFormat, field, n,
+			"has invalid format '%s': CPU is allocated in millicores, so at most three decimal places (1m) are allowed", n.Value))
+		return Quantity{}, false
+	}
+	return q, true
+}
+
+// stringMap checks a mapping of string keys to string values such as
+// labels or annotations.
+func (c *checker) stringMap(m *yaml.Node, path string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		field := joinKey(path, k.Value)
+		c.stringValue(k, field, true)
+		c.stringValue(v, field, true)
+	}
+}
@@ -0,0 +1,226 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestPortBoundaries(t *testing.T) {
+	tests := []struct {
+		port string
+		ok   bool
+	}{
+		{"0", false},
+		{"1", true},
+		{"65535", true},
+		{"65536", false},
+		{"-1", false},
+		{"99999999999999999999", false}, // too large for int64, yet a range finding
+	}
+	for _, tt := range tests {
+		t.Run(tt.port, func(t *testing.T) {
+			for _, key := range []string{"containerPort", "hostPort"} {
+				src := strings.Replace(validPod, "containerPort: 80", "containerPort: 80\n      hostPort: 80", 1)
+				src = strings.Replace(src, key+": 80", key+": "+tt.port, 1)
+				res := mustValidate(t, "pod.yaml", src, Options{DisableRules: []string{ruleHostPort}})
+				if tt.ok {
+					if len(res.Findings) > 0 {
+						t.Errorf("%s %s: findings %v, want none", key, tt.port, res.Findings)
+					}
+					continue
+				}
+				if len(res.Findings) != 1 || res.Findings[0].Category != CategoryRange || !strings.HasSuffix(res.Findings[0].Field, key) {
+					t.Errorf("%s %s: findings %v, want one range finding", key, tt.port, res.Findings)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQuantityExponent(t *testing.T) {
+	tests := []struct {
+		in       string
+		outRange bool
+	}{
+		{"1e100", false},
+		{"1e101", true},
+		{"1e-100", false},
+		{"1e-101", true},
+		{"1E100", false},
+		{"1E101", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			q, exact, err := parseQuantity(tt.in)
+			if tt.outRange {
+				if !errors.Is(err, errQuantityRange) {
+					t.Errorf("err = %v, want %v", err, errQuantityRange)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 1e-100 rounds up to one thousandth.
+			want, wantExact := big.NewInt(1), false
+			if !strings.Contains(tt.in, "-") {
+				want, wantExact = new(big.Int).Exp(big.NewInt(10), big.NewInt(maxQuantityExp+3), nil), true
+			}
+			if q.bigMilli().Cmp(want) != 0 || exact != wantExact {
+				t.Errorf("%s thousandths, exact %t; want %s, exact %t", q.bigMilli(), exact, want, wantExact)
+			}
+		})
+	}
+}
+
+func TestNameLengthLimits(t *testing.T) {
+	named := func(n int) string { return strings.Repeat("a", n) }
+	tests := []struct {
+		name  string
+		field string // suffix of the finding's field
+		src   func(name string) string
+		max   int
+	}{
+		{"metadata.name", "metadata.name", func(n string) string {
+			return strings.Replace(validPod, "name: web\nspec", "name: "+n+"\nspec", 1)
+		}, maxDNSSubdomain},
+		{"container name", "].name", func(n string) string {
+			return strings.Replace(validPod, "- name: web", "- name: "+n, 1)
+		}, maxDNSLabel},
+		{"port name", "].ports[0].name", func(n string) string {
+			return strings.Replace(validPod, "- containerPort: 80", "- containerPort: 80\n      name: "+n, 1)
+		}, maxPortName},
+		{"volume name", "spec.volumes[0].name", func(n string) string {
+			return validPod + "  volumes:\n  - name: " + n + "\n    emptyDir: {}\n"
+		}, maxDNSLabel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := mustValidate(t, "pod.yaml", tt.src(named(tt.max)), Options{}); len(res.Findings) > 0 {
+				t.Errorf("%d characters: findings %v, want none", tt.max, res.Findings)
+			}
+			res := mustValidate(t, "pod.yaml", tt.src(named(tt.max+1)), Options{})
+			want := fmt.Sprintf("is %d characters, maximum is %d", tt.max+1, tt.max)
+			if len(res.Findings) != 1 || !strings.HasSuffix(res.Findings[0].Field, tt.field) || res.Findings[0].Message != want {
+				t.Errorf("%d characters: findings %v, want %s %q", tt.max+1, res.Findings, tt.field, want)
+			}
+		})
+	}
+}
+
+func TestBoolSpellings(t *testing.T) {
+	fields := []struct {
+		field string // suffix of the finding's field
+		src   func(v string) string
+	}{
+		{"spec.hostNetwork", func(v string) string {
+			return strings.Replace(validPod, "spec:\n", "spec:\n  hostNetwork: "+v+"\n", 1)
+		}},
+		{"securityContext.privileged", func(v string) string {
+			return validPod + "    securityContext:\n      privileged: " + v + "\n"
+		}},
+		{"volumeMounts[0].readOnly", func(v string) string {
+			return validPod + "    volumeMounts:\n    - name: data\n      mountPath: /data\n      readOnly: " + v +
+				"\n  volumes:\n  - name: data\n    emptyDir: {}\n"
+		}},
+	}
+	tests := []struct {
+		value string
+		want  string // the message, or "" when the value is accepted
+	}{
+		{"true", ""},
+		{"false", ""},
+		{"True", "must be boolean (found 'True' — use true/false)"},
+		{"FALSE", "must be boolean (found 'FALSE' — use true/false)"},
+		{"yes", "must be boolean (found 'yes' — use true/false)"},
+		{"Yes", "must be boolean (found 'Yes' — use true/false)"},
+		{"no", "must be boolean (found 'no' — use true/false)"},
+		{"on", "must be boolean (found 'on' — use true/false)"},
+		{"off", "must be boolean (found 'off' — use true/false)"},
+		{"y", "must be boolean (found 'y' — use true/false)"},
+		{"1", "must be boolean (found '1' — use true/false)"},
+		{`"true"`, "must be boolean (found string 'true' — remove the quotes)"},
+		{"'false'", "must be boolean (found string 'false' — remove the quotes)"},
+		{`"yes"`, "must be boolean (found 'yes' — use true/false)"},
+		{"[true]", "must be boolean (found sequence)"},
+	}
+	for _, f := range fields {
+		for _, tt := range tests {
+			t.Run(f.field+"="+tt.value, func(t *testing.T) {
+				res := mustValidate(t, "pod.yaml", f.src(tt.value), Options{})
+				if tt.want == "" {
+					if len(res.Findings) > 0 {
+						t.Errorf("findings %v, want none", res.Findings)
+					}
+					return
+				}
+				if len(res.Findings) != 1 || !strings.HasSuffix(res.Findings[0].Field, f.field) || res.Findings[0].Message != tt.want {
+					t.Errorf("findings %v, want %s %q", res.Findings, f.field, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCPUFloats(t *testing.T) {
+	const precision = "has invalid format '%s': CPU is allocated in millicores, so at most three decimal places (1m) are allowed"
+	tests := []struct {
+		value string
+		want  string // the message, or "" when the value is accepted
+	}{
+		{"0.1", ""},
+		{".5", ""},
+		{"1.0", ""},
+		{"1e-1", ""},
+		{"0.001", ""},
+		{"0.0005", fmt.Sprintf(precision, "0.0005")},
+		{"1.0001", fmt.Sprintf(precision, "1.0001")},
+	}
+	for _, list := range []string{"limits", "requests"} {
+		for _, tt := range tests {
+			t.Run(list+"="+tt.value, func(t *testing.T) {
+				src := validPod + "    resources:\n      " + list + ":\n        cpu: " + tt.value + "\n"
+				res := mustValidate(t, "pod.yaml", src, Options{})
+				if tt.want == "" {
+					if len(res.Findings) > 0 {
+						t.Errorf("findings %v, want none", res.Findings)
+					}
+					return
+				}
+				if len(res.Findings) != 1 || !strings.HasSuffix(res.Findings[0].Field, "resources."+list+".cpu") || res.Findings[0].Message != tt.want {
+					t.Errorf("findings %v, want %q", res.Findings, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCPUFloatsCompareInMillicores(t *testing.T) {
+	tests := []struct {
+		request, limit string
+		exceeds        bool
+	}{
+		{"0.25", "250m", false},
+		{".5", "500m", false},
+		{"0.5", "250m", true},
+		{"1e-1", "0.1", false},
+		{"0.101", "1e-1", true},
+		{"100m", "0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.request+"/"+tt.limit, func(t *testing.T) {
+			src := validPod + "    resources:\n      limits:\n        cpu: " + tt.limit + "\n      requests:\n        cpu: " + tt.request + "\n"
+			res := mustValidate(t, "pod.yaml", src, Options{})
+			want := 0
+			if tt.exceeds {
+				want = 1
+			}
+			if len(res.Findings) != want || want == 1 && res.Findings[0].Category != CategoryCrossField {
+				t.Errorf("findings %v, want %d cross-field findings", res.Findings, want)
+			}
+		})
+	}
+}
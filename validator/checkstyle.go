@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type checkstyleLog struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// FileReporter is implemented by Reporters that list every input,
+// including the ones without findings. StartFile is called once per
+// input before its findings are reported.
+type FileReporter interface {
+	Reporter
+	StartFile(name string)
+}
+
+// NewCheckstyleReporter returns a Reporter writing checkstyle XML to w
+// when the run ends. Every input gets a <file> element, so inputs that
+// validated cleanly show up as checked.
+func NewCheckstyleReporter(w io.Writer) FileReporter { return &checkstyleReporter{w: w} }
+
+type checkstyleReporter struct {
+	w     io.Writer
+	files []checkstyleFile
+	index map[string]int
+}
+
+func (c *checkstyleReporter) StartFile(name string) { c.file(name) }
+
+func (c *checkstyleReporter) Report(e *ValidationError) {
+	f := c.file(e.File)
+	f.Errors = append(f.Errors, checkstyleError{
+		Line:     e.Line,
+		Column:   e.Column,
+		Severity: e.Severity.String(),
+		Message:  e.Error(),
+		Source:   reportID(e),
+	})
+}
+
+// file returns the element for name, adding it on first use.
+func (c *checkstyleReporter) file(name string) *checkstyleFile {
+	if c.index == nil {
+		c.index = map[string]int{}
+	}
+	i, ok := c.index[name]
+	if !ok {
+		i = len(c.files)
+		c.index[name] = i
+		c.files = append(c.files, checkstyleFile{Name: name})
+	}
+	return &c.files[i]
+}
+
+func (c *checkstyleReporter) Summary(Stats) error {
+	if _, err := io.WriteString(c.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(c.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(checkstyleLog{Version: "4.3", Files: c.files}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.w, "\n")
+	return err
+}
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+type codeClimateIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// NewCodeClimateReporter returns a Reporter writing the run to w as a
+// Code Climate issue array, the format of GitLab's Code Quality report,
+// when it ends. Errors are major issues and warnings minor ones. GitLab
+// compares reports by fingerprint, which hashes the path, the code and
+// the field, so a finding keeps it when lines move; paths should be
+// relative to the repository, as with --path-mode=relative.
+func NewCodeClimateReporter(w io.Writer) Reporter { return &codeClimateReporter{w: w} }
+
+type codeClimateReporter struct {
+	w        io.Writer
+	findings []findingJSON
+}
+
+func (c *codeClimateReporter) Report(e *ValidationError) { c.findings = append(c.findings, e.wire()) }
+
+func (c *codeClimateReporter) Summary(Stats) error {
+	issues := make([]codeClimateIssue, len(c.findings))
+	seen := make(map[string]int)
+	for i, e := range c.findings {
+		check := e.Code
+		if check == "" {
+			check = e.Rule
+		}
+		if check == "" {
+			check = e.Category.String()
+		}
+		desc := e.Message
+		if e.Field != "" {
+			desc = e.Field + " " + desc
+		}
+		is := codeClimateIssue{Description: desc, CheckName: check, Severity: "major"}
+		if e.Severity == SeverityWarning {
+			is.Severity = "minor"
+		}
+		// Findings that agree on path, code and field are told apart by
+		// their order, so that GitLab does not fold them into one.
+		base := codeClimateFingerprint(e.File, check, e.Field, 0)
+		is.Fingerprint = codeClimateFingerprint(e.File, check, e.Field, seen[base])
+		seen[base]++
+		is.Location.Path = e.File
+		is.Location.Lines.Begin = max(e.Line, 1)
+		issues[i] = is
+	}
+	enc := json.NewEncoder(c.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+// codeClimateFingerprint hashes the parts like fingerprint does; the
+// ordinal is left out when it is 0.
+func codeClimateFingerprint(path, check, field string, ordinal int) string {
+	parts := []string{path, check, field}
+	if ordinal > 0 {
+		parts = append(parts, strconv.Itoa(ordinal))
+	}
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(strconv.Itoa(len(part)) + ":" + part))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,13 @@
+package validator
+
+import "fmt"
+
+// ConfigError reports an invalid configuration value.
+type ConfigError struct {
+	Field string
+	Err   error
+}
+
+func (e *ConfigError) Error() string { return fmt.Sprintf("config: %s: %v", e.Field, e.Err) }
+
+func (e *ConfigError) Unwrap() error { return e.Err }
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaulter fills in the values the apiserver would default for one
+// kind. It only adds fields and reports how many it added.
+type Defaulter func(doc *yaml.Node) int
+
+// defaulters is the per-kind defaulting table used by SetDefaults.
+var defaulters = map[GroupVersionKind]Defaulter{
+	{Version: "v1", Kind: "Pod"}: defaultPod,
+}
+
+// SetDefaults adds the well-known defaults for the kind of doc, a
+// top-level mapping, without touching fields that are already set. It
+// returns the number of fields added; documents of kinds without a
+// defaulter are left unchanged.
+func SetDefaults(doc *yaml.Node) int {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	apiVersion, kind := getField(doc, "apiVersion"), getField(doc, "kind")
+	if apiVersion == nil || kind == nil {
+		return 0
+	}
+	var gvk GroupVersionKind
+	gvk.Group, gvk.Version = ParseGroupVersion(apiVersion.Value)
+	gvk.Kind = kind.Value
+	if d, ok := defaulters[gvk]; ok {
+		return d(doc)
+	}
+	return 0
+}
+
+func defaultPod(doc *yaml.Node) int {
+	return defaultPodSpec(getField(doc, "spec"))
+}
+
+// defaultPodSpec applies pod spec defaults. Kinds embedding a pod
+// template call it on the template's spec.
+func defaultPodSpec(spec *yaml.Node) int {
+	if spec == nil || spec.Kind != yaml.MappingNode {
+		return 0
+	}
+	n := 0
+	for _, d := range []struct{ key, value string }{
+		{"restartPolicy", "Always"},
+		{"dnsPolicy", "ClusterFirst"},
+		{"schedulerName", "default-scheduler"},
+	} {
+		if setIfAbsent(spec, d.key, newScalar("!!str", d.value)) {
+			n++
+		}
+	}
+	if setIfAbsent(spec, "terminationGracePeriodSeconds", newScalar("!!int", "30")) {
+		n++
+	}
+	for _, key := range []string{"initContainers", "containers"} {
+		for _, ctr := range mappingItems(spec, key) {
+			n += defaultContainer(ctr)
+		}
+	}
+	return n
+}
+
+func defaultContainer(ctr *yaml.Node) int {
+	n := 0
+	if img := getField(ctr, "image"); img != nil && img.Kind == yaml.ScalarNode {
+		if setIfAbsent(ctr, "imagePullPolicy", newScalar("!!str", defaultPullPolicy(img.Value))) {
+			n++
+		}
+	}
+	if setIfAbsent(ctr, "terminationMessagePath", newScalar("!!str", "/dev/termination-log")) {
+		n++
+	}
+	if setIfAbsent(ctr, "terminationMessagePolicy", newScalar("!!str", "File")) {
+		n++
+	}
+	for _, p := range mappingItems(ctr, "ports") {
+		if setIfAbsent(p, "protocol", newScalar("!!str", "TCP")) {
+			n++
+		}
+	}
+	return n
+}
+
+// defaultPullPolicy mirrors the apiserver: images tagged latest, or
+// not tagged at all, are pulled Always; anything else IfNotPresent.
+func defaultPullPolicy(image string) string {
+	if strings.Contains(image, "@") {
+		return "IfNotPresent"
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	if !ok || tag == "latest" {
+		return "Always"
+	}
+	return "IfNotPresent"
+}
@@ -0,0 +1,94 @@
+package validator
+
+import (
+	"math"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateDeployment is the KindValidator for apps/v1 Deployments.
+func validateDeployment(doc *yaml.Node, h Helpers, report ReportFunc) {
+	c := h.checker(report)
+	c.namespace, c.labels = documentScope(doc)
+	c.metadata(doc, "metadata", objectMetadata)
+	spec := getField(doc, "spec")
+	if isNull(spec) {
+		report(required("spec", doc))
+		return
+	}
+	if spec.Kind != yaml.MappingNode {
+		report(typeMismatch("spec", spec, "object"))
+		return
+	}
+	if n := getField(spec, "replicas"); !isNull(n) {
+		c.requireIntRange(n, "spec.replicas", 0, math.MaxInt32)
+	}
+	matchLabels := c.selector(spec, "spec.selector")
+	tmpl := getField(spec, "template")
+	if isNull(tmpl) {
+		report(required("spec.template", spec))
+		return
+	}
+	if tmpl.Kind != yaml.MappingNode {
+		report(typeMismatch("spec.template", tmpl, "object"))
+		return
+	}
+	c.metadata(tmpl, "spec.template.metadata", templateMetadata)
+	c.selectorMatches(matchLabels, getField(getField(tmpl, "metadata"), "labels"))
+	c.requiredFields(tmpl, "spec.template")
+	podSpec := getField(tmpl, "spec")
+	if isNull(podSpec) {
+		report(required("spec.template.spec", tmpl))
+		return
+	}
+	if podSpec.Kind != yaml.MappingNode {
+		report(typeMismatch("spec.template.spec", podSpec, "object"))
+		return
+	}
+	c.podSpec(podSpec, "spec.template.spec")
+}
+
+// selector checks the label selector at key selector of spec, found at
+// path, and returns its matchLabels when they are usable.
+func (c *checker) selector(spec *yaml.Node, path string) *yaml.Node {
+	sel := getField(spec, "selector")
+	if isNull(sel) {
+		c.report(required(path, spec))
+		return nil
+	}
+	if sel.Kind != yaml.MappingNode {
+		c.report(typeMismatch(path, sel, "object"))
+		return nil
+	}
+	c.optionalSequence(sel, "matchExpressions", path)
+	ml := c.optionalMapping(sel, "matchLabels", path)
+	if ml == nil {
+		if isNull(getField(sel, "matchExpressions")) {
+			c.report(required(joinKey(path, "matchLabels"), sel))
+		}
+		return nil
+	}
+	c.stringMap(ml, joinKey(path, "matchLabels"))
+	return ml
+}
+
+// selectorMatches checks that the pod template labels satisfy every
+// matchLabels entry. A wrong value is reported at the template label,
+// a missing label at the selector entry.
+func (c *checker) selectorMatches(matchLabels, labels *yaml.Node) {
+	if matchLabels == nil || labels == nil || labels.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(matchLabels.Content); i += 2 {
+		k, want := matchLabels.Content[i], matchLabels.Content[i+1]
+		got := getField(labels, k.Value)
+		switch {
+		case got == nil:
+			c.report(crossField(joinKey("spec.selector.matchLabels", k.Value), want,
+				"has no matching label in spec.template.metadata.labels (line %d)", labels.Line))
+		case got.Value != want.Value:
+			c.report(crossField(joinKey("spec.template.metadata.labels", k.Value), got,
+				"is '%s' but spec.selector.matchLabels requires '%s' (line %d)", got.Value, want.Value, want.Line))
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KubeVersion is a Kubernetes minor release such as 1.29. The zero
+// value means no target version.
+type KubeVersion struct {
+	Major, Minor int
+}
+
+// ParseKubeVersion parses "1.29" or "v1.29"; a patch level is ignored.
+func ParseKubeVersion(s string) (KubeVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q: want MAJOR.MINOR", s)
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || major < 1 || minor < 0 {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q: want MAJOR.MINOR", s)
+	}
+	return KubeVersion{major, minor}, nil
+}
+
+// IsZero reports whether v is unset.
+func (v KubeVersion) IsZero() bool { return v == KubeVersion{} }
+
+// AtLeast reports whether v is o or later.
+func (v KubeVersion) AtLeast(o KubeVersion) bool {
+	return v.Major > o.Major || (v.Major == o.Major && v.Minor >= o.Minor)
+}
+
+func (v KubeVersion) String() string { return fmt.Sprintf("%d.%d", v.Major, v.Minor) }
+
+// Rule IDs of the findings about apiVersions and annotations that are
+// deprecated or removed in Options.KubernetesVersion.
+const (
+	ruleDeprecatedAPI        = "deprecated-api"
+	ruleDeprecatedAnnotation = "deprecated-annotation"
+)
+
+// apiDeprecation records when an apiVersion of a kind was deprecated
+// and removed. A zero removed version means it is still served.
+type apiDeprecation struct {
+	gvk         GroupVersionKind
+	deprecated  KubeVersion
+	removed     KubeVersion
+	replacement string
+}
+
+var apiDeprecations = []apiDeprecation{
+	{GroupVersionKind{"batch", "v1beta1", "CronJob"}, KubeVersion{1, 21}, KubeVersion{1, 25}, "batch/v1"},
+	{GroupVersionKind{"policy", "v1beta1", "PodDisruptionBudget"}, KubeVersion{1, 21}, KubeVersion{1, 25}, "policy/v1"},
+	{GroupVersionKind{"policy", "v1beta1", "PodSecurityPolicy"}, KubeVersion{1, 21}, KubeVersion{1, 25}, "Pod Security Admission"},
+	{GroupVersionKind{"discovery.k8s.io", "v1beta1", "EndpointSlice"}, KubeVersion{1, 21}, KubeVersion{1, 25}, "discovery.k8s.io/v1"},
+	{GroupVersionKind{"autoscaling", "v2beta1", "HorizontalPodAutoscaler"}, KubeVersion{1, 22}, KubeVersion{1, 25}, "autoscaling/v2"},
+	{GroupVersionKind{"autoscaling", "v2beta2", "HorizontalPodAutoscaler"}, KubeVersion{1, 23}, KubeVersion{1, 26}, "autoscaling/v2"},
+	{GroupVersionKind{"networking.k8s.io", "v1beta1", "Ingress"}, KubeVersion{1, 19}, KubeVersion{1, 22}, "networking.k8s.io/v1"},
+	{GroupVersionKind{"extensions", "v1beta1", "Ingress"}, KubeVersion{1, 14}, KubeVersion{1, 22}, "networking.k8s.io/v1"},
+	{GroupVersionKind{"apps", "v1beta1", "Deployment"}, KubeVersion{1, 9}, KubeVersion{1, 16}, "apps/v1"},
+	{GroupVersionKind{"apps", "v1beta2", "Deployment"}, KubeVersion{1, 9}, KubeVersion{1, 16}, "apps/v1"},
+	{GroupVersionKind{"extensions", "v1beta1", "Deployment"}, KubeVersion{1, 9}, KubeVersion{1, 16}, "apps/v1"},
+	{GroupVersionKind{"flowcontrol.apiserver.k8s.io", "v1beta2", "FlowSchema"}, KubeVersion{1, 26}, KubeVersion{1, 29}, "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// annotationDeprecation records an annotation superseded by a field.
+// Prefix entries match every annotation key starting with key.
+type annotationDeprecation struct {
+	key         string
+	prefix      bool
+	deprecated  KubeVersion
+	removed     KubeVersion
+	replacement string
+}
+
+var annotationDeprecations = []annotationDeprecation{
+	{"seccomp.security.alpha.kubernetes.io/pod", false, KubeVersion{1, 19}, KubeVersion{1, 27}, "spec.securityContext.seccompProfile"},
+	{"container.seccomp.security.alpha.kubernetes.io/", true, KubeVersion{1, 19}, KubeVersion{1, 27}, "the container's securityContext.seccompProfile"},
+	{"container.apparmor.security.beta.kubernetes.io/", true, KubeVersion{1, 30}, KubeVersion{}, "the container's securityContext.appArmorProfile"},
+	{"scheduler.alpha.kubernetes.io/critical-pod", false, KubeVersion{1, 13}, KubeVersion{1, 16}, "spec.priorityClassName"},
+}
+
+// deprecationFinding builds the finding for something deprecated at
+// deprecated and removed at removed, judged against target. qualifier
+// is placed between the field and the verdict. It returns nil when
+// target predates the deprecation.
+func deprecationFinding(target, deprecated, removed KubeVersion, field string, n *yaml.Node, qualifier text, replacement string) *ValidationError {
+	switch {
+	case !removed.IsZero() && target.AtLeast(removed):
+		return newError(CategoryEnum, field, n, "%swas removed in Kubernetes %s (targeting %s); use %s",
+			qualifier, removed, target, replacement)
+	case target.AtLeast(deprecated):
+		w := newError(CategoryEnum, field, n, "%sis deprecated since Kubernetes %s; use %s", qualifier, deprecated, replacement)
+		w.Severity = SeverityWarning
+		return w
+	}
+	return nil
+}
+
+// apiDeprecated checks the apiVersion of a document against the table
+// and reports whether it is removed in the target version, in which
+// case validating it further is pointless.
+func (c *checker) apiDeprecated(apiVersion *yaml.Node, gvk GroupVersionKind) (removed bool) {
+	target := c.opts.KubernetesVersion
+	if target.IsZero() || c.opts.ruleOff(ruleDeprecatedAPI) {
+		return false
+	}
+	for _, d := range apiDeprecations {
+		if d.gvk != gvk {
+			continue
+		}
+		e := deprecationFinding(target, d.deprecated, d.removed, "apiVersion", apiVersion,
+			textf("'%s' for kind %s ", gvk.APIVersion(), gvk.Kind), d.replacement)
+		if e != nil {
+			e.Rule = ruleDeprecatedAPI
+			c.report(e)
+			return e.Severity == SeverityError
+		}
+	}
+	return false
+}
+
+// annotationsDeprecated checks metadata annotations of doc against the
+// table.
+func (c *checker) annotationsDeprecated(meta *yaml.Node, path string) {
+	target := c.opts.KubernetesVersion
+	ann := getField(meta, "annotations")
+	if target.IsZero() || ann == nil || ann.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(ann.Content); i += 2 {
+		k := ann.Content[i]
+		for _, d := range annotationDeprecations {
+			if k.Value != d.key && !(d.prefix && strings.HasPrefix(k.Value, d.key)) {
+				continue
+			}
+			field := joinKey(joinKey(path, "annotations"), k.Value)
+			if e := deprecationFinding(target, d.deprecated, d.removed, field, k, text{}, d.replacement); e != nil {
+				e.Rule = ruleDeprecatedAnnotation
+				c.report(e)
+			}
+			break
+		}
+	}
+}
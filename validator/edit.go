@@ -0,0 +1,49 @@
+package validator
+
+import "gopkg.in/yaml.v3"
+
+// This file holds the node-editing helpers used by transformations that
+// rewrite manifests, as opposed to checks that only read them.
+
+// newScalar returns a plain scalar node with the given tag.
+func newScalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+// setIfAbsent appends key: value to mapping m unless key is already
+// present, and reports whether it did. Fields the user set, even to an
+// explicit null, are never touched.
+func setIfAbsent(m *yaml.Node, key string, value *yaml.Node) bool {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return false
+		}
+	}
+	k := newScalar("!!str", key)
+	// A comment trailing the previous last entry belongs to the end of
+	// the mapping, so keep it there.
+	if n := len(m.Content); n >= 2 {
+		last := m.Content[n-2]
+		k.FootComment, last.FootComment = last.FootComment, ""
+	}
+	m.Content = append(m.Content, k, value)
+	return true
+}
+
+// mappingItems returns the mapping items of sequence field key of m.
+func mappingItems(m *yaml.Node, key string) []*yaml.Node {
+	seq := getField(m, key)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var items []*yaml.Node
+	for _, it := range seq.Content {
+		if it.Kind == yaml.MappingNode {
+			items = append(items, it)
+		}
+	}
+	return items
+}
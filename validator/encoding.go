@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrEncoding is wrapped by errors about inputs that are not valid
+// UTF-8 and cannot be transcoded to it.
+var ErrEncoding = errors.New("unsupported encoding")
+
+// EncodingError reports input that is not, and cannot be turned into,
+// valid UTF-8. Line is zero when the problem is not tied to a line.
+type EncodingError struct {
+	Name    string
+	Line    int
+	Message string
+}
+
+func (e *EncodingError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Name, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Name, e.Message)
+}
+
+func (e *EncodingError) Unwrap() error { return ErrEncoding }
+
+// byte order marks, longest first so UTF-32LE is not taken for UTF-16LE.
+var boms = []struct {
+	name  string
+	bom   []byte
+	width int
+	order binary.ByteOrder
+}{
+	{"UTF-32BE", []byte{0x00, 0x00, 0xFE, 0xFF}, 4, binary.BigEndian},
+	{"UTF-32LE", []byte{0xFF, 0xFE, 0x00, 0x00}, 4, binary.LittleEndian},
+	{"UTF-8", []byte{0xEF, 0xBB, 0xBF}, 1, nil},
+	{"UTF-16BE", []byte{0xFE, 0xFF}, 2, binary.BigEndian},
+	{"UTF-16LE", []byte{0xFF, 0xFE}, 2, binary.LittleEndian},
+}
+
+// toUTF8 strips a byte order mark, transcodes UTF-16 and UTF-32 input
+// to UTF-8 and verifies that the result is valid UTF-8.
+func toUTF8(name string, data []byte) ([]byte, error) {
+	for _, b := range boms {
+		if !bytes.HasPrefix(data, b.bom) {
+			continue
+		}
+		body := data[len(b.bom):]
+		switch b.width {
+		case 1:
+			data = body
+		case 2:
+			if len(body)%2 != 0 {
+				return nil, &EncodingError{Name: name, Message: fmt.Sprintf("file appears to be %s encoded but has an odd length; please save as UTF-8", b.name)}
+			}
+			units := make([]uint16, len(body)/2)
+			for i := range units {
+				units[i] = b.order.Uint16(body[2*i:])
+			}
+			data = []byte(string(utf16.Decode(units)))
+		case 4:
+			if len(body)%4 != 0 {
+				return nil, &EncodingError{Name: name, Message: fmt.Sprintf("file appears to be %s encoded but has a truncated code point; please save as UTF-8", b.name)}
+			}
+			var buf bytes.Buffer
+			for i := 0; i < len(body); i += 4 {
+				r := rune(b.order.Uint32(body[i:]))
+				if !utf8.ValidRune(r) {
+					return nil, &EncodingError{Name: name, Message: fmt.Sprintf("invalid %s code point at byte %d; please save as UTF-8", b.name, len(b.bom)+i)}
+				}
+				buf.WriteRune(r)
+			}
+			data = buf.Bytes()
+		}
+		break
+	}
+	// Without a BOM, ASCII text in UTF-16 shows up as alternating NULs.
+	if len(data) >= 2 && (data[0] == 0) != (data[1] == 0) {
+		return nil, &EncodingError{Name: name, Message: "file appears to be UTF-16 encoded; please save as UTF-8"}
+	}
+	if off := invalidUTF8Offset(data); off >= 0 {
+		line := 1 + bytes.Count(data[:off], []byte{'\n'})
+		return nil, &EncodingError{Name: name, Line: line, Message: fmt.Sprintf("invalid UTF-8 sequence at byte offset %d; please save as UTF-8", off)}
+	}
+	return data, nil
+}
+
+// invalidUTF8Offset returns the offset of the first byte that does not
+// start a valid UTF-8 sequence, or -1.
+func invalidUTF8Offset(data []byte) int {
+	if utf8.Valid(data) {
+		return -1
+	}
+	for off := 0; off < len(data); {
+		r, size := utf8.DecodeRune(data[off:])
+		if r == utf8.RuneError && size <= 1 {
+			return off
+		}
+		off += size
+	}
+	return -1
+}
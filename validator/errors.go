@@ -0,0 +1,331 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sentinel errors returned by Validate or wrapped by a ValidationError.
+// Use errors.Is to test for them.
+var (
+	// ErrNotYAML means the input could not be parsed as YAML.
+	ErrNotYAML = errors.New("cannot unmarshal file content")
+	// ErrEmptyDocument means the input contains no document.
+	ErrEmptyDocument = errors.New("document is empty")
+	// ErrIO means the input could not be read.
+	ErrIO = errors.New("cannot read file")
+	// ErrTooLarge means the input exceeds Options.MaxFileSize; the
+	// concrete error is a *SizeError.
+	ErrTooLarge = errors.New("input too large")
+	// ErrUnsupportedKind is wrapped by findings for documents whose kind
+	// the validator does not know.
+	ErrUnsupportedKind = errors.New("unsupported kind")
+)
+
+// Category classifies a ValidationError by the kind of check that fired.
+type Category int
+
+const (
+	// CategoryRequired is reported when a mandatory field is absent.
+	CategoryRequired Category = iota + 1
+	// CategoryType is reported when a field has the wrong YAML type.
+	CategoryType
+	// CategoryFormat is reported when a value does not match its syntax.
+	CategoryFormat
+	// CategoryRange is reported when a number is outside its bounds.
+	CategoryRange
+	// CategoryEnum is reported when a value is not one of the allowed ones.
+	CategoryEnum
+	// CategoryCrossField is reported when fields are inconsistent with
+	// each other.
+	CategoryCrossField
+	// CategoryIO is reported when an input could not be read at all.
+	CategoryIO
+	// CategoryParse is reported when an input is not a YAML document.
+	CategoryParse
+)
+
+var categoryNames = map[Category]string{
+	CategoryRequired:   "required",
+	CategoryType:       "type",
+	CategoryFormat:     "format",
+	CategoryRange:      "range",
+	CategoryEnum:       "enum",
+	CategoryCrossField: "cross-field",
+	CategoryIO:         "io",
+	CategoryParse:      "parse",
+}
+
+func (c Category) String() string {
+	if s, ok := categoryNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Category(%d)", int(c))
+}
+
+// ValidationError is a single finding about a document.
+type ValidationError struct {
+	// File names the input the finding belongs to.
+	File string
+	// Document is the 1-based index of the document of File the finding
+	// is about, or 0 when it is about the input as a whole.
+	Document int
+	// Resource names the document as "Kind/name" (see Document.Label)
+	// when File holds several, so that findings in a generated stream
+	// say which object they are about. It is taken from the document
+	// before validation, even when kind or name fail their own checks.
+	Resource string
+	// Field is the dotted path of the offending field, e.g. "spec.os".
+	Field string
+	// Path is Field in JSONPath notation with the index of every
+	// sequence item, e.g. "$.spec.containers[2].image" where Field names
+	// the container. It is empty for findings about no document.
+	Path string
+	// Line and Column locate the finding; zero when unknown.
+	Line, Column int
+	// KeyLine and KeyColumn locate the key of the mapping entry the
+	// finding is about, and ValueLine and ValueColumn its value, so that
+	// editors can highlight the whole entry. They are zero for findings
+	// about a missing field or about no mapping entry. Line and Column
+	// point at whichever of the two the finding is best spotted by.
+	KeyLine, KeyColumn, ValueLine, ValueColumn int
+	// Message describes the problem without the field prefix.
+	Message  string
+	Category Category
+	Severity Severity
+	// Rule is the identifier of the check that produced the finding.
+	Rule string
+	// Code is the stable code of the check, such as "PV021"; every
+	// finding returned by the package has one. See ruleCodes.
+	Code string
+	// Err optionally links the finding to one of the sentinel errors.
+	Err error
+	// Occurrences counts the findings this one stands for when the same
+	// problem was reported more than once at one position, as happens
+	// for a node reached through several aliases. Zero means once.
+	Occurrences int
+	// Fingerprint identifies the finding across runs. It does not
+	// depend on Line or Column, so it survives edits elsewhere in the
+	// file, and it is unique within one Result.
+	Fingerprint string
+
+	node     *yaml.Node // the offending node, when there is one
+	embedded string     // the ConfigMap data the finding sits in, as "data/KEY" (see Options.Nested)
+	// format and args are what Message was formatted from, for Localize.
+	format string
+	args   []any
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + " " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+func newError(cat Category, field string, node *yaml.Node, format string, args ...any) *ValidationError {
+	e := &ValidationError{Field: field, Message: fmt.Sprintf(format, args...), Category: cat, format: format, args: args}
+	if node != nil {
+		e.Line, e.Column = node.Line, node.Column
+		e.node = node
+	}
+	return e
+}
+
+// positionAtKeys records the key and value positions of findings about
+// a mapping value, and moves format findings to the value's key. A bad
+// value is easiest to spot by the key it sits under, and the key column
+// tells flow-style entries such as {cpu: two} apart. Type and range
+// findings keep the value's position.
+func positionAtKeys(doc *yaml.Node, findings []*ValidationError) {
+	keys := make(map[*yaml.Node]*yaml.Node)
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				keys[n.Content[i+1]] = n.Content[i]
+			}
+		}
+		if n.Kind != yaml.AliasNode {
+			for _, c := range n.Content {
+				walk(c)
+			}
+		}
+	}
+	walk(doc)
+	for _, e := range findings {
+		k := keys[e.node]
+		if k == nil || e.Category == CategoryRequired {
+			continue
+		}
+		e.KeyLine, e.KeyColumn = k.Line, k.Column
+		e.ValueLine, e.ValueColumn = e.node.Line, e.node.Column
+		if e.Category == CategoryFormat {
+			e.Line, e.Column = k.Line, k.Column
+		}
+	}
+}
+
+// required reports field as missing from parent, the mapping it belongs
+// in, which also gives the finding its position. The missing key is the
+// last segment of field.
+func required(field string, parent *yaml.Node) *ValidationError {
+	key := field[strings.LastIndex(field, ".")+1:]
+	if i := strings.Index(key, "["); i >= 0 {
+		key = key[:i]
+	}
+	return requiredKey(field, key, parent)
+}
+
+// maxSiblingKeys is the number of keys a required finding lists from
+// the mapping the field is missing from.
+const maxSiblingKeys = 6
+
+// requiredKey is required for findings whose field is not the missing
+// key itself, such as metadata.labels of a template without metadata.
+// The message lists the keys parent does have, which shows a typo or
+// wrong casing at a glance. When one of them looks like a misspelling
+// of key, the finding points at it and suggests the right name.
+func requiredKey(field, key string, parent *yaml.Node) *ValidationError {
+	found := siblingKeys(parent)
+	k := misspelledKey(parent, key)
+	switch {
+	case k != nil:
+		e := newError(CategoryRequired, field, parent, "is required (did you mean '%s' instead of '%s'? found: %s)", key, k.Value, found)
+		e.Line, e.Column = k.Line, k.Column
+		return e
+	case found != "":
+		return newError(CategoryRequired, field, parent, "is required (found: %s)", found)
+	}
+	return newError(CategoryRequired, field, parent, "is required")
+}
+
+// siblingKeys lists the scalar keys of mapping m in source order, up to
+// maxSiblingKeys and then an ellipsis, or returns "" when it has none.
+func siblingKeys(m *yaml.Node) string {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return ""
+	}
+	var keys []string
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if k := m.Content[i]; k.Kind == yaml.ScalarNode && k.Tag != "!!merge" {
+			keys = append(keys, k.Value)
+		}
+	}
+	if len(keys) > maxSiblingKeys {
+		keys = append(keys[:maxSiblingKeys], "…")
+	}
+	return strings.Join(keys, ", ")
+}
+
+// misspelledKey returns the key of mapping m closest to key, compared
+// without regard to case, or nil when none is within a third of its
+// length (and at least one edit) of it.
+func misspelledKey(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode || key == "" {
+		return nil
+	}
+	var best *yaml.Node
+	bestDist := max(len(key)/3, 1) + 1
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k := m.Content[i]
+		if k.Kind != yaml.ScalarNode || k.Value == key {
+			continue
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(k.Value)); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func typeMismatch(field string, node *yaml.Node, want string) *ValidationError {
+	return newError(CategoryType, field, node, "must be %s (found %s)", want, describeNode(node))
+}
+
+func invalidFormat(field string, node *yaml.Node) *ValidationError {
+	return newError(CategoryFormat, field, node, "has invalid format '%s'", node.Value)
+}
+
+func outOfRange(field string, node *yaml.Node) *ValidationError {
+	return newError(CategoryRange, field, node, "value out of range '%s'", node.Value)
+}
+
+func unsupportedValue(field string, node *yaml.Node) *ValidationError {
+	return newError(CategoryEnum, field, node, "has unsupported value '%s'", node.Value)
+}
+
+func crossField(field string, node *yaml.Node, format string, args ...any) *ValidationError {
+	return newError(CategoryCrossField, field, node, format, args...)
+}
+
+// IOResult returns a Result carrying a single I/O finding for an input
+// that could not be read, so batch callers can report it alongside
+// validation findings instead of aborting.
+func IOResult(name string, err error) *Result {
+	e := &ValidationError{File: name, Message: err.Error(), Category: CategoryIO, Err: err}
+	var pe *fs.PathError
+	var ee *EncodingError
+	var se *SizeError
+	switch {
+	case errors.As(err, &pe):
+		e.Message = ErrIO.Error() + ": " + pe.Err.Error()
+	case errors.As(err, &ee):
+		e.Line, e.Message = ee.Line, ee.Message
+	case errors.As(err, &se):
+		e.Message = "input exceeds the maximum size of " + FormatSize(se.Limit)
+	default:
+		e.Message = strings.TrimSpace(strings.TrimPrefix(e.Message, name+":"))
+	}
+	res := &Result{File: name, Findings: []*ValidationError{e}}
+	setCodes(res.Findings)
+	res.setFingerprints(nil)
+	return res
+}
+
+// yamlErrorLine matches the position yaml.v3 and UnknownAliasError put
+// in their messages.
+var yamlErrorLine = regexp.MustCompile(`(?:yaml: )?line (\d+): `)
+
+// ParseResult returns a Result carrying the parse findings for an input
+// rejected with ErrNotYAML or ErrEmptyDocument, so that it is reported
+// like any other finding. The line the parser names becomes the
+// finding's line and is dropped from the message; a *yaml.TypeError
+// yields one finding per error it holds.
+func ParseResult(name string, err error) *Result {
+	cause := ErrNotYAML
+	if errors.Is(err, ErrEmptyDocument) {
+		cause = ErrEmptyDocument
+	}
+	msg := strings.TrimSpace(strings.TrimPrefix(err.Error(), name+":"))
+	msgs := []string{msg}
+	var te *yaml.TypeError
+	if errors.As(err, &te) && len(te.Errors) > 0 {
+		// Keep what wraps the TypeError, such as ErrNotYAML's text.
+		prefix := strings.TrimSuffix(msg, te.Error())
+		msgs = msgs[:0]
+		for _, m := range te.Errors {
+			msgs = append(msgs, prefix+m)
+		}
+	}
+	res := &Result{File: name}
+	for _, m := range msgs {
+		e := &ValidationError{File: name, Category: CategoryParse, Err: cause, Message: m}
+		if loc := yamlErrorLine.FindStringSubmatchIndex(m); loc != nil {
+			e.Line, _ = strconv.Atoi(m[loc[2]:loc[3]])
+			e.Message = m[:loc[0]] + m[loc[1]:]
+		}
+		res.Findings = append(res.Findings, e)
+	}
+	setCodes(res.Findings)
+	res.setFingerprints(nil)
+	return res
+}
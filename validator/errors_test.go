@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		opts Options
+		want error
+	}{
+		{"not YAML", "key: [unclosed\n", Options{}, ErrNotYAML},
+		{"unknown alias", "a: *missing\n", Options{}, ErrNotYAML},
+		{"empty", "", Options{}, ErrEmptyDocument},
+		{"comments only", "# nothing\n---\n", Options{}, ErrEmptyDocument},
+		{"too large", validPod, Options{MaxFileSize: 16}, ErrTooLarge},
+		{"invalid UTF-8", "a: \xff\n", Options{}, ErrEncoding},
+		{"truncated gzip", "\x1f\x8b\x08\x00", Options{}, ErrIO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ValidateBytes(context.Background(), "in.yaml", []byte(tt.data), tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want one wrapping %v", err, tt.want)
+			}
+			if res != nil {
+				t.Errorf("got a Result along with error %v", err)
+			}
+		})
+	}
+}
+
+func TestSizeError(t *testing.T) {
+	// The compressed input is within the limit; only its content is not.
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte("#" + strings.Repeat("x", 10000) + "\n" + validPod))
+	zw.Close()
+	for name, data := range map[string][]byte{"plain": []byte(validPod), "decompressed": compressed.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ValidateBytes(context.Background(), "in.yaml", data, Options{MaxFileSize: 100})
+			var se *SizeError
+			if !errors.As(err, &se) {
+				t.Fatalf("err = %v, want a *SizeError", err)
+			}
+			if se.Name != "in.yaml" || se.Limit != 100 {
+				t.Errorf("SizeError = %+v, want Name in.yaml and Limit 100", se)
+			}
+		})
+	}
+}
+
+func TestEncodingError(t *testing.T) {
+	_, err := ValidateBytes(context.Background(), "in.yaml", []byte("a: 1\nb: \xff\n"), Options{})
+	var ee *EncodingError
+	if !errors.As(err, &ee) {
+		t.Fatalf("err = %v, want an *EncodingError", err)
+	}
+	if ee.Name != "in.yaml" || ee.Line != 2 {
+		t.Errorf("EncodingError = %+v, want Name in.yaml and Line 2", ee)
+	}
+}
+
+func TestValidateFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := ValidateFile(context.Background(), filepath.Join(dir, "missing.yaml"), Options{})
+	var pe *fs.PathError
+	if !errors.Is(err, ErrIO) || !errors.As(err, &pe) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: err = %v, want ErrIO wrapping a not-exist *fs.PathError", err)
+	}
+
+	big := filepath.Join(dir, "big.yaml")
+	if err := os.WriteFile(big, []byte(validPod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ValidateFile(context.Background(), big, Options{MaxFileSize: 16})
+	var se *SizeError
+	if !errors.Is(err, ErrTooLarge) || !errors.As(err, &se) {
+		t.Errorf("large file: err = %v, want a *SizeError", err)
+	}
+
+	_, err = ValidateFile(context.Background(), dir, Options{})
+	if !errors.Is(err, ErrIO) {
+		t.Errorf("directory: err = %v, want ErrIO", err)
+	}
+}
+
+func TestUnsupportedKindFinding(t *testing.T) {
+	res := mustValidate(t, "in.yaml", "apiVersion: v1\nkind: Gadget\nmetadata:\n  name: g\n", Options{})
+	var found bool
+	for _, e := range res.Findings {
+		if errors.Is(e, ErrUnsupportedKind) {
+			found = true
+			var ve *ValidationError
+			if !errors.As(error(e), &ve) || ve.Field != "kind" {
+				t.Errorf("finding %v: want a *ValidationError about kind", e)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("findings %v: none wraps ErrUnsupportedKind", res.Findings)
+	}
+}
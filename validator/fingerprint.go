@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fingerprint computes e's Fingerprint from the rule (or, for findings
+// without one, the category), the file, the resource, the field and the
+// offending value. Positions are left out so that a finding keeps its
+// fingerprint when lines are added or removed above it; ordinal tells
+// apart findings that agree on everything else.
+func fingerprint(e *ValidationError, resource string, ordinal int) string {
+	rule := e.Rule
+	if rule == "" {
+		rule = e.Category.String()
+	}
+	h := sha256.New()
+	for _, part := range []string{rule, e.File, resource, e.Field, offendingValue(e), strconv.Itoa(ordinal)} {
+		// Length-prefix each part so that no two tuples hash alike.
+		h.Write([]byte(strconv.Itoa(len(part)) + ":" + part))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// setFingerprints fills in the fingerprints of r's findings, each for
+// the resource its document describes, identified in resources by
+// document index as e.g. "Pod/web". Findings must already be sorted, as
+// ordinals count up in position order.
+func (r *Result) setFingerprints(resources map[int]string) {
+	seen := make(map[string]int)
+	for _, e := range r.Findings {
+		resource := resources[e.Document]
+		base := fingerprint(e, resource, 0)
+		e.Fingerprint = fingerprint(e, resource, seen[base])
+		seen[base]++
+	}
+}
+
+// resourceID names the resource doc describes as "Kind/name", or
+// "Kind/namespace/name" outside the default namespace.
+func resourceID(doc *yaml.Node) string {
+	kind := scalarValue(getField(doc, "kind"))
+	name := scalarValue(getField(getField(doc, "metadata"), "name"))
+	namespace, _ := documentScope(doc)
+	if namespace != "default" {
+		name = namespace + "/" + name
+	}
+	return kind + "/" + name
+}
+
+// offendingValue is the scalar a finding is about, or "" when it is
+// about a missing field or a whole mapping or sequence.
+func offendingValue(e *ValidationError) string {
+	if e.Category == CategoryRequired {
+		return ""
+	}
+	return scalarValue(e.node)
+}
+
+func scalarValue(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
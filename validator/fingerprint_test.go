@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+// identicalFindingsPod has two containers that fail the same checks
+// with the same values, so only the ordinal tells their findings apart.
+const identicalFindingsPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: a
+    image: nginx
+    ports:
+    - containerPort: 0
+    - containerPort: 0
+  - name: b
+    image: nginx
+    ports:
+    - containerPort: 0
+`
+
+func TestFingerprintIgnoresLineShifts(t *testing.T) {
+	before := mustValidate(t, "pod.yaml", identicalFindingsPod, Options{})
+	shifted := "# Owned by the web team.\n# Reviewed quarterly.\n\n" +
+		strings.Replace(identicalFindingsPod, "spec:\n", "spec:\n  # the containers\n", 1)
+	after := mustValidate(t, "pod.yaml", shifted, Options{})
+	if len(before.Findings) < 5 || len(after.Findings) != len(before.Findings) {
+		t.Fatalf("%d findings before, %d after", len(before.Findings), len(after.Findings))
+	}
+	seen := map[string]bool{}
+	for i, e := range before.Findings {
+		a := after.Findings[i]
+		if a.Line == e.Line {
+			t.Errorf("%s: line %d did not move", e.Field, e.Line)
+		}
+		if e.Fingerprint == "" || a.Fingerprint != e.Fingerprint {
+			t.Errorf("%s: fingerprint %q after the shift, was %q", e.Field, a.Fingerprint, e.Fingerprint)
+		}
+		if seen[e.Fingerprint] {
+			t.Errorf("%s: fingerprint %s is not unique in the Result", e.Field, e.Fingerprint)
+		}
+		seen[e.Fingerprint] = true
+	}
+}
+
+func TestFingerprintDependsOnValue(t *testing.T) {
+	a := mustValidate(t, "pod.yaml", strings.Replace(validPod, "containerPort: 80", "containerPort: 0", 1), Options{})
+	b := mustValidate(t, "pod.yaml", strings.Replace(validPod, "containerPort: 80", "containerPort: 70000", 1), Options{})
+	if len(a.Findings) != 1 || len(b.Findings) != 1 {
+		t.Fatalf("findings %v and %v, want one each", a.Findings, b.Findings)
+	}
+	if a.Findings[0].Fingerprint == b.Findings[0].Fingerprint {
+		t.Errorf("different values share fingerprint %s", a.Findings[0].Fingerprint)
+	}
+}
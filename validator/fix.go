@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"cmp"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fix records one change made by ApplyFixes.
+type Fix struct {
+	// Rule is the rule whose findings the change resolves.
+	Rule  string
+	Field string
+	Line  int
+}
+
+// ApplyFixes rewrites doc, a document or its top-level mapping, so
+// that the findings of fixable rules go away, and returns the changes
+// it made. Comments and the rest of the document are left alone. For
+// now the only fixable rule is image-lock: images whose repository is
+// in Options.ImageLock get the locked digest pinned, replacing any
+// other digest.
+func ApplyFixes(doc *yaml.Node, opts Options) []Fix {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	lock := opts.ImageLock
+	if lock == nil {
+		return nil
+	}
+	kind := getField(doc, "kind")
+	if kind == nil {
+		return nil
+	}
+	keys, ok := podTemplatePaths[kind.Value]
+	if !ok {
+		return nil
+	}
+	spec, path := doc, ""
+	for _, k := range append(keys, "spec") {
+		spec, path = getField(spec, k), joinKey(path, k)
+	}
+	var fixes []Fix
+	for _, list := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		seq := getField(spec, list)
+		if seq == nil || seq.Kind != yaml.SequenceNode {
+			continue
+		}
+		for i, ctr := range seq.Content {
+			img := getField(ctr, "image")
+			if img == nil || img.Kind != yaml.ScalarNode || img.Tag != "!!str" {
+				continue
+			}
+			want, ok := lock.Digest(img.Value)
+			ref := splitImageRef(img.Value)
+			if !ok || ref.digest == want {
+				continue
+			}
+			ref.digest = want
+			img.Value = ref.String()
+			fixes = append(fixes, Fix{Rule: ruleImageLock, Field: joinKey(itemPath(joinKey(path, list), seq, i), "image"), Line: img.Line})
+		}
+	}
+	slices.SortStableFunc(fixes, func(a, b Fix) int { return cmp.Compare(a.Line, b.Line) })
+	return fixes
+}
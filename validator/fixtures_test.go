@@ -0,0 +1,30 @@
+package validator
+
+import (
+	"context"
+	"testing"
+)
+
+// validPod is a Pod that passes every check without a warning.
+const validPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx:1.25
+    ports:
+    - containerPort: 80
+`
+
+// mustValidate validates src under name and fails the test on an error
+// result.
+func mustValidate(t *testing.T, name, src string, opts Options) *Result {
+	t.Helper()
+	res, err := ValidateBytes(context.Background(), name, []byte(src), opts)
+	if err != nil {
+		t.Fatalf("ValidateBytes(%s): %v", name, err)
+	}
+	return res
+}
@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"testing"
+)
+
+// FuzzValidateBytes checks that no input makes ValidateBytes panic, and
+// that every input either validates or fails with one of the sentinel
+// errors.
+func FuzzValidateBytes(f *testing.F) {
+	for _, src := range []string{validPod, fullPod, twoContainerPod, identicalFindingsPod, deployment,
+		validPod + "---\n" + deployment, "apiVersion: v1\nkind: List\nitems:\n- " + "kind: Pod\n",
+		"a: &x 1\nb: *x\n", "key: [unclosed\n", "# comment only\n", "a: \xff\n", "\xef\xbb\xbfa: 1\n"} {
+		f.Add([]byte(src))
+	}
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(validPod))
+	zw.Close()
+	f.Add(compressed.Bytes())
+	f.Add([]byte("\xff\xfea\x00:\x00 \x001\x00\n\x00"))
+
+	sentinels := []error{ErrNotYAML, ErrEmptyDocument, ErrEncoding, ErrIO, ErrTooLarge}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		res, err := ValidateBytes(context.Background(), "fuzz.yaml", data, Options{MaxFileSize: 1 << 20, Nested: true})
+		if err != nil {
+			if res != nil {
+				t.Errorf("got a Result along with error %v", err)
+			}
+			for _, s := range sentinels {
+				if errors.Is(err, s) {
+					return
+				}
+			}
+			t.Fatalf("error %v wraps no sentinel", err)
+		}
+		if res == nil {
+			t.Fatal("neither a Result nor an error")
+		}
+		for _, e := range res.Findings {
+			if e.Message == "" || e.Code == "" {
+				t.Errorf("finding %+v lacks a message or code", e)
+			}
+		}
+	})
+}
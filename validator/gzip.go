@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// gzipMagic opens every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// IsGzip reports whether the input called name, holding data, is gzip
+// compressed: data starts with the gzip magic bytes or name ends in
+// ".gz".
+func IsGzip(name string, data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic) || strings.HasSuffix(strings.ToLower(name), ".gz")
+}
+
+// Decompress returns the content of data when IsGzip says it is
+// compressed, and data itself otherwise. No more than limit bytes are
+// decompressed, so that a small input cannot expand without bound; a
+// limit of zero or less decompresses everything. A corrupt stream fails
+// with an error wrapping ErrIO.
+func Decompress(name string, data []byte, limit int64) ([]byte, error) {
+	if !IsGzip(name, data) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err == nil {
+		defer zr.Close()
+		data, err = ReadAll(name, zr, limit)
+	}
+	if err != nil {
+		return nil, gzipError(name, err)
+	}
+	return data, nil
+}
+
+// gzipError describes err, met decompressing the input called name.
+// Errors that already say what went wrong are returned unchanged.
+func gzipError(name string, err error) error {
+	switch {
+	case errors.Is(err, ErrTooLarge), errors.Is(err, ErrIO):
+		return err
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%s: %w: gzip: truncated stream", name, ErrIO)
+	}
+	return fmt.Errorf("%s: %w: %w", name, ErrIO, err)
+}
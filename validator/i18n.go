@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// text is a message fragment that is translated on its own, for
+// messages assembled from parts such as the reason a check applies.
+// Formatted with %s it reads in English.
+type text struct {
+	format string
+	args   []any
+}
+
+func textf(format string, args ...any) text { return text{format: format, args: args} }
+
+func (t text) String() string { return fmt.Sprintf(t.format, t.args...) }
+
+// messages translates the formats the findings of one code are written
+// in, fragments included, keyed by the English format exactly as the
+// checks write it.
+type messages map[string]string
+
+// merged returns the messages of all sets, for codes whose findings
+// read like those of several checks.
+func merged(sets ...messages) messages {
+	out := make(messages)
+	for _, s := range sets {
+		maps.Copy(out, s)
+	}
+	return out
+}
+
+// catalogs maps a language to the messages of every code (see Checks).
+// A code whose findings are not formatted by a check, such as a read
+// error, maps to nil.
+var catalogs = map[string]map[string]messages{
+	"ru": messagesRU,
+}
+
+// Languages lists the languages findings can be written in, English
+// first.
+func Languages() []string {
+	return append([]string{"en"}, slices.Sorted(maps.Keys(catalogs))...)
+}
+
+// ParseLanguage returns the language of a --lang value or locale name
+// such as "ru", "ru_RU.UTF-8" or "en-US", and whether it is one of
+// Languages.
+func ParseLanguage(s string) (string, bool) {
+	lang := strings.ToLower(s)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang, lang == "en" || catalogs[lang] != nil
+}
+
+// Localize returns e with its message in lang, leaving e untouched.
+// The catalog of lang is looked up by e.Code; formats without a
+// translation under it, and fragments of them, read in English.
+// Findings that were not formatted by a check, such as read errors or
+// findings read back from JSON, are returned as they are.
+func Localize(e *ValidationError, lang string) *ValidationError {
+	cat := catalogs[lang][e.Code]
+	if cat == nil || e.format == "" {
+		return e
+	}
+	out := *e
+	out.Message = textf(e.format, e.args...).translate(cat)
+	return &out
+}
+
+func (t text) translate(cat messages) string {
+	format := t.format
+	if tr, ok := cat[format]; ok {
+		format = tr
+	}
+	args := slices.Clone(t.args)
+	for i, a := range args {
+		if f, ok := a.(text); ok {
+			args[i] = f.translate(cat)
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// formatVerb matches the verbs of a fmt format, leaving out "%%".
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]*)?[a-zA-Z]`)
+
+// hasWords matches a format that is more than verbs and punctuation.
+var hasWords = regexp.MustCompile(`\pL`)
+
+func TestCatalogVerbs(t *testing.T) {
+	for lang, cat := range catalogs {
+		for code, msgs := range cat {
+			for en, tr := range msgs {
+				if want, got := formatVerb.FindAllString(en, -1), formatVerb.FindAllString(tr, -1); !slices.Equal(got, want) {
+					t.Errorf("%s %s: %q has verbs %v, English %q has %v", lang, code, tr, got, en, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCatalogsCoverCodes(t *testing.T) {
+	codes := make(map[string]bool)
+	for _, ch := range Checks() {
+		codes[ch.Code] = true
+	}
+	for lang, cat := range catalogs {
+		for code := range codes {
+			if _, ok := cat[code]; !ok {
+				t.Errorf("%s: no entry for %s", lang, code)
+			}
+		}
+		for code := range cat {
+			if !codes[code] {
+				t.Errorf("%s: entry for unknown code %s", lang, code)
+			}
+		}
+	}
+}
+
+func TestCatalogsCoverFindings(t *testing.T) {
+	res := mustValidate(t, "bad.yaml", badManifests, Options{Nested: true, EnableRules: []string{"probe-port"}})
+	for lang := range catalogs {
+		for _, e := range res.Findings {
+			if e.format == "" {
+				continue
+			}
+			for _, f := range textFormats(textf(e.format, e.args...)) {
+				if _, ok := catalogs[lang][e.Code][f]; !ok {
+					t.Errorf("%s %s: no translation of %q", lang, e.Code, f)
+				}
+			}
+		}
+	}
+}
+
+// textFormats returns the formats of t and of the fragments among its
+// arguments that have words to translate.
+func textFormats(t text) []string {
+	var out []string
+	if hasWords.MatchString(formatVerb.ReplaceAllString(t.format, "")) {
+		out = append(out, t.format)
+	}
+	for _, a := range t.args {
+		if f, ok := a.(text); ok {
+			out = append(out, textFormats(f)...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package validator
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImagePolicy restricts the registries container images may come from.
+type ImagePolicy struct {
+	// AllowedRegistries applies to documents no scope matches. Empty
+	// means any registry is allowed.
+	AllowedRegistries []string `yaml:"allowedRegistries"`
+	// Scopes override AllowedRegistries for matching documents.
+	Scopes []ImageScope `yaml:"scopes"`
+}
+
+// ImageScope is an allowlist that applies to documents selected by
+// namespace and labels. A document matches when its namespace matches
+// one of Namespaces (if any are given) and it carries every label in
+// Labels. When several scopes match, the most specific one wins.
+type ImageScope struct {
+	Name              string            `yaml:"name"`
+	Namespaces        []string          `yaml:"namespaces"`
+	Labels            map[string]string `yaml:"labels"`
+	AllowedRegistries []string          `yaml:"allowedRegistries"`
+}
+
+// Validate reports malformed namespace globs.
+func (p *ImagePolicy) Validate() error {
+	for _, s := range p.Scopes {
+		for _, ns := range s.Namespaces {
+			if _, err := path.Match(ns, ""); err != nil {
+				return &ConfigError{Field: "images.scopes[" + s.Name + "].namespaces", Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// scopeFor returns the most specific scope matching a document with
+// the given namespace and labels, or nil. Specificity counts matched
+// labels plus one for a namespace constraint; ties go to the namespace
+// pattern with more literal characters, then to the earlier scope.
+func (p *ImagePolicy) scopeFor(namespace string, labels map[string]string) *ImageScope {
+	var best *ImageScope
+	bestScore, bestLiteral := -1, -1
+	for i := range p.Scopes {
+		s := &p.Scopes[i]
+		score, literal, ok := s.match(namespace, labels)
+		if !ok {
+			continue
+		}
+		if score > bestScore || (score == bestScore && literal > bestLiteral) {
+			best, bestScore, bestLiteral = s, score, literal
+		}
+	}
+	return best
+}
+
+func (s *ImageScope) match(namespace string, labels map[string]string) (score, literal int, ok bool) {
+	if len(s.Namespaces) > 0 {
+		matched := false
+		for _, pat := range s.Namespaces {
+			if m, _ := path.Match(pat, namespace); m {
+				matched = true
+				if l := len(strings.Trim(pat, "*?[]")); l > literal {
+					literal = l
+				}
+			}
+		}
+		if !matched {
+			return 0, 0, false
+		}
+		score++
+	}
+	for k, v := range s.Labels {
+		if labels[k] != v {
+			return 0, 0, false
+		}
+		score++
+	}
+	return score, literal, true
+}
+
+// imageRegistry returns the registry host of an image reference,
+// applying the Docker convention that a first path component without
+// a dot or port (and other than localhost) is a Docker Hub namespace.
+func imageRegistry(ref string) string {
+	first, _, found := strings.Cut(ref, "/")
+	if !found || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return "docker.io"
+	}
+	return first
+}
+
+// documentScope extracts the namespace and labels the image policy is
+// evaluated against.
+func documentScope(doc *yaml.Node) (namespace string, labels map[string]string) {
+	meta := getField(doc, "metadata")
+	if ns := getField(meta, "namespace"); ns != nil && ns.Kind == yaml.ScalarNode {
+		namespace = ns.Value
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	labels = map[string]string{}
+	if l := getField(meta, "labels"); l != nil && l.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(l.Content); i += 2 {
+			labels[l.Content[i].Value] = l.Content[i+1].Value
+		}
+	}
+	return namespace, labels
+}
+
+// imageAllowed checks image n against the policy for the document.
+func (c *checker) imageAllowed(n *yaml.Node, field string) {
+	p := c.opts.ImagePolicy
+	if p == nil {
+		return
+	}
+	allowed, rule := p.AllowedRegistries, textf("the global allowlist")
+	if s := p.scopeFor(c.namespace, c.labels); s != nil {
+		allowed, rule = s.AllowedRegistries, textf("scope '%s'", s.Name)
+	}
+	if len(allowed) == 0 {
+		return
+	}
+	reg := imageRegistry(n.Value)
+	for _, a := range allowed {
+		if reg == a {
+			c.trace.note(field, "registry ok")
+			return
+		}
+	}
+	c.report(newError(CategoryEnum, field, n, "uses registry '%s', which %s does not allow (allowed: %s)",
+		reg, rule, strings.Join(allowed, ", ")))
+}
+
+// imageRefRe is the image reference grammar of the distribution
+// project: an optional registry host and port, a lowercase repository
+// path of components separated by '.', '_', '__' or dashes, then an
+// optional tag and an optional digest.
+var imageRefRe = regexp.MustCompile(`^` +
+	`(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?` +
+	`(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})?$`)
+
+// imageFormat reports image n unless it is a well-formed reference; the
+// other image checks only make sense of one that is.
+func (c *checker) imageFormat(n *yaml.Node, field string) bool {
+	if imageRefRe.MatchString(n.Value) {
+		c.trace.note(field, "format ok")
+		return true
+	}
+	c.report(newError(CategoryFormat, field, n,
+		"has invalid format '%s': must be an image reference such as 'nginx:1.25' or 'registry.example.com/team/app@sha256:...' with a lowercase repository", n.Value))
+	return false
+}
+
+// ruleLatestTag is the rule ID of the warning about floating image tags.
+const ruleLatestTag = "latest-tag"
+
+// imageTag warns about image n when it runs the latest tag, explicitly
+// or by leaving the tag out, since the image can then change under the
+// same manifest. Digest references are pinned whatever their tag.
+func (c *checker) imageTag(n *yaml.Node, field string) {
+	ref := n.Value
+	if strings.Contains(ref, "@") {
+		c.trace.note(field, "digest pinned")
+		return
+	}
+	name := ref[strings.LastIndexByte(ref, '/')+1:]
+	_, tag, tagged := strings.Cut(name, ":")
+	var w *ValidationError
+	switch {
+	case !tagged:
+		w = newError(CategoryFormat, field, n, "has no tag, so it runs 'latest', which can change without a manifest change; pin a version")
+	case tag == "latest":
+		w = newError(CategoryFormat, field, n, "uses the 'latest' tag, which can change without a manifest change; pin a version")
+	default:
+		c.trace.note(field, "tag ok")
+		return
+	}
+	w.Rule, w.Severity = ruleLatestTag, SeverityWarning
+	c.report(w)
+}
@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestImagePolicyScopes(t *testing.T) {
+	policy := &ImagePolicy{
+		AllowedRegistries: []string{"registry.bigbrother.io"},
+		Scopes: []ImageScope{
+			{Name: "prod", Namespaces: []string{"prod-*"}, AllowedRegistries: []string{"registry.bigbrother.io"}},
+			{Name: "sandbox", Namespaces: []string{"sandbox-*"}, AllowedRegistries: []string{"registry.bigbrother.io", "ghcr.io"}},
+			{Name: "sandbox-ml", Namespaces: []string{"sandbox-m*"}, AllowedRegistries: []string{"nvcr.io"}},
+			{Name: "ml", Labels: map[string]string{"team": "ml"}, AllowedRegistries: []string{"nvcr.io", "ghcr.io"}},
+			{Name: "ml-sandbox", Namespaces: []string{"sandbox-*"}, Labels: map[string]string{"team": "ml"}, AllowedRegistries: []string{"nvcr.io"}},
+		},
+	}
+	tests := []struct {
+		name      string
+		namespace string // "" leaves it out, so the pod is in "default"
+		labels    string // YAML flow mapping, or ""
+		image     string
+		want      string // the message, or "" when the image is allowed
+	}{
+		{"global allowlist", "", "", "registry.bigbrother.io/web:1", ""},
+		{"global allowlist refuses", "", "", "ghcr.io/web:1",
+			"uses registry 'ghcr.io', which the global allowlist does not allow (allowed: registry.bigbrother.io)"},
+		{"an unscoped namespace falls back", "staging", "", "docker.io/nginx:1.25",
+			"uses registry 'docker.io', which the global allowlist does not allow (allowed: registry.bigbrother.io)"},
+		{"prod scope", "prod-eu", "", "registry.bigbrother.io/web:1", ""},
+		{"prod scope refuses", "prod-eu", "", "ghcr.io/web:1",
+			"uses registry 'ghcr.io', which scope 'prod' does not allow (allowed: registry.bigbrother.io)"},
+		{"sandbox scope", "sandbox-a", "", "ghcr.io/web:1", ""},
+		{"Docker Hub shorthand", "sandbox-a", "", "nginx:1.25",
+			"uses registry 'docker.io', which scope 'sandbox' does not allow (allowed: registry.bigbrother.io, ghcr.io)"},
+		{"more literal namespace pattern wins", "sandbox-ml", "", "ghcr.io/web:1",
+			"uses registry 'ghcr.io', which scope 'sandbox-ml' does not allow (allowed: nvcr.io)"},
+		{"label scope", "", "{team: ml}", "ghcr.io/web:1", ""},
+		{"label scope on another team", "", "{team: web}", "nvcr.io/web:1",
+			"uses registry 'nvcr.io', which the global allowlist does not allow (allowed: registry.bigbrother.io)"},
+		{"namespace and label beat either", "sandbox-a", "{team: ml}", "ghcr.io/web:1",
+			"uses registry 'ghcr.io', which scope 'ml-sandbox' does not allow (allowed: nvcr.io)"},
+	}
+	// Every case is one document of a single input, so that the scope
+	// is evaluated per document.
+	var docs []string
+	for _, tt := range tests {
+		meta := "  name: web\n"
+		if tt.namespace != "" {
+			meta += "  namespace: " + tt.namespace + "\n"
+		}
+		if tt.labels != "" {
+			meta += "  labels: " + tt.labels + "\n"
+		}
+		docs = append(docs, fmt.Sprintf("apiVersion: v1\nkind: Pod\nmetadata:\n%sspec:\n  containers:\n  - name: web\n    image: %s\n", meta, tt.image))
+	}
+	res := mustValidate(t, "pods.yaml", strings.Join(docs, "---\n"), Options{ImagePolicy: policy})
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range res.Findings {
+				if e.Document == i+1 {
+					got = append(got, e.Field+" "+e.Message)
+				}
+			}
+			var want []string
+			if tt.want != "" {
+				want = []string{"spec.containers[name=web].image " + tt.want}
+			}
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("findings %q, want %q", got, want)
+			}
+		})
+	}
+}
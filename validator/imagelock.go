@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule IDs of the image lock checks.
+const (
+	ruleImageLock     = "image-lock"
+	ruleImageUnlocked = "image-unlocked"
+)
+
+// ImageLock maps image repositories to the digest every reference to
+// them must pin. It is read from a lockfile of "repository: digest"
+// lines, e.g.
+//
+//	nginx: sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac
+//	ghcr.io/acme/api: sha256:…
+//
+// Repositories are compared in normalized form, so "nginx" and
+// "docker.io/library/nginx" are the same entry.
+type ImageLock struct {
+	digests map[string]string
+	// RequireLocked warns about images whose repository has no entry.
+	RequireLocked bool
+}
+
+// digestRe matches an OCI content digest such as "sha256:<hex>".
+var digestRe = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+
+// ParseImageLock reads a lockfile. Malformed digests and repositories
+// listed twice, after normalization, are errors.
+func ParseImageLock(data []byte) (*ImageLock, error) {
+	var m yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	lock := &ImageLock{digests: map[string]string{}}
+	if len(m.Content) == 0 {
+		return lock, nil
+	}
+	root := m.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: image lock must be a mapping of repository to digest (found %s)", root.Line, describeNode(root))
+	}
+	seen := map[string]int{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		if v.Kind != yaml.ScalarNode || !digestRe.MatchString(v.Value) {
+			return nil, fmt.Errorf("line %d: %s has invalid digest %s", v.Line, k.Value, describeNode(v))
+		}
+		repo := NormalizeRepository(k.Value)
+		if prev, ok := seen[repo]; ok {
+			return nil, fmt.Errorf("line %d: %s is already locked on line %d", k.Line, k.Value, prev)
+		}
+		seen[repo] = k.Line
+		lock.digests[repo] = v.Value
+	}
+	return lock, nil
+}
+
+// Digest returns the digest locked for the repository of image ref.
+func (l *ImageLock) Digest(ref string) (string, bool) {
+	d, ok := l.digests[NormalizeRepository(splitImageRef(ref).repo)]
+	return d, ok
+}
+
+// imageRef is an image reference split into its parts.
+type imageRef struct {
+	repo, tag, digest string
+}
+
+func splitImageRef(ref string) imageRef {
+	var r imageRef
+	ref, r.digest, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndexByte(ref, ':'); i > strings.LastIndexByte(ref, '/') {
+		ref, r.tag = ref[:i], ref[i+1:]
+	}
+	r.repo = ref
+	return r
+}
+
+func (r imageRef) String() string {
+	s := r.repo
+	if r.tag != "" {
+		s += ":" + r.tag
+	}
+	if r.digest != "" {
+		s += "@" + r.digest
+	}
+	return s
+}
+
+// NormalizeRepository spells out the Docker Hub defaults of repository
+// name, a reference without tag or digest: "nginx" becomes
+// "docker.io/library/nginx" and "index.docker.io/x/y" "docker.io/x/y".
+func NormalizeRepository(name string) string {
+	reg := imageRegistry(name)
+	rest := name
+	if first, after, ok := strings.Cut(name, "/"); ok && first == reg {
+		rest = after
+	}
+	if reg == "index.docker.io" {
+		reg = "docker.io"
+	}
+	if reg == "docker.io" && !strings.Contains(rest, "/") {
+		rest = "library/" + rest
+	}
+	return reg + "/" + rest
+}
+
+// imageLocked checks image n against Options.ImageLock.
+func (c *checker) imageLocked(n *yaml.Node, field string) {
+	lock := c.opts.ImageLock
+	if lock == nil {
+		return
+	}
+	ref := splitImageRef(n.Value)
+	want, ok := lock.Digest(n.Value)
+	var e *ValidationError
+	switch {
+	case !ok && lock.RequireLocked:
+		e = newError(CategoryEnum, field, n, "uses repository '%s', which the image lock does not list", NormalizeRepository(ref.repo))
+		e.Rule, e.Severity = ruleImageUnlocked, SeverityWarning
+	case !ok:
+		return
+	case ref.digest == want:
+		c.trace.note(field, "lock ok")
+		return
+	case ref.digest == "":
+		e = newError(CategoryEnum, field, n, "must pin digest '%s' from the image lock", want)
+		e.Rule = ruleImageLock
+	default:
+		e = newError(CategoryEnum, field, n, "pins digest '%s', but the image lock requires '%s'", ref.digest, want)
+		e.Rule = ruleImageLock
+	}
+	c.report(e)
+}
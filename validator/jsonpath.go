@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// jsonMember appends key to the JSONPath p, in dot notation when key is
+// a plain name and in bracket notation otherwise, as for label keys
+// such as "app.kubernetes.io/name".
+func jsonMember(p, key string) string {
+	plain := key != ""
+	for i, r := range key {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= 0x80 || i > 0 && r >= '0' && r <= '9') {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return p + "." + key
+	}
+	return p + "[" + jsonString(key) + "]"
+}
+
+// jsonString quotes s as a single-quoted JSONPath string literal.
+func jsonString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// setPaths fills in the Path of every finding about a field of doc,
+// resolving the named container steps of Field to indices. Fields that
+// do not exist, such as missing required ones, are resolved as far as
+// the document goes and written out from there. Findings about a
+// manifest embedded in doc keep the paths they have within it.
+func setPaths(x *PathIndex, findings []*ValidationError) {
+	for _, e := range findings {
+		if e.embedded == "" {
+			e.Path = x.jsonPath(e.Field)
+		}
+	}
+}
+
+// jsonPath returns the JSONPath of field, a path as findings write it.
+func (x *PathIndex) jsonPath(field string) string {
+	if field == "" {
+		return "$"
+	}
+	if i, ok := x.byPath[field]; ok {
+		return x.entries[i].JSONPath
+	}
+	// Find the longest prefix that exists, ending before a '.' or '['.
+	prefix, base := "", "$"
+	for i := len(field) - 1; i > 0; i-- {
+		if field[i] != '.' && field[i] != '[' {
+			continue
+		}
+		if j, ok := x.byPath[field[:i]]; ok {
+			prefix, base = field[:i], x.entries[j].JSONPath
+			break
+		}
+	}
+	rest := strings.TrimPrefix(field[len(prefix):], ".")
+	segs, err := parsePath(rest)
+	if err != nil {
+		return jsonMember(base, rest)
+	}
+	p := base
+	for _, s := range segs {
+		switch {
+		case s.key != "":
+			p = jsonMember(p, s.key)
+		case s.wildcard:
+			p += "[*]"
+		case s.isIndex():
+			p += "[" + strconv.Itoa(s.index) + "]"
+		default:
+			p += "[?(@" + jsonMember("", s.matchKey) + "==" + jsonString(s.matchValue) + ")]"
+		}
+	}
+	return p
+}
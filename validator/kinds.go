@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kindPattern is one entry of Options.Kinds or Options.SkipKinds: a kind,
+// optionally qualified by its API group as in "apps/Deployment". The
+// core group is written "core".
+type kindPattern struct {
+	group, kind string
+	anyGroup    bool
+}
+
+func parseKindPattern(s string) (kindPattern, error) {
+	group, kind, qualified := strings.Cut(s, "/")
+	if !qualified {
+		group, kind = "", s
+	}
+	if kind == "" || strings.Contains(kind, "/") || (qualified && group == "") {
+		return kindPattern{}, fmt.Errorf("invalid kind %q (want KIND or GROUP/KIND)", s)
+	}
+	if group == "core" {
+		group = ""
+	}
+	return kindPattern{group: group, kind: kind, anyGroup: !qualified}, nil
+}
+
+func (p kindPattern) matches(gvk GroupVersionKind) bool {
+	return p.kind == gvk.Kind && (p.anyGroup || p.group == gvk.Group)
+}
+
+// overlaps reports whether p and q can match the same document.
+func (p kindPattern) overlaps(q kindPattern) bool {
+	return p.kind == q.kind && (p.anyGroup || q.anyGroup || p.group == q.group)
+}
+
+// CheckKindFilters reports malformed entries in kinds and skip, the
+// values of Options.Kinds and Options.SkipKinds, and entries of the two
+// that cover the same kind.
+func CheckKindFilters(kinds, skip []string) error {
+	var in, out []kindPattern
+	for _, list := range []struct {
+		names []string
+		dst   *[]kindPattern
+	}{{kinds, &in}, {skip, &out}} {
+		for _, s := range list.names {
+			p, err := parseKindPattern(s)
+			if err != nil {
+				return err
+			}
+			*list.dst = append(*list.dst, p)
+		}
+	}
+	for i, p := range in {
+		for j, q := range out {
+			if p.overlaps(q) {
+				return fmt.Errorf("kind %q is both included (%s) and skipped (%s)", p.kind, kinds[i], skip[j])
+			}
+		}
+	}
+	return nil
+}
+
+// kindSelected reports whether doc passes Options.Kinds and SkipKinds,
+// along with its kind for logging. Documents without a kind are always
+// selected so that the missing field gets reported.
+func (o *Options) kindSelected(doc *yaml.Node) (string, bool) {
+	if len(o.Kinds) == 0 && len(o.SkipKinds) == 0 {
+		return "", true
+	}
+	kind := getField(doc, "kind")
+	if kind == nil || kind.Kind != yaml.ScalarNode {
+		return "", true
+	}
+	gvk := GroupVersionKind{Kind: kind.Value}
+	if v := getField(doc, "apiVersion"); v != nil {
+		gvk.Group, gvk.Version = ParseGroupVersion(v.Value)
+	}
+	if matchesAnyKind(o.SkipKinds, gvk) {
+		return kind.Value, false
+	}
+	return kind.Value, len(o.Kinds) == 0 || matchesAnyKind(o.Kinds, gvk)
+}
+
+func matchesAnyKind(patterns []string, gvk GroupVersionKind) bool {
+	for _, s := range patterns {
+		if p, err := parseKindPattern(s); err == nil && p.matches(gvk) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package validator
+
+import "gopkg.in/yaml.v3"
+
+// Rule IDs of the sanity limits; "limit" disables them all.
+const (
+	ruleLimitContainers  = "limit-containers"
+	ruleLimitPorts       = "limit-ports"
+	ruleLimitEnv         = "limit-env"
+	ruleLimitLabels      = "limit-labels"
+	ruleLimitAnnotations = "limit-annotations"
+)
+
+// Limits bounds the size of lists and maps that real manifests keep
+// small. A document over a bound is most likely produced by a broken
+// generator, and the bounds keep pathological inputs cheap to report.
+// A zero bound disables the check.
+type Limits struct {
+	// Containers bounds spec.containers and spec.initContainers each.
+	Containers int `yaml:"containers"`
+	// PortsPerContainer and EnvPerContainer bound the ports and env
+	// lists of every container.
+	PortsPerContainer int `yaml:"portsPerContainer"`
+	EnvPerContainer   int `yaml:"envPerContainer"`
+	// Labels and Annotations bound the entries of metadata.labels and
+	// metadata.annotations.
+	Labels      int `yaml:"labels"`
+	Annotations int `yaml:"annotations"`
+	// Strict reports exceeded limits as errors instead of warnings.
+	Strict bool `yaml:"strict"`
+}
+
+// DefaultLimits are the limits applied when Options.Limits is nil.
+var DefaultLimits = Limits{
+	Containers:        50,
+	PortsPerContainer: 100,
+	EnvPerContainer:   500,
+	Labels:            100,
+	Annotations:       100,
+}
+
+func (o *Options) limits() *Limits {
+	if o.Limits == nil {
+		return &DefaultLimits
+	}
+	return o.Limits
+}
+
+// limit reports n, the list or map at field, when it has more than max
+// entries. name is the Limits key to adjust, for the message.
+func (c *checker) limit(rule, field string, n *yaml.Node, max int, name string) {
+	count := len(n.Content)
+	if n.Kind == yaml.MappingNode {
+		count /= 2
+	}
+	if max <= 0 || count <= max || (n.Kind != yaml.SequenceNode && n.Kind != yaml.MappingNode) {
+		return
+	}
+	e := newError(CategoryRange, field, n, "has %d entries, more than the limit of %d (limits.%s)", count, max, name)
+	e.Rule = rule
+	if !c.opts.limits().Strict {
+		e.Severity = SeverityWarning
+	}
+	c.report(e)
+}
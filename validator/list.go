@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleListItemKind names the warning about List items of a kind no
+// validator is registered for.
+const ruleListItemKind = "list-item-kind"
+
+// listKind is the kind kubectl get -o yaml wraps several objects in.
+var listKind = GroupVersionKind{Version: "v1", Kind: "List"}
+
+// validateList checks a v1 List: items must be a non-empty sequence,
+// and each item is validated like a document of its own, with its
+// findings under "items[i]". An item of an unsupported kind is only
+// warned about, since a List may well hold Services or ConfigMaps next
+// to the pods.
+func validateList(doc *yaml.Node, h Helpers, report ReportFunc) {
+	items := getField(doc, "items")
+	switch {
+	case isNull(items):
+		report(required("items", doc))
+		return
+	case items.Kind != yaml.SequenceNode:
+		report(typeMismatch("items", items, "array"))
+		return
+	case len(items.Content) == 0:
+		report(newError(CategoryRange, "items", items, "must hold at least one item"))
+		return
+	}
+	for i, item := range items.Content {
+		path := joinIndex("items", i)
+		if item.Kind != yaml.MappingNode {
+			report(typeMismatch(path, item, "object"))
+			continue
+		}
+		validateObject(item, h, func(e *ValidationError) {
+			e.Field = prefixField(path, e.Field)
+			if errors.Is(e.Err, ErrUnsupportedKind) {
+				e.Rule, e.Severity = ruleListItemKind, SeverityWarning
+			}
+			report(e)
+		})
+	}
+}
+
+// prefixField puts field below prefix in a field path.
+func prefixField(prefix, field string) string {
+	if field == "" || strings.HasPrefix(field, "[") {
+		return prefix + field
+	}
+	return prefix + "." + field
+}
@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// markdownCollapseAt is the number of findings above which a file's
+// table is folded into a <details> section.
+const markdownCollapseAt = 20
+
+// NewMarkdownReporter returns a Reporter writing the run to w as
+// Markdown when it ends, for posting as a pull request comment: the
+// summary of the Stats as a header, then a table of findings per file
+// with its code, line, field and message. A file with more than 20
+// findings has its table folded into a collapsible section. Findings
+// are rendered from the same wire form as the JSON output.
+func NewMarkdownReporter(w io.Writer) Reporter {
+	return &markdownReporter{w: w, index: map[string]int{}}
+}
+
+type markdownReporter struct {
+	w     io.Writer
+	files []markdownFile
+	index map[string]int
+}
+
+type markdownFile struct {
+	name     string
+	findings []findingJSON
+}
+
+func (m *markdownReporter) Report(e *ValidationError) {
+	i, ok := m.index[e.File]
+	if !ok {
+		i = len(m.files)
+		m.index[e.File] = i
+		m.files = append(m.files, markdownFile{name: e.File})
+	}
+	m.files[i].findings = append(m.files[i].findings, e.wire())
+}
+
+func (m *markdownReporter) Summary(s Stats) error {
+	b := bufio.NewWriter(m.w)
+	fmt.Fprintf(b, "## Validation results\n\n**%s**\n", markdownEscape(s.String()))
+	if len(m.files) == 0 {
+		fmt.Fprint(b, "\nNo findings.\n")
+	}
+	for _, f := range m.files {
+		collapse := len(f.findings) > markdownCollapseAt
+		if collapse {
+			fmt.Fprintf(b, "\n<details>\n<summary>%s: %d findings</summary>\n", htmlEscape(f.name), len(f.findings))
+		} else {
+			fmt.Fprintf(b, "\n### %s\n", markdownEscape(f.name))
+		}
+		fmt.Fprint(b, "\n| Code | Line | Field | Message |\n| --- | --- | --- | --- |\n")
+		for _, e := range f.findings {
+			line := ""
+			if e.Line > 0 {
+				line = strconv.Itoa(e.Line)
+			}
+			msg := e.Message
+			if e.Occurrences > 1 {
+				msg += fmt.Sprintf(" (x%d)", e.Occurrences)
+			}
+			if e.Severity == SeverityWarning {
+				msg = "warning: " + msg
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %s |\n", markdownEscape(e.Code), line, markdownEscape(e.Field), markdownEscape(msg))
+		}
+		if collapse {
+			fmt.Fprint(b, "\n</details>\n")
+		}
+	}
+	if s.Omitted > 0 {
+		fmt.Fprintf(b, "\n%s\n", markdownEscape(s.OmittedNotice()))
+	}
+	return b.Flush()
+}
+
+// markdownEscape keeps s from breaking a table row or being read as
+// formatting: pipes, backticks and emphasis characters are escaped and
+// newlines become line breaks.
+var markdownEscape = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "`", "\\`", "*", `\*`, "_", `\_`,
+	"[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;", "\n", "<br>",
+).Replace
+
+// htmlEscape makes s safe inside an HTML element such as <summary>.
+var htmlEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\n", " ").Replace
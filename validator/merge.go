@@ -0,0 +1,229 @@
+package validator
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrIncompatibleReport means a JSON report was written for a schema
+// other than OutputSchemaID and the earlier ones that can still be
+// read, and cannot be read back.
+var ErrIncompatibleReport = errors.New("incompatible report")
+
+// Report is a run read back from the document NewJSONReporter writes.
+type Report struct {
+	Findings  []*ValidationError
+	Resources []Resource
+	Stats     Stats
+}
+
+// ReadJSONReport parses a document written by NewJSONReporter, or a
+// stream written by NewNDJSONReporter. It fails with
+// ErrIncompatibleReport unless the report names OutputSchemaID, or an
+// earlier schema it can still read, as its schema.
+func ReadJSONReport(data []byte) (*Report, error) {
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] == '[' {
+		return nil, fmt.Errorf("%w: a findings array from before %s", ErrIncompatibleReport, OutputSchemaID)
+	}
+	var doc struct {
+		Type      string             `json:"type"`
+		Schema    string             `json:"schema"`
+		Findings  []*ValidationError `json:"findings"`
+		Resources []resourceJSON     `json:"resources"`
+		Summary   summaryJSON        `json:"summary"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Type != "" {
+		return readNDJSONReport(data)
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after the report")
+	}
+	if !schemaReadable(doc.Schema) {
+		if doc.Schema == "" {
+			return nil, fmt.Errorf("%w: no schema, want %s", ErrIncompatibleReport, OutputSchemaID)
+		}
+		return nil, fmt.Errorf("%w: schema %s, want %s", ErrIncompatibleReport, doc.Schema, OutputSchemaID)
+	}
+	rep := &Report{Findings: doc.Findings, Stats: doc.Summary.stats()}
+	for _, r := range doc.Resources {
+		rep.Resources = append(rep.Resources, r.resource())
+	}
+	return rep, nil
+}
+
+// readNDJSONReport parses a stream written by NewNDJSONReporter, which
+// must end with its summary line.
+func readNDJSONReport(data []byte) (*Report, error) {
+	rep := &Report{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	done := false
+	for dec.More() {
+		if done {
+			return nil, errors.New("unexpected data after the summary line")
+		}
+		var line struct {
+			Type   string `json:"type"`
+			Schema string `json:"schema"`
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return nil, err
+		}
+		switch line.Type {
+		case "finding":
+			e := &ValidationError{}
+			if err := json.Unmarshal(raw, e); err != nil {
+				return nil, err
+			}
+			rep.Findings = append(rep.Findings, e)
+		case "resource":
+			var r resourceJSON
+			if err := json.Unmarshal(raw, &r); err != nil {
+				return nil, err
+			}
+			rep.Resources = append(rep.Resources, r.resource())
+		case "summary":
+			if !schemaReadable(line.Schema) {
+				return nil, fmt.Errorf("%w: schema %s, want %s", ErrIncompatibleReport, line.Schema, OutputSchemaID)
+			}
+			var s summaryJSON
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, err
+			}
+			rep.Stats = s.stats()
+			done = true
+		default:
+			return nil, fmt.Errorf("unknown line type %q", line.Type)
+		}
+	}
+	if !done {
+		return nil, fmt.Errorf("%w: no summary line, so the run did not finish", ErrIncompatibleReport)
+	}
+	return rep, nil
+}
+
+// MergeReports combines the reports of shards of one run. Findings are
+// de-duplicated by fingerprint and sorted by file and then position, so
+// the result does not depend on the order of reports. Skip and ignore
+// counts are added up; error, warning and invalid-file counts are
+// recomputed from the merged findings, keeping what each shard
+// counted among its omitted findings. Files and documents are counted
+// once however many shards name them in resources or findings; what a
+// shard counted beyond those it names is added. Resources are sorted by
+// file and document, and a resource that several shards list is kept
+// once.
+func MergeReports(reports ...*Report) *Report {
+	out := &Report{}
+	seen := make(map[string]bool)
+	type doc struct {
+		file  string
+		index int
+	}
+	listed := make(map[doc]bool)
+	files, docs := make(map[string]bool), make(map[doc]bool)
+	for _, r := range reports {
+		shardFiles, shardDocs := make(map[string]bool), make(map[doc]bool)
+		for _, res := range r.Resources {
+			d := doc{res.File, res.Index}
+			if !listed[d] {
+				listed[d] = true
+				out.Resources = append(out.Resources, res)
+			}
+			shardFiles[res.File], shardDocs[d] = true, true
+		}
+		for _, e := range r.Findings {
+			if e.File != "" {
+				shardFiles[e.File] = true
+			}
+			if e.Document > 0 {
+				shardDocs[doc{e.File, e.Document}] = true
+			}
+		}
+		for f := range shardFiles {
+			files[f] = true
+		}
+		for d := range shardDocs {
+			docs[d] = true
+		}
+		errs, warns, invalid, invalidDocs := tally(r.Findings)
+		out.Stats.Files += max(r.Stats.Files-len(shardFiles), 0)
+		out.Stats.Documents += max(r.Stats.Documents-len(shardDocs), 0)
+		out.Stats.Skipped += r.Stats.Skipped
+		out.Stats.Ignored += r.Stats.Ignored
+		out.Stats.Excluded += r.Stats.Excluded
+		out.Stats.Unreadable += r.Stats.Unreadable
+		out.Stats.Omitted += r.Stats.Omitted
+		out.Stats.Errors += max(r.Stats.Errors-errs, 0)
+		out.Stats.Warnings += max(r.Stats.Warnings-warns, 0)
+		out.Stats.Invalid += max(r.Stats.Invalid-invalid, 0)
+		out.Stats.InvalidDocuments += max(r.Stats.InvalidDocuments-invalidDocs, 0)
+		for _, e := range r.Findings {
+			if e.Fingerprint != "" {
+				if seen[e.Fingerprint] {
+					continue
+				}
+				seen[e.Fingerprint] = true
+			}
+			out.Findings = append(out.Findings, e)
+		}
+	}
+	out.Stats.Files += len(files)
+	out.Stats.Documents += len(docs)
+	slices.SortStableFunc(out.Findings, func(a, b *ValidationError) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), compareFindings(a, b), cmp.Compare(a.Fingerprint, b.Fingerprint))
+	})
+	slices.SortStableFunc(out.Resources, func(a, b Resource) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Index, b.Index))
+	})
+	errs, warns, invalid, invalidDocs := tally(out.Findings)
+	out.Stats.Errors += errs
+	out.Stats.Warnings += warns
+	out.Stats.Invalid += invalid
+	out.Stats.InvalidDocuments += invalidDocs
+	return out
+}
+
+// tally counts the errors and warnings among findings and the files
+// and documents that have errors.
+func tally(findings []*ValidationError) (errs, warns, invalid, invalidDocs int) {
+	type doc struct {
+		file  string
+		index int
+	}
+	files := make(map[string]bool)
+	docs := make(map[doc]bool)
+	for _, e := range findings {
+		switch e.Severity {
+		case SeverityError:
+			errs++
+			if !files[e.File] {
+				files[e.File] = true
+				invalid++
+			}
+			if d := (doc{e.File, e.Document}); d.index > 0 && !docs[d] {
+				docs[d] = true
+				invalidDocs++
+			}
+		case SeverityWarning:
+			warns++
+		}
+	}
+	return errs, warns, invalid, invalidDocs
+}
+
+// schemaReadable reports whether ReadJSONReport reads reports written
+// for schema id.
+func schemaReadable(id string) bool {
+	return id == OutputSchemaID || slices.Contains(readableSchemas, id)
+}
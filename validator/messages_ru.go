@@ -0,0 +1,226 @@
+package validator
+
+// messagesRU translates the messages of every code to Russian. Field
+// paths, kinds, YAML type names and values stay as written in the
+// manifest.
+var messagesRU = map[string]messages{
+	// Documents and metadata.
+	"PV001": ruEnum,
+	"PV002": ruEnum,
+	"PV003": merged(ruType, messages{"document must be a mapping (found %s)": "документ должен быть отображением (найдено: %s)"}),
+	"PV004": ruRequired,
+	"PV005": ruRequired,
+	"PV006": ruType,
+	"PV007": ruType,
+	"PV008": ruRequired,
+	"PV009": ruRequired,
+	"PV150": ruRequired,
+	"PV151": ruType,
+	"PV152": ruRequired,
+	"PV153": ruType,
+	"PV154": ruType,
+	"PV155": ruType,
+	"PV156": ruRange,
+	"PV157": ruType,
+	"PV158": ruType,
+	"PV159": ruType,
+	"PV100": {"defines anchor '&%s', which no alias uses": "определяет якорь '&%s', который не использует ни один псевдоним"},
+	"PV132": {"is empty, so no selector can match this object": "пусто, поэтому ни один селектор не может выбрать этот объект"},
+	"PV138": merged(ruDeprecated, messages{"'%s' for kind %s ": "'%s' для kind %s "}),
+	"PV139": ruDeprecated,
+
+	// Containers.
+	"PV010": merged(ruFormat, messages{
+		"has invalid format '%s': must be lowercase letters, digits and '-', starting and ending with a letter or digit": "имеет неверный формат '%s': допускаются строчные буквы, цифры и '-', в начале и в конце — буква или цифра",
+	}),
+	"PV011": merged(ruFormat, messages{
+		"has invalid format '%s': must be an image reference such as 'nginx:1.25' or 'registry.example.com/team/app@sha256:...' with a lowercase repository": "имеет неверный формат '%s': ожидается ссылка на образ вида 'nginx:1.25' или 'registry.example.com/team/app@sha256:...' с репозиторием в нижнем регистре",
+	}),
+	"PV012": ruType,
+	"PV020": ruType,
+	"PV021": ruFormat,
+	"PV022": merged(ruFormat, messages{
+		"has invalid format '%s': CPU is allocated in millicores, so at most three decimal places (1m) are allowed": "имеет неверный формат '%s': CPU выделяется в миллиядрах, поэтому допускается не более трёх знаков после запятой (1m)",
+	}),
+	"PV023": ruFormat,
+	"PV024": {"must not exceed limits.%s ('%s' > '%s')": "не должно превышать limits.%s ('%s' > '%s')"},
+	"PV030": ruRange,
+	"PV031": ruRange,
+	"PV032": ruEnum,
+	"PV040": ruRequired,
+	"PV050": ruType,
+	"PV060": ruType,
+	"PV061": ruType,
+	"PV130": {
+		"has no tag, so it runs 'latest', which can change without a manifest change; pin a version": "не имеет тега, поэтому запускается 'latest', который может измениться без изменения манифеста; закрепите версию",
+		"uses the 'latest' tag, which can change without a manifest change; pin a version":           "использует тег 'latest', который может измениться без изменения манифеста; закрепите версию",
+	},
+	"PV133": {
+		"must pin digest '%s' from the image lock":           "должно закреплять дайджест '%s' из файла блокировки образов",
+		"pins digest '%s', but the image lock requires '%s'": "закрепляет дайджест '%s', но файл блокировки образов требует '%s'",
+	},
+	"PV134": {"uses repository '%s', which the image lock does not list": "использует репозиторий '%s', которого нет в файле блокировки образов"},
+	"PV160": ruType,
+	"PV161": ruType,
+	"PV162": ruRequired,
+	"PV163": ruType,
+	"PV164": ruRange,
+	"PV165": ruRequired,
+	"PV166": ruType,
+	"PV167": merged(ruEnum, messages{
+		"uses registry '%s', which %s does not allow (allowed: %s)": "использует реестр '%s', который не разрешён в %s (разрешены: %s)",
+		"the global allowlist": "глобальном списке разрешённых",
+		"scope '%s'":           "области '%s'",
+	}),
+	"PV168": ruType,
+	"PV170": ruType,
+	"PV171": ruType,
+	"PV172": ruRequired,
+	"PV173": ruType,
+	"PV174": ruType,
+	"PV175": {
+		"conflicts with %s: both bind %s/%d on the host%s": "конфликтует с %s: оба занимают %s/%d на узле%s",
+		" (hostNetwork is true)":                           " (hostNetwork равно true)",
+	},
+	"PV176": ruType,
+	"PV177": ruRange,
+	"PV178": ruType,
+	"PV180": ruType,
+	"PV181": ruType,
+	"PV182": ruType,
+	"PV183": ruType,
+	"PV191": ruType,
+	"PV192": ruType,
+	"PV193": ruRange,
+	"PV194": ruType,
+	"PV195": ruType,
+
+	// Probes.
+	"PV051": {
+		"refers to port '%s', which the container does not define (%s)": "ссылается на порт '%s', который контейнер не определяет (%s)",
+		"the container defines no named ports":                          "в контейнере нет именованных портов",
+		"defined: %s":                                                   "определены: %s",
+		"%s; also used by %s":                                           "%s; также используется в %s",
+	},
+	"PV135": merged(ruDeclaredPorts, messages{
+		"probes port %d of the node (hostNetwork is true), which is no containerPort of the container (%s)": "проверяет порт %d узла (hostNetwork равно true), который не является containerPort контейнера (%s)",
+	}),
+	"PV136": merged(ruDeclaredPorts, messages{
+		"probes port %d, which is no containerPort of the container (%s); declare it with a name and probe it by name": "проверяет порт %d, который не является containerPort контейнера (%s); объявите его с именем и проверяйте по имени",
+	}),
+	"PV184": ruRequired,
+	"PV185": ruType,
+	"PV186": merged(ruFormat, messages{
+		"has invalid format '%s': must be a path, not a URL; set httpGet.scheme, httpGet.host and httpGet.port instead": "имеет неверный формат '%s': должен быть путь, а не URL; вместо этого задайте httpGet.scheme, httpGet.host и httpGet.port",
+		"has invalid format '%s': must start with '/'":                                                                  "имеет неверный формат '%s': должен начинаться с '/'",
+		"contains characters that should be percent-encoded: %s":                                                        "содержит символы, которые нужно записать в percent-кодировке: %s",
+	}),
+	"PV187": ruRequired,
+	"PV188": ruType,
+	"PV189": ruRange,
+	"PV190": ruFormat,
+
+	// Pod specs.
+	"PV062": ruType,
+	"PV070": ruDuplicate,
+	"PV071": ruDuplicate,
+	"PV072": ruDuplicate,
+	"PV073": ruDuplicate,
+	"PV074": merged(ruDuplicate, messages{"topologyKey '%s' with whenUnsatisfiable '%s'": "topologyKey '%s' с whenUnsatisfiable '%s'"}),
+	"PV080": merged(ruWindows, messages{"must not be true for Windows pods": "не должно быть true для подов Windows"}),
+	"PV081": merged(ruWindows, messages{"must not be true for Windows pods; use a HostProcess container instead": "не должно быть true для подов Windows; используйте вместо этого контейнер HostProcess"}),
+	"PV082": merged(ruWindows, messages{"must not be set for Windows pods": "не должно задаваться для подов Windows"}),
+	"PV083": merged(ruWindows, messages{"mounts a host path, which on Windows nodes needs a Windows path and often HostProcess privileges": "монтирует путь узла, который на узлах Windows должен быть путём Windows и часто требует привилегий HostProcess"}),
+	"PV090": ruLimit,
+	"PV091": ruLimit,
+	"PV092": ruLimit,
+	"PV093": ruLimit,
+	"PV094": ruLimit,
+	"PV124": {"must not be set in a pod template; the controller sets it on the pods it creates": "не должно задаваться в шаблоне пода; контроллер задаёт его в создаваемых подах"},
+	"PV131": {"binds a port on the node, so only one such pod fits on each node; prefer a Service": "занимает порт на узле, поэтому на каждом узле помещается только один такой под; лучше используйте Service"},
+	"PV200": ruRequired,
+	"PV201": ruType,
+	"PV202": ruRange,
+	"PV204": {"is ignored in a pod template": "игнорируется в шаблоне пода"},
+
+	// Deployments.
+	"PV120": ruRequired,
+	"PV121": {
+		"has no matching label in spec.template.metadata.labels (line %d)": "не имеет соответствующей метки в spec.template.metadata.labels (строка %d)",
+		"is '%s' but spec.selector.matchLabels requires '%s' (line %d)":    "равно '%s', но spec.selector.matchLabels требует '%s' (строка %d)",
+	},
+	"PV122": ruRange,
+	"PV123": ruRequired,
+	"PV203": ruRequired,
+	"PV205": ruType,
+	"PV206": ruType,
+	"PV207": ruRequired,
+	"PV208": ruType,
+	"PV209": ruType,
+	"PV210": ruType,
+	"PV211": ruType,
+	"PV212": ruRequired,
+	"PV213": ruType,
+
+	// Lists, ConfigMaps and nested manifests.
+	"PV140": ruType,
+	"PV141": ruEnum,
+	"PV142": {"does not parse as YAML: %s": "не разбирается как YAML: %s"},
+	"PV220": ruRequired,
+	"PV221": merged(ruRange, messages{"must hold at least one item": "должно содержать хотя бы один элемент"}),
+	"PV222": ruType,
+	"PV223": ruType,
+	"PV224": ruType,
+
+	// Cross-references between inputs.
+	"PV110": {"matches no workload in the input set (selector %s, namespace '%s')": "не выбирает ни одной рабочей нагрузки среди входных данных (селектор %s, пространство имён '%s')"},
+	"PV111": {"refers to Service '%s', which is not in the input set (namespace '%s')": "ссылается на Service '%s', которого нет среди входных данных (пространство имён '%s')"},
+
+	// Fields made mandatory by the config, and the findings of checks
+	// registered for other kinds.
+	"PV137": ruRequired,
+	"PV901": ruRequired,
+	"PV902": ruType,
+	"PV903": ruFormat,
+	"PV904": ruRange,
+	"PV905": ruEnum,
+	"PV906": nil, // written by the registered check
+	"PV907": nil,
+	"PV908": nil,
+}
+
+// The messages shared by the codes of one kind of check.
+var (
+	ruRequired = messages{
+		"is required":             "является обязательным",
+		"is required (found: %s)": "является обязательным (найдено: %s)",
+		"is required (did you mean '%s' instead of '%s'? found: %s)": "является обязательным (возможно, имелось в виду '%s' вместо '%s'? найдено: %s)",
+	}
+	ruType = messages{
+		"must be %s (found %s)":                                   "должно иметь тип %s (найдено: %s)",
+		"should be string (found %s); quote it as \"%s\"":         "должно быть строкой (найдено: %s); заключите в кавычки: \"%s\"",
+		"must be boolean (found string '%s' — remove the quotes)": "должно быть логическим значением (найдена строка '%s' — уберите кавычки)",
+		"must be boolean (found '%s' — use true/false)":           "должно быть логическим значением (найдено '%s' — используйте true/false)",
+	}
+	ruFormat = messages{"has invalid format '%s'": "имеет неверный формат '%s'"}
+	ruRange  = messages{
+		"value out of range '%s'":         "значение вне допустимого диапазона '%s'",
+		"is %d characters, maximum is %d": "имеет длину %d символов, максимум %d",
+	}
+	ruEnum       = messages{"has unsupported value '%s'": "имеет неподдерживаемое значение '%s'"}
+	ruLimit      = messages{"has %d entries, more than the limit of %d (limits.%s)": "содержит %d элементов, больше предела %d (limits.%s)"}
+	ruDeprecated = messages{
+		"%swas removed in Kubernetes %s (targeting %s); use %s": "%sудалено в Kubernetes %s (целевая версия %s); используйте %s",
+		"%sis deprecated since Kubernetes %s; use %s":           "%sустарело начиная с Kubernetes %s; используйте %s",
+	}
+	ruDuplicate = messages{
+		"has duplicate %s (first used at %s)": "повторяет %s (впервые использовано в %s)",
+		"value '%s'":                          "значение '%s'",
+		"%s, line %d":                         "%s, строка %d",
+	}
+	ruWindows       = messages{"spec.os.name is windows on line %d": "spec.os.name равно windows в строке %d"}
+	ruDeclaredPorts = messages{
+		"the container declares none": "контейнер не объявляет ни одного",
+		"declared: %s":                "объявлены: %s",
+	}
+)
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// NewNDJSONReporter returns a Reporter writing the run to w as
+// newline-delimited JSON: each finding as an object with "type":
+// "finding" and each document that validated without errors as one
+// with "type": "resource", as soon as they are reported, then one
+// object with "type": "summary" holding the schema ID and the summary
+// of the Stats. Nothing
+// is held back, so memory does not grow with the number of findings.
+// When w has a Flush method it is called after every line.
+func NewNDJSONReporter(w io.Writer) ResourceReporter { return &ndjsonReporter{w: w} }
+
+type ndjsonFinding struct {
+	Type string `json:"type"`
+	findingJSON
+}
+
+type ndjsonResource struct {
+	Type string `json:"type"`
+	resourceJSON
+}
+
+type ndjsonSummary struct {
+	Type   string `json:"type"`
+	Schema string `json:"schema"`
+	summaryJSON
+}
+
+type ndjsonReporter struct {
+	w   io.Writer
+	err error
+}
+
+func (n *ndjsonReporter) Report(e *ValidationError) {
+	n.write(ndjsonFinding{Type: "finding", findingJSON: e.wire()})
+}
+
+func (n *ndjsonReporter) Resource(r Resource) {
+	n.write(ndjsonResource{Type: "resource", resourceJSON: r.wire()})
+}
+
+func (n *ndjsonReporter) Summary(s Stats) error {
+	n.write(ndjsonSummary{Type: "summary", Schema: OutputSchemaID, summaryJSON: s.wire()})
+	return n.err
+}
+
+// write encodes v as one line. After the first error nothing more is
+// written, so the stream never holds a partial line followed by more.
+func (n *ndjsonReporter) write(v any) {
+	if n.err != nil {
+		return
+	}
+	enc := json.NewEncoder(n.w)
+	enc.SetEscapeHTML(false)
+	if n.err = enc.Encode(v); n.err != nil {
+		return
+	}
+	if f, ok := n.w.(interface{ Flush() error }); ok {
+		n.err = f.Flush()
+	}
+}
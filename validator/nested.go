@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleNestedParse names the error about a ConfigMap data value, under
+// a manifest name, that does not parse as YAML.
+const ruleNestedParse = "nested-parse"
+
+// configMapKind is the kind whose data values Options.Nested validates.
+var configMapKind = GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+// validateConfigMap validates, as manifests of their own, the data
+// values of a v1 ConfigMap whose keys match *.yaml or *.yml. Their
+// findings are positioned within the value and name the input they sit
+// in as "FILE[data/KEY]"; a value that does not parse is reported at
+// its key.
+func validateConfigMap(doc *yaml.Node, h Helpers, report ReportFunc) {
+	data := getField(doc, "data")
+	if isNull(data) {
+		return
+	}
+	if data.Kind != yaml.MappingNode {
+		report(typeMismatch("data", data, "object"))
+		return
+	}
+	for i := 0; i+1 < len(data.Content); i += 2 {
+		key, value := data.Content[i], data.Content[i+1]
+		if !isManifestKey(key.Value) {
+			continue
+		}
+		field := joinKey("data", key.Value)
+		if value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+			report(typeMismatch(field, value, "string"))
+			continue
+		}
+		findings, err := validateEmbedded(value.Value, *h.opts)
+		if err != nil {
+			e := newError(CategoryParse, field, key, "does not parse as YAML: %s", strings.TrimPrefix(err.Error(), "yaml: "))
+			e.Rule = ruleNestedParse
+			report(e)
+			continue
+		}
+		for _, e := range findings {
+			e.embedded = "data/" + key.Value
+			report(e)
+		}
+	}
+}
+
+// isManifestKey reports whether a ConfigMap data key names a manifest.
+func isManifestKey(key string) bool {
+	ext := strings.ToLower(path.Ext(key))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// validateEmbedded returns the findings about the documents of src, the
+// text of an embedded manifest, validated under opts without descending
+// into further ConfigMaps or applying Select, which is about the
+// enclosing document.
+func validateEmbedded(src string, opts Options) ([]*ValidationError, error) {
+	opts.Nested, opts.Select = false, ""
+	var out []*ValidationError
+	dec := yaml.NewDecoder(strings.NewReader(src))
+	for {
+		var root yaml.Node
+		if err := dec.Decode(&root); errors.Is(err, io.EOF) {
+			return out, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(root.Content) == 0 || isNull(root.Content[0]) {
+			continue
+		}
+		if _, ok := opts.kindSelected(root.Content[0]); !ok {
+			continue
+		}
+		findings, _ := validateDocument(root.Content[0], &opts)
+		out = append(out, findings...)
+	}
+}
@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// getField returns the value node stored under key in mapping m, or nil
+// when m is not a mapping or has no such key.
+func getField(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// isNull reports whether n is absent or an explicit YAML null.
+func isNull(n *yaml.Node) bool {
+	return n == nil || (n.Kind == yaml.ScalarNode && n.Tag == "!!null")
+}
+
+// intNumeral matches plain integer literals, including ones too large
+// for yaml.v3 to resolve as !!int (it tags those !!float).
+var intNumeral = regexp.MustCompile(`^[-+]?[0-9][0-9_]*$`)
+
+// parseInt interprets scalar n as an integer of the given bit size.
+// Numerals that are integers but do not fit report errRange; anything
+// that is not an integer literal reports errNotInt.
+func parseInt(n *yaml.Node, bitSize int) (int64, error) {
+	if n.Kind != yaml.ScalarNode {
+		return 0, errNotInt
+	}
+	switch {
+	case n.Tag == "!!int":
+	case n.Tag == "!!float" && intNumeral.MatchString(n.Value):
+	default:
+		return 0, errNotInt
+	}
+	v, err := strconv.ParseInt(strings.ReplaceAll(n.Value, "_", ""), 0, bitSize)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, errRange
+	}
+	if err != nil {
+		return 0, errNotInt
+	}
+	return v, nil
+}
+
+var (
+	errNotInt = errors.New("not an integer")
+	errRange  = errors.New("out of range")
+)
+
+// maxDescribedValue caps how much of a scalar describeNode quotes.
+const maxDescribedValue = 40
+
+// describeNode renders what n actually is for type-mismatch messages:
+// "int '123'", "mapping", "sequence", "null" or "alias '*base' to mapping".
+func describeNode(n *yaml.Node) string {
+	if n == nil {
+		return "nothing"
+	}
+	switch n.Kind {
+	case yaml.AliasNode:
+		if n.Alias == nil {
+			return "alias '*" + n.Value + "'"
+		}
+		return "alias '*" + n.Value + "' to " + describeNode(n.Alias)
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.DocumentNode:
+		return "document"
+	}
+	tag := strings.TrimPrefix(n.ShortTag(), "!!")
+	switch tag {
+	case "null":
+		return "null"
+	case "str":
+		tag = "string"
+	}
+	v := n.Value
+	if r := []rune(v); len(r) > maxDescribedValue {
+		v = string(r[:maxDescribedValue]) + "…"
+	}
+	if i := strings.IndexByte(v, '\n'); i >= 0 {
+		v = v[:i] + "…"
+	}
+	return tag + " '" + v + "'"
+}
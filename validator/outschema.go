@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// OutputSchemaID identifies the JSON Schema of the findings document.
+// The trailing version changes whenever the format changes in a way
+// that existing consumers could not read.
+const OutputSchemaID = "https://github.com/abdddev/go-magistr-lesson2-tpl/schemas/findings/v3.json"
+
+// readableSchemas lists the earlier schema IDs whose reports
+// ReadJSONReport still reads: v2 marked a capped run on its last
+// finding rather than at the top level, which ReadJSONReport never
+// relied on.
+var readableSchemas = []string{"https://github.com/abdddev/go-magistr-lesson2-tpl/schemas/findings/v2.json"}
+
+// OutputSchema returns a JSON Schema for the CLI's --format=json
+// output: an object holding the findings and a summary of the run. It
+// is derived by reflection from the types the output is marshalled
+// with, so the two cannot drift apart.
+func OutputSchema() map[string]any {
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     OutputSchemaID,
+		"title":   "Validation findings",
+		"type":    "object",
+		"properties": map[string]any{
+			"schema": map[string]any{"const": OutputSchemaID},
+			"findings": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"$ref": "#/$defs/finding"},
+				"description": "Every finding of one run, in input order and then position order.",
+			},
+			"resources": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"$ref": "#/$defs/resource"},
+				"description": "The documents that validated without errors, in input order. Absent when there are none.",
+			},
+			"file": map[string]any{
+				"type":        "string",
+				"description": "The input, in the document of a single validator.Result only.",
+			},
+			"documents": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"$ref": "#/$defs/resource"},
+				"description": "Every document of the input, valid or not, in the document of a single validator.Result only.",
+			},
+			"summary": map[string]any{"$ref": "#/$defs/summary"},
+			"truncated": map[string]any{
+				"const":       true,
+				"description": "Set when a cap on the number of findings left some out; summary.omitted counts them.",
+			},
+		},
+		"required":             []string{"schema", "findings", "summary"},
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"finding":  objectSchema(reflect.TypeFor[findingJSON]()),
+			"resource": objectSchema(reflect.TypeFor[resourceJSON]()),
+			"summary":  objectSchema(reflect.TypeFor[summaryJSON]()),
+		},
+	}
+}
+
+// enumValues lists the spellings of the types marshalled as fixed sets
+// of strings.
+var enumValues = map[reflect.Type]func() []string{
+	reflect.TypeFor[Severity](): func() []string {
+		return []string{SeverityError.String(), SeverityWarning.String()}
+	},
+	reflect.TypeFor[Category](): func() []string { return slices.Sorted(maps.Values(categoryNames)) },
+}
+
+// objectSchema describes struct type t from its json tags. Fields
+// without omitempty are required.
+func objectSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		props[name] = typeSchema(f.Type)
+		if opts != "omitempty" {
+			required = append(required, name)
+		}
+	}
+	if p, ok := props["cause"].(map[string]any); ok {
+		p["enum"] = slices.Sorted(maps.Values(sentinelNames))
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	if values, ok := enumValues[t]; ok {
+		return map[string]any{"type": "string", "enum": values()}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	}
+	panic("validator: no JSON Schema for " + t.String())
+}
@@ -0,0 +1,159 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOutputMatchesSchema(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(mustJSON(t, OutputSchema()), &schema); err != nil {
+		t.Fatal(err)
+	}
+	invalid := mustValidate(t, "pod.yaml", twoContainerPod, Options{})
+	valid := mustValidate(t, "valid.yaml", validPod+"---\n"+validPod, Options{})
+	report := func(omitted int, results ...*Result) []byte {
+		var buf bytes.Buffer
+		r := NewJSONReporter(&buf)
+		var stats Stats
+		for _, res := range results {
+			for _, e := range res.Findings {
+				r.Report(e)
+			}
+			for _, d := range res.ValidDocuments() {
+				r.Resource(Resource{File: res.File, Document: d})
+			}
+			stats.Count(res)
+		}
+		stats.Omitted = omitted
+		if err := r.Summary(stats); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	tests := []struct {
+		name string
+		doc  []byte
+		want []string // the violations, or nil for a document that conforms
+	}{
+		{"findings", report(0, invalid), nil},
+		{"resources", report(0, valid), nil},
+		{"findings and resources", report(0, invalid, valid), nil},
+		{"truncated", report(3, invalid), nil},
+		{"single result", mustJSON(t, invalid), nil},
+		{"checker rejects an unknown severity",
+			bytes.Replace(report(0, invalid), []byte(`"severity": "error"`), []byte(`"severity": "fatal"`), 1),
+			[]string{`findings[0].severity: "fatal" is not one of [error warning]`}},
+		{"checker rejects a missing summary",
+			[]byte(`{"schema":"` + OutputSchemaID + `","findings":[]}`),
+			[]string{"summary is required"}},
+		{"checker rejects an unknown property",
+			bytes.Replace(report(0, valid), []byte(`"summary":`), []byte(`"extra":1,"summary":`), 1),
+			[]string{"extra is not allowed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+			if err := json.Unmarshal(tt.doc, &doc); err != nil {
+				t.Fatal(err)
+			}
+			got := checkSchema(schema, schema, doc, "")
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("violations %q, want %q\ndocument: %s", got, tt.want, tt.doc)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// checkSchema returns the ways v, found at path, violates schema. It
+// knows the keywords OutputSchema uses, with $ref resolved in root.
+func checkSchema(root, schema map[string]any, v any, path string) []string {
+	at := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	name := path
+	if name == "" {
+		name = "document"
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		def := root
+		for _, seg := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			def = def[seg].(map[string]any)
+		}
+		return checkSchema(root, def, v, path)
+	}
+	var out []string
+	if c, ok := schema["const"]; ok && c != v {
+		out = append(out, fmt.Sprintf("%s: %v is not %v", name, v, c))
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, v) {
+		out = append(out, fmt.Sprintf("%s: %q is not one of %v", name, v, enum))
+	}
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return append(out, name+" is not an object")
+		}
+		props, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, r := range required {
+			if _, ok := obj[r.(string)]; !ok {
+				out = append(out, at(r.(string))+" is required")
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(obj)) {
+			p, ok := props[key].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					out = append(out, at(key)+" is not allowed")
+				}
+				continue
+			}
+			out = append(out, checkSchema(root, p, obj[key], at(key))...)
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return append(out, name+" is not an array")
+		}
+		for i, it := range items {
+			out = append(out, checkSchema(root, schema["items"].(map[string]any), it, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			out = append(out, name+" is not a string")
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			out = append(out, name+" is not a boolean")
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return append(out, name+" is not an integer")
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			out = append(out, fmt.Sprintf("%s: %v is less than %v", name, n, min))
+		}
+	}
+	return out
+}
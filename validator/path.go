@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrBadSelector is wrapped by errors about Options.Select expressions
+// that do not parse or do not resolve against the document.
+var ErrBadSelector = errors.New("invalid selector")
+
+// pathSegment is one step of a path expression: a mapping key, a
+// sequence index, or a [key=value] match on a sequence of mappings.
+type pathSegment struct {
+	key        string
+	index      int
+	wildcard   bool
+	matchKey   string
+	matchValue string
+}
+
+func (s pathSegment) isIndex() bool { return s.key == "" && s.matchKey == "" && !s.wildcard }
+
+// parsePath parses expressions such as "spec.containers[2].ports" or
+// "spec.containers[name=web].image".
+func parsePath(expr string) ([]pathSegment, error) {
+	var segs []pathSegment
+	rest := expr
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w %q: unterminated '['", ErrBadSelector, expr)
+			}
+			inner := rest[1:end]
+			if inner == "*" {
+				segs = append(segs, pathSegment{wildcard: true})
+			} else if k, v, ok := strings.Cut(inner, "="); ok {
+				if k == "" {
+					return nil, fmt.Errorf("%w %q: empty key in [%s]", ErrBadSelector, expr, inner)
+				}
+				segs = append(segs, pathSegment{matchKey: k, matchValue: v})
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("%w %q: bad index [%s]", ErrBadSelector, expr, inner)
+				}
+				segs = append(segs, pathSegment{index: i})
+			}
+			rest = rest[end+1:]
+		case rest[0] == '.':
+			rest = rest[1:]
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return nil, fmt.Errorf("%w %q: empty key", ErrBadSelector, expr)
+			}
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segs = append(segs, pathSegment{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("%w: empty expression", ErrBadSelector)
+	}
+	return segs, nil
+}
+
+// resolvePath walks segs from doc and returns the node reached plus the
+// canonical field path of that node, with every list step written the
+// way itemPath writes it so it can be compared against finding fields.
+func resolvePath(doc *yaml.Node, expr string) (*yaml.Node, string, error) {
+	segs, err := parsePath(expr)
+	if err != nil {
+		return nil, "", err
+	}
+	n, path := doc, ""
+	for _, s := range segs {
+		switch {
+		case s.wildcard:
+			return nil, "", fmt.Errorf("%w %q: [*] is not allowed here", ErrBadSelector, expr)
+		case s.key != "":
+			next := getField(n, s.key)
+			if next == nil {
+				return nil, "", fmt.Errorf("%w %q: no key %q at %s (available: %s)",
+					ErrBadSelector, expr, s.key, displayPath(path), strings.Join(mappingKeys(n), ", "))
+			}
+			n, path = next, joinKey(path, s.key)
+		case s.isIndex():
+			if n.Kind != yaml.SequenceNode || s.index >= len(n.Content) {
+				return nil, "", fmt.Errorf("%w %q: no index [%d] at %s (%s)",
+					ErrBadSelector, expr, s.index, displayPath(path), describeLen(n))
+			}
+			n, path = n.Content[s.index], itemPath(path, n, s.index)
+		default:
+			i := matchItem(n, s.matchKey, s.matchValue)
+			if i < 0 {
+				return nil, "", fmt.Errorf("%w %q: no item with %s=%s at %s (available: %s)",
+					ErrBadSelector, expr, s.matchKey, s.matchValue, displayPath(path),
+					strings.Join(itemValues(n, s.matchKey), ", "))
+			}
+			n, path = n.Content[i], itemPath(path, n, i)
+		}
+	}
+	return n, path, nil
+}
+
+func matchItem(seq *yaml.Node, key, value string) int {
+	if seq.Kind != yaml.SequenceNode {
+		return -1
+	}
+	for i, item := range seq.Content {
+		if v := getField(item, key); v != nil && v.Kind == yaml.ScalarNode && v.Value == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func mappingKeys(m *yaml.Node) []string {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return []string{"none, not a mapping"}
+	}
+	var keys []string
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		keys = append(keys, m.Content[i].Value)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func itemValues(seq *yaml.Node, key string) []string {
+	if seq.Kind != yaml.SequenceNode {
+		return []string{"none, not a sequence"}
+	}
+	var vals []string
+	for _, item := range seq.Content {
+		if v := getField(item, key); v != nil && v.Kind == yaml.ScalarNode {
+			vals = append(vals, key+"="+v.Value)
+		}
+	}
+	return vals
+}
+
+func describeLen(n *yaml.Node) string {
+	if n.Kind != yaml.SequenceNode {
+		return "not a sequence"
+	}
+	return fmt.Sprintf("%d items", len(n.Content))
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "document root"
+	}
+	return path
+}
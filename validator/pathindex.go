@@ -0,0 +1,228 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxPathEntries caps the size of a PathIndex. Aliases are expanded, so
+// a small document that aliases large anchors many times could
+// otherwise produce an index far larger than its source.
+const maxPathEntries = 1 << 20
+
+// PathEntry locates one node of a document.
+type PathEntry struct {
+	// Path is the canonical field path, written the way findings write
+	// their Field.
+	Path string
+	// JSONPath is the path in JSONPath notation with an index for every
+	// sequence step, such as "$.spec.containers[2].image".
+	JSONPath string
+	// Line and Column are the position of the node. An alias is located
+	// where it is written; what lies below it, merged keys included,
+	// only exists at the anchor and is located there.
+	Line, Column int
+	// Value is the scalar value; it is empty for mappings and sequences.
+	Value string
+	// Scalar tells an empty scalar from a mapping or sequence.
+	Scalar bool
+}
+
+// PathIndex maps the field paths of one document to their positions, so
+// that tools can point at a field of a manifest whether or not it
+// validates. Aliases and merge keys are expanded the way a decoder sees
+// them.
+type PathIndex struct {
+	doc     *yaml.Node
+	entries []PathEntry // in document order
+	byPath  map[string]int
+}
+
+// NewPathIndex indexes doc, a document or its top-level node.
+func NewPathIndex(doc *yaml.Node) *PathIndex {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	x := &PathIndex{doc: doc, byPath: make(map[string]int)}
+	x.add(doc, doc, "", "$")
+	return x
+}
+
+// IndexBytes parses the document in data, read from the input called
+// name, and indexes it. It fails like Validate on inputs that are not a
+// YAML document.
+func IndexBytes(name string, data []byte) (*PathIndex, error) {
+	data, err := toUTF8(name, data)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", name, ErrNotYAML, explainParseError(data, 1, err))
+	}
+	if len(root.Content) == 0 || isNull(root.Content[0]) {
+		return nil, fmt.Errorf("%s: %w", name, ErrEmptyDocument)
+	}
+	return NewPathIndex(&root), nil
+}
+
+// add records n, reached at path (jp in JSONPath) and written at pos,
+// and everything below it.
+func (x *PathIndex) add(n, pos *yaml.Node, path, jp string) {
+	if len(x.entries) >= maxPathEntries {
+		return
+	}
+	n = deref(n)
+	if path != "" {
+		e := PathEntry{Path: path, JSONPath: jp, Line: pos.Line, Column: pos.Column}
+		if n.Kind == yaml.ScalarNode {
+			e.Value, e.Scalar = n.Value, true
+		}
+		x.byPath[path] = len(x.entries)
+		x.entries = append(x.entries, e)
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for _, kv := range mergedPairs(n) {
+			x.add(kv[1], kv[1], joinKey(path, kv[0].Value), jsonMember(jp, kv[0].Value))
+		}
+	case yaml.SequenceNode:
+		for i, it := range n.Content {
+			x.add(it, it, itemPath(path, n, i), jp+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+// Lookup returns the entry at expr, a path such as
+// "spec.containers[0].image" or "spec.containers[name=web].image".
+func (x *PathIndex) Lookup(expr string) (PathEntry, error) {
+	path, err := x.canonical(expr)
+	if err != nil {
+		return PathEntry{}, err
+	}
+	i, ok := x.byPath[path]
+	if !ok {
+		return PathEntry{}, fmt.Errorf("%w %q: too deep in an expanded document", ErrBadSelector, expr)
+	}
+	return x.entries[i], nil
+}
+
+// ListPaths returns the canonical paths at and below prefix in document
+// order; an empty prefix lists every path.
+func (x *PathIndex) ListPaths(prefix string) ([]string, error) {
+	if prefix != "" {
+		var err error
+		if prefix, err = x.canonical(prefix); err != nil {
+			return nil, err
+		}
+	}
+	var out []string
+	for _, e := range x.entries {
+		if prefix == "" || e.Path == prefix || strings.HasPrefix(e.Path, prefix+".") || strings.HasPrefix(e.Path, prefix+"[") {
+			out = append(out, e.Path)
+		}
+	}
+	return out, nil
+}
+
+// canonical resolves expr against the document like resolvePath, but
+// through aliases and merge keys, and returns the path add recorded.
+func (x *PathIndex) canonical(expr string) (string, error) {
+	segs, err := parsePath(expr)
+	if err != nil {
+		return "", err
+	}
+	n, path := x.doc, ""
+	for _, s := range segs {
+		n = deref(n)
+		switch {
+		case s.wildcard:
+			return "", fmt.Errorf("%w %q: [*] is not allowed here", ErrBadSelector, expr)
+		case s.key != "":
+			var next *yaml.Node
+			if n.Kind == yaml.MappingNode {
+				for _, kv := range mergedPairs(n) {
+					if kv[0].Value == s.key {
+						next = kv[1]
+					}
+				}
+			}
+			if next == nil {
+				return "", fmt.Errorf("%w %q: no key %q at %s", ErrBadSelector, expr, s.key, displayPath(path))
+			}
+			n, path = next, joinKey(path, s.key)
+		case s.isIndex():
+			if n.Kind != yaml.SequenceNode || s.index >= len(n.Content) {
+				return "", fmt.Errorf("%w %q: no index [%d] at %s (%s)",
+					ErrBadSelector, expr, s.index, displayPath(path), describeLen(n))
+			}
+			n, path = n.Content[s.index], itemPath(path, n, s.index)
+		default:
+			i := -1
+			if n.Kind == yaml.SequenceNode {
+				for j, it := range n.Content {
+					if v := deref(getField(deref(it), s.matchKey)); v != nil && v.Kind == yaml.ScalarNode && v.Value == s.matchValue {
+						i = j
+						break
+					}
+				}
+			}
+			if i < 0 {
+				return "", fmt.Errorf("%w %q: no item with %s=%s at %s", ErrBadSelector, expr, s.matchKey, s.matchValue, displayPath(path))
+			}
+			n, path = n.Content[i], itemPath(path, n, i)
+		}
+	}
+	return path, nil
+}
+
+// deref follows n to the node it aliases.
+func deref(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// mergedPairs returns the key/value pairs of mapping m with "<<" merge
+// keys expanded in place. Keys written in m win over merged ones, and
+// earlier merged mappings over later ones, as in the YAML merge spec.
+func mergedPairs(m *yaml.Node) [][2]*yaml.Node {
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Tag != "!!merge" {
+			explicit[m.Content[i].Value] = true
+		}
+	}
+	seen := make(map[string]bool)
+	var out [][2]*yaml.Node
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if k.Tag != "!!merge" {
+			if !seen[k.Value] {
+				seen[k.Value] = true
+				out = append(out, [2]*yaml.Node{k, v})
+			}
+			continue
+		}
+		sources := []*yaml.Node{deref(v)}
+		if sources[0].Kind == yaml.SequenceNode {
+			sources = sources[0].Content
+		}
+		for _, src := range sources {
+			if src = deref(src); src.Kind != yaml.MappingNode {
+				continue
+			}
+			for _, kv := range mergedPairs(src) {
+				if !explicit[kv[0].Value] && !seen[kv[0].Value] {
+					seen[kv[0].Value] = true
+					out = append(out, kv)
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// indexedDoc has nested sequences, a merge key and aliases.
+const indexedDoc = `base: &base
+  image: nginx:1.25
+  ports:
+  - containerPort: 80
+spec:
+  containers:
+  - name: web
+    <<: *base
+    image: nginx:1.27
+  - name: sidecar
+    <<: *base
+    env:
+    - name: A
+      value: x
+  volumes: &vols
+  - name: data
+    emptyDir: {}
+copy: *vols
+`
+
+func TestPathIndexLookup(t *testing.T) {
+	x, err := IndexBytes("doc.yaml", []byte(indexedDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want PathEntry
+	}{
+		{"spec.containers[0].name", PathEntry{Path: "spec.containers[name=web].name", JSONPath: "$.spec.containers[0].name",
+			Line: 7, Column: 11, Value: "web", Scalar: true}},
+		{"spec.containers[name=web].image", PathEntry{Path: "spec.containers[name=web].image", JSONPath: "$.spec.containers[0].image",
+			Line: 9, Column: 12, Value: "nginx:1.27", Scalar: true}},
+		// Merged keys only exist at the anchor.
+		{"spec.containers[1].image", PathEntry{Path: "spec.containers[name=sidecar].image", JSONPath: "$.spec.containers[1].image",
+			Line: 2, Column: 10, Value: "nginx:1.25", Scalar: true}},
+		{"spec.containers[name=sidecar].ports[0].containerPort", PathEntry{Path: "spec.containers[name=sidecar].ports[0].containerPort",
+			JSONPath: "$.spec.containers[1].ports[0].containerPort", Line: 4, Column: 20, Value: "80", Scalar: true}},
+		{"spec.containers[1].env[0].value", PathEntry{Path: "spec.containers[name=sidecar].env[0].value",
+			JSONPath: "$.spec.containers[1].env[0].value", Line: 14, Column: 14, Value: "x", Scalar: true}},
+		{"spec.containers", PathEntry{Path: "spec.containers", JSONPath: "$.spec.containers", Line: 7, Column: 3}},
+		// An alias is located where it is written, what lies below it
+		// at the anchor.
+		{"copy", PathEntry{Path: "copy", JSONPath: "$.copy", Line: 18, Column: 7}},
+		{"copy[0].name", PathEntry{Path: "copy[0].name", JSONPath: "$.copy[0].name", Line: 16, Column: 11, Value: "data", Scalar: true}},
+		{"spec.volumes[0].emptyDir", PathEntry{Path: "spec.volumes[0].emptyDir", JSONPath: "$.spec.volumes[0].emptyDir", Line: 17, Column: 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := x.Lookup(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Lookup = %+v\nwant     %+v", got, tt.want)
+			}
+		})
+	}
+	for _, expr := range []string{"spec.containers[2]", "spec.containers[name=db]", "spec.nope", "spec.containers[0].name.x", "spec[0]", ""} {
+		if _, err := x.Lookup(expr); !errors.Is(err, ErrBadSelector) {
+			t.Errorf("Lookup(%q) err = %v, want ErrBadSelector", expr, err)
+		}
+	}
+}
+
+func TestPathIndexListPaths(t *testing.T) {
+	x, err := IndexBytes("doc.yaml", []byte(indexedDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		// The merged keys come where the merge key is written, less
+		// those the mapping sets itself.
+		{"spec.containers[name=web]", []string{
+			"spec.containers[name=web]",
+			"spec.containers[name=web].name",
+			"spec.containers[name=web].ports",
+			"spec.containers[name=web].ports[0]",
+			"spec.containers[name=web].ports[0].containerPort",
+			"spec.containers[name=web].image",
+		}},
+		{"spec.containers[1].env", []string{
+			"spec.containers[name=sidecar].env",
+			"spec.containers[name=sidecar].env[0]",
+			"spec.containers[name=sidecar].env[0].name",
+			"spec.containers[name=sidecar].env[0].value",
+		}},
+		{"copy", []string{"copy", "copy[0]", "copy[0].name", "copy[0].emptyDir"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, err := x.ListPaths(tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListPaths = %q\nwant        %q", got, tt.want)
+			}
+		})
+	}
+	all, err := x.ListPaths("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) == 0 || all[0] != "base" || all[len(all)-1] != "copy[0].emptyDir" {
+		t.Errorf("ListPaths(\"\") = %q, want every path in document order", all)
+	}
+}
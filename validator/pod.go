@@ -0,0 +1,379 @@
+package validator
+
+import "gopkg.in/yaml.v3"
+
+// validatePod is the KindValidator for v1 Pods.
+func validatePod(doc *yaml.Node, h Helpers, report ReportFunc) {
+	c := h.checker(report)
+	c.namespace, c.labels = documentScope(doc)
+	c.metadata(doc, "metadata", objectMetadata)
+	c.requiredFields(doc, "")
+	spec := getField(doc, "spec")
+	if isNull(spec) {
+		report(required("spec", doc))
+		return
+	}
+	if spec.Kind != yaml.MappingNode {
+		report(typeMismatch("spec", spec, "object"))
+		return
+	}
+	c.podSpec(spec, "spec")
+}
+
+// Rule IDs of the warnings about pods that work but are fragile.
+const (
+	ruleHostPort    = "host-port"
+	ruleEmptyLabels = "empty-labels"
+)
+
+// metadataContext tells metadata which rules apply.
+type metadataContext int
+
+const (
+	// objectMetadata is the metadata of a document, which must be named.
+	objectMetadata metadataContext = iota
+	// templateMetadata is the metadata of a pod template. The controller
+	// names the pods it creates, so name and namespace must not be set,
+	// and the labels must be, for the selector to match.
+	templateMetadata
+)
+
+// metadata checks the metadata mapping of parent, found at path.
+func (c *checker) metadata(parent *yaml.Node, path string, ctx metadataContext) {
+	meta := getField(parent, "metadata")
+	if isNull(meta) {
+		if ctx == templateMetadata {
+			c.report(requiredKey(joinKey(path, "labels"), "metadata", parent))
+		} else {
+			c.report(required(path, parent))
+		}
+		return
+	}
+	if meta.Kind != yaml.MappingNode {
+		c.report(typeMismatch(path, meta, "object"))
+		return
+	}
+	if ctx == templateMetadata {
+		for _, key := range []string{"name", "namespace"} {
+			if n := getField(meta, key); !isNull(n) {
+				c.report(crossField(joinKey(path, key), n, "must not be set in a pod template; the controller sets it on the pods it creates"))
+			}
+		}
+		if n := getField(meta, "generateName"); !isNull(n) {
+			w := crossField(joinKey(path, "generateName"), n, "is ignored in a pod template")
+			w.Severity = SeverityWarning
+			c.report(w)
+		}
+		if isNull(getField(meta, "labels")) {
+			c.report(required(joinKey(path, "labels"), meta))
+		}
+	} else {
+		c.requireName(meta, "name", path, maxDNSSubdomain)
+	}
+	c.annotationsDeprecated(meta, path)
+	lim := c.opts.limits()
+	for _, l := range []struct {
+		key, rule string
+		max       int
+	}{{"labels", ruleLimitLabels, lim.Labels}, {"annotations", ruleLimitAnnotations, lim.Annotations}} {
+		if m := c.optionalMapping(meta, l.key, path); m != nil {
+			c.stringMap(m, joinKey(path, l.key))
+			c.limit(l.rule, joinKey(path, l.key), m, l.max, l.key)
+		}
+	}
+	if m := getField(meta, "labels"); m != nil && m.Kind == yaml.MappingNode && len(m.Content) == 0 {
+		w := newError(CategoryFormat, joinKey(path, "labels"), m, "is empty, so no selector can match this object")
+		w.Rule, w.Severity = ruleEmptyLabels, SeverityWarning
+		c.report(w)
+	}
+}
+
+func (c *checker) podSpec(spec *yaml.Node, path string) {
+	for _, key := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		c.optionalBool(spec, key, path)
+	}
+	containersPath := joinKey(path, "containers")
+	containers := getField(spec, "containers")
+	if isNull(containers) {
+		c.report(required(containersPath, spec))
+		return
+	}
+	if containers.Kind != yaml.SequenceNode {
+		c.report(typeMismatch(containersPath, containers, "array"))
+		return
+	}
+	c.hostNetwork = isTrue(getField(spec, "hostNetwork"))
+	lim := c.opts.limits()
+	c.limit(ruleLimitContainers, containersPath, containers, lim.Containers, "containers")
+	for i, ctr := range containers.Content {
+		c.container(ctr, itemPath(containersPath, containers, i))
+	}
+	var inits *yaml.Node
+	if inits = c.optionalSequence(spec, "initContainers", path); inits != nil {
+		c.limit(ruleLimitContainers, joinKey(path, "initContainers"), inits, lim.Containers, "containers")
+		for i, ctr := range inits.Content {
+			c.container(ctr, itemPath(joinKey(path, "initContainers"), inits, i))
+		}
+	}
+	c.crossContainer(path, containers, inits)
+	for i, v := range indexedItems(spec, "volumes") {
+		if v != nil {
+			c.requireName(v, "name", joinIndex(joinKey(path, "volumes"), i), maxDNSLabel)
+		}
+	}
+	c.podSpecUnique(spec, path)
+	c.windowsPod(spec, path)
+}
+
+// podContainer is a container item together with its field path.
+type podContainer struct {
+	node *yaml.Node
+	path string
+}
+
+// crossContainer runs the checks that need every container of the pod
+// at once: duplicate names and host port conflicts.
+func (c *checker) crossContainer(path string, containers, inits *yaml.Node) {
+	var all, running []podContainer
+	if inits != nil {
+		for i, ctr := range inits.Content {
+			pc := podContainer{ctr, itemPath(joinKey(path, "initContainers"), inits, i)}
+			all = append(all, pc)
+			// Sidecars keep running next to the main containers, so
+			// only they can collide with them on the host.
+			if rp := getField(ctr, "restartPolicy"); rp != nil && rp.Value == "Always" {
+				running = append(running, pc)
+			}
+		}
+	}
+	for i, ctr := range containers.Content {
+		pc := podContainer{ctr, itemPath(joinKey(path, "containers"), containers, i)}
+		all = append(all, pc)
+		running = append(running, pc)
+	}
+
+	var names []keyedItem
+	for _, pc := range all {
+		if n := getField(pc.node, "name"); n != nil && n.Kind == yaml.ScalarNode {
+			names = append(names, scalarItem(joinKey(pc.path, "name"), n))
+		}
+	}
+	c.unique(ruleUniqueContainerName, names)
+
+	var used []hostPortUse
+	for _, pc := range running {
+		ports := getField(pc.node, "ports")
+		if ports == nil || ports.Kind != yaml.SequenceNode {
+			continue
+		}
+		for i, p := range ports.Content {
+			u, ok := hostPortOf(p, joinIndex(joinKey(pc.path, "ports"), i), c.hostNetwork)
+			if !ok {
+				continue
+			}
+			for _, prev := range used {
+				if prev.conflicts(u) {
+					c.report(crossField(u.field, u.node, "conflicts with %s: both bind %s/%d on the host%s",
+						prev.field, u.protocol, u.port, u.reason(prev)))
+					break
+				}
+			}
+			used = append(used, u)
+		}
+	}
+}
+
+// hostPortUse is a port bound on the node by one container port.
+type hostPortUse struct {
+	field    string
+	node     *yaml.Node
+	port     int64
+	protocol string
+	hostIP   string
+	implied  bool // bound through hostNetwork rather than hostPort
+}
+
+// hostPortOf returns the host binding of port item p. With hostNetwork
+// every containerPort is bound on the host.
+func hostPortOf(p *yaml.Node, path string, hostNetwork bool) (hostPortUse, bool) {
+	key := "hostPort"
+	n := getField(p, key)
+	if isNull(n) {
+		if !hostNetwork {
+			return hostPortUse{}, false
+		}
+		key = "containerPort"
+		n = getField(p, key)
+		if isNull(n) {
+			return hostPortUse{}, false
+		}
+	}
+	port, err := parseInt(n, 32)
+	if err != nil {
+		return hostPortUse{}, false
+	}
+	u := hostPortUse{field: joinKey(path, key), node: n, port: port, protocol: "TCP", implied: key == "containerPort"}
+	if pr := getField(p, "protocol"); pr != nil && pr.Kind == yaml.ScalarNode {
+		u.protocol = pr.Value
+	}
+	if ip := getField(p, "hostIP"); ip != nil && ip.Kind == yaml.ScalarNode {
+		u.hostIP = ip.Value
+	}
+	return u, true
+}
+
+func (u hostPortUse) conflicts(o hostPortUse) bool {
+	if u.port != o.port || u.protocol != o.protocol {
+		return false
+	}
+	return isWildcardIP(u.hostIP) || isWildcardIP(o.hostIP) || u.hostIP == o.hostIP
+}
+
+func (u hostPortUse) reason(prev hostPortUse) text {
+	if u.implied || prev.implied {
+		return textf(" (hostNetwork is true)")
+	}
+	return text{}
+}
+
+func isWildcardIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "::"
+}
+
+func (c *checker) container(ctr *yaml.Node, path string) {
+	if ctr.Kind != yaml.MappingNode {
+		c.report(typeMismatch(path, ctr, "object"))
+		return
+	}
+	if n := c.requireName(ctr, "name", path, maxDNSLabel); n != nil {
+		c.dnsLabel(n, joinKey(path, "name"))
+	}
+	if img := c.requireString(ctr, "image", path); img != nil && c.imageFormat(img, joinKey(path, "image")) {
+		c.imageAllowed(img, joinKey(path, "image"))
+		c.imageTag(img, joinKey(path, "image"))
+		c.imageLocked(img, joinKey(path, "image"))
+	}
+	for _, key := range []string{"stdin", "stdinOnce", "tty"} {
+		c.optionalBool(ctr, key, path)
+	}
+	if sc := c.optionalMapping(ctr, "securityContext", path); sc != nil {
+		scPath := joinKey(path, "securityContext")
+		for _, key := range []string{"privileged", "allowPrivilegeEscalation", "readOnlyRootFilesystem", "runAsNonRoot"} {
+			c.optionalBool(sc, key, scPath)
+		}
+	}
+	if ports := c.optionalSequence(ctr, "ports", path); ports != nil {
+		portsPath := joinKey(path, "ports")
+		c.limit(ruleLimitPorts, portsPath, ports, c.opts.limits().PortsPerContainer, "portsPerContainer")
+		for i, p := range ports.Content {
+			c.containerPort(p, joinIndex(portsPath, i))
+		}
+	}
+	if env := c.optionalSequence(ctr, "env", path); env != nil {
+		envPath := joinKey(path, "env")
+		c.limit(ruleLimitEnv, envPath, env, c.opts.limits().EnvPerContainer, "envPerContainer")
+		for i, e := range env.Content {
+			c.envVar(e, joinIndex(envPath, i))
+		}
+	}
+	c.handlers(ctr, path)
+	if res := c.optionalMapping(ctr, "resources", path); res != nil {
+		c.resources(res, joinKey(path, "resources"))
+	}
+	if mounts := c.optionalSequence(ctr, "volumeMounts", path); mounts != nil {
+		mountsPath := joinKey(path, "volumeMounts")
+		for i, m := range mounts.Content {
+			mPath := joinIndex(mountsPath, i)
+			if m.Kind != yaml.MappingNode {
+				c.report(typeMismatch(mPath, m, "object"))
+				continue
+			}
+			c.optionalBool(m, "readOnly", mPath)
+		}
+		c.unique(ruleUniqueMountPath, itemsByKey(indexedItems(ctr, "volumeMounts"), mountsPath, "mountPath"))
+	}
+}
+
+var protocols = map[string]bool{"TCP": true, "UDP": true, "SCTP": true}
+
+func (c *checker) containerPort(p *yaml.Node, path string) {
+	if p.Kind != yaml.MappingNode {
+		c.report(typeMismatch(path, p, "object"))
+		return
+	}
+	if n := getField(p, "containerPort"); isNull(n) {
+		c.report(required(joinKey(path, "containerPort"), p))
+	} else {
+		c.requireIntRange(n, joinKey(path, "containerPort"), 1, 65535)
+	}
+	if n := c.optional(p, "name", path); n != nil {
+		field := joinKey(path, "name")
+		if c.stringValue(n, field, true) != nil {
+			c.nameLength(n, field, maxPortName)
+		}
+	}
+	if n := c.optional(p, "hostPort", path); n != nil {
+		if _, ok := c.requireIntRange(n, joinKey(path, "hostPort"), 1, 65535); ok {
+			w := newError(CategoryCrossField, joinKey(path, "hostPort"), n, "binds a port on the node, so only one such pod fits on each node; prefer a Service")
+			w.Rule, w.Severity = ruleHostPort, SeverityWarning
+			c.report(w)
+		}
+	}
+	if n := c.optional(p, "protocol", path); n != nil {
+		field := joinKey(path, "protocol")
+		if c.stringValue(n, field, false) != nil && !protocols[n.Value] {
+			c.report(unsupportedValue(field, n))
+		}
+	}
+}
+
+func (c *checker) envVar(e *yaml.Node, path string) {
+	if e.Kind != yaml.MappingNode {
+		c.report(typeMismatch(path, e, "object"))
+		return
+	}
+	c.requireString(e, "name", path)
+	if v := c.optional(e, "value", path); v != nil {
+		c.stringValue(v, joinKey(path, "value"), true)
+	}
+}
+
+func (c *checker) resources(res *yaml.Node, path string) {
+	parsed := map[string]map[string]Quantity{}
+	nodes := map[string]*yaml.Node{}
+	var requested []string // in document order, for stable output
+	for _, key := range []string{"limits", "requests"} {
+		m := c.optionalMapping(res, key, path)
+		if m == nil {
+			continue
+		}
+		parsed[key] = map[string]Quantity{}
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			name, v := m.Content[i].Value, m.Content[i+1]
+			field := joinKey(joinKey(path, key), name)
+			var q Quantity
+			var ok bool
+			if name == "cpu" {
+				q, ok = c.cpuQuantity(v, field)
+			} else {
+				q, ok = c.quantity(v, field)
+			}
+			if ok {
+				parsed[key][name] = q
+				nodes[key+"."+name] = v
+				if key == "requests" {
+					requested = append(requested, name)
+				}
+			}
+		}
+	}
+	for _, name := range requested {
+		req := parsed["requests"][name]
+		limit, ok := parsed["limits"][name]
+		if ok && req.Cmp(limit) > 0 {
+			n := nodes["requests."+name]
+			c.report(crossField(joinKey(joinKey(path, "requests"), name), n,
+				"must not exceed limits.%s ('%s' > '%s')", name, n.Value, nodes["limits."+name].Value))
+		}
+	}
+}
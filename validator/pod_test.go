@@ -0,0 +1,94 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContainerNameFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"web", true},
+		{"web-2", true},
+		{"0web", true},
+		{"Web", false},
+		{"web_api", false},
+		{"-web", false},
+		{"web-", false},
+		{"web.api", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := strings.Replace(validPod, "- name: web", "- name: "+tt.name, 1)
+			res := mustValidate(t, "pod.yaml", src, Options{})
+			got := len(res.Errors()) == 0
+			if got != tt.ok {
+				t.Errorf("valid = %v, want %v; findings %v", got, tt.ok, res.Findings)
+			}
+		})
+	}
+}
+
+func TestImageFormat(t *testing.T) {
+	tests := []struct {
+		image string
+		ok    bool
+	}{
+		{"nginx:1.25", true},
+		{"library/nginx:1.25", true},
+		{"registry.example.com:5000/team/app:v1.2.3", true},
+		{"ghcr.io/org/my_app__x:1", true},
+		{"nginx@sha256:" + strings.Repeat("a", 64), true},
+		{"nginx:1.25@sha256:" + strings.Repeat("0", 64), true},
+		{"NGINX:1.25", false},
+		{"nginx:::", false},
+		{"nginx:1.25 ", false},
+		{"team//app:1", false},
+		{"nginx@sha256:abc", false},
+		{"nginx:" + strings.Repeat("1", 129), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			src := strings.Replace(validPod, "image: nginx:1.25", "image: '"+tt.image+"'", 1)
+			res := mustValidate(t, "pod.yaml", src, Options{})
+			if got := len(res.Errors()) == 0; got != tt.ok {
+				t.Errorf("valid = %v, want %v; findings %v", got, tt.ok, res.Findings)
+			}
+		})
+	}
+}
+
+func TestContainerBreakingFiveRules(t *testing.T) {
+	src := `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: Web_Server
+    image: "NGINX::1"
+    stdin: "yes"
+    ports:
+    - containerPort: 70000
+      protocol: HTTP
+`
+	res := mustValidate(t, "pod.yaml", src, Options{})
+	if len(res.Findings) != 5 {
+		t.Fatalf("%d findings, want 5: %v", len(res.Findings), res.Findings)
+	}
+	fields, codes := map[string]bool{}, map[string]bool{}
+	for _, e := range res.Findings {
+		if e.Severity != SeverityError {
+			t.Errorf("%s: %s is a %s, want an error", e.Field, e.Message, e.Severity)
+		}
+		if fields[e.Field] {
+			t.Errorf("%s is reported twice", e.Field)
+		}
+		if codes[e.Code] {
+			t.Errorf("%s: code %s is reported twice", e.Field, e.Code)
+		}
+		fields[e.Field], codes[e.Code] = true, true
+	}
+}
@@ -0,0 +1,229 @@
+package validator
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	probeKinds     = []string{"livenessProbe", "readinessProbe", "startupProbe"}
+	lifecycleHooks = []string{"postStart", "preStop"}
+)
+
+// Rule IDs of the opt-in checks of numeric probe ports.
+const (
+	ruleProbePortHostNetwork = "probe-port-host-network"
+	ruleProbePortUndeclared  = "probe-port-undeclared"
+)
+
+// portUse is a handler's reference to a container port, by name or,
+// when number is set, by number.
+type portUse struct {
+	field  string
+	node   *yaml.Node
+	number int64
+}
+
+// handlers validates the probes and lifecycle hooks of container ctr
+// and checks that the port names they use are defined by ctr.
+func (c *checker) handlers(ctr *yaml.Node, path string) {
+	var uses, probeUses []portUse
+	for _, key := range probeKinds {
+		if p := c.optionalMapping(ctr, key, path); p != nil {
+			us := c.handler(p, joinKey(path, key))
+			uses, probeUses = append(uses, us...), append(probeUses, us...)
+		}
+	}
+	if lc := c.optionalMapping(ctr, "lifecycle", path); lc != nil {
+		lcPath := joinKey(path, "lifecycle")
+		for _, key := range lifecycleHooks {
+			if h := c.optionalMapping(lc, key, lcPath); h != nil {
+				uses = append(uses, c.handler(h, joinKey(lcPath, key))...)
+			}
+		}
+	}
+	c.resolvePortNames(ctr, uses)
+	c.probePortNumbers(ctr, probeUses)
+}
+
+// handler checks the httpGet and tcpSocket actions of a probe or
+// lifecycle hook and returns the ports they refer to.
+func (c *checker) handler(p *yaml.Node, path string) []portUse {
+	var uses []portUse
+	if h := c.optionalMapping(p, "httpGet", path); h != nil {
+		hPath := joinKey(path, "httpGet")
+		if n := c.requireString(h, "path", hPath); n != nil {
+			c.httpPath(n, joinKey(hPath, "path"))
+		}
+		if u, ok := c.portRef(h, hPath); ok {
+			uses = append(uses, u)
+		}
+	}
+	if t := c.optionalMapping(p, "tcpSocket", path); t != nil {
+		if u, ok := c.portRef(t, joinKey(path, "tcpSocket")); ok {
+			uses = append(uses, u)
+		}
+	}
+	return uses
+}
+
+// portRef checks the port field of a handler, which is either a port
+// number or the name of a container port. Well-formed names and valid
+// numbers are returned for resolvePortNames and probePortNumbers.
+func (c *checker) portRef(h *yaml.Node, path string) (portUse, bool) {
+	field := joinKey(path, "port")
+	n := getField(h, "port")
+	if isNull(n) {
+		c.report(required(field, h))
+		return portUse{}, false
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		if !c.nameLength(n, field, maxPortName) {
+			return portUse{}, false
+		}
+		if !portNameRe.MatchString(n.Value) || !strings.ContainsAny(n.Value, "abcdefghijklmnopqrstuvwxyz") {
+			c.report(invalidFormat(field, n))
+			return portUse{}, false
+		}
+		return portUse{field: field, node: n}, true
+	}
+	if v, ok := c.requireIntRange(n, field, 1, 65535); ok {
+		return portUse{field: field, node: n, number: v}, true
+	}
+	return portUse{}, false
+}
+
+// probePortNumbers checks the probe ports given by number against the
+// containerPorts of ctr. With hostNetwork a probe connects to the node,
+// so a number the container does not declare is most likely a port
+// that was changed in one place only; without it, a declared port is
+// better referred to by name.
+func (c *checker) probePortNumbers(ctr *yaml.Node, uses []portUse) {
+	var declared []int64
+	for _, p := range mappingItems(ctr, "ports") {
+		if n := getField(p, "containerPort"); n != nil {
+			if v, err := parseInt(n, 64); err == nil {
+				declared = append(declared, v)
+			}
+		}
+	}
+	list := textf("the container declares none")
+	if len(declared) > 0 {
+		s := make([]string, len(declared))
+		for i, v := range declared {
+			s[i] = strconv.FormatInt(v, 10)
+		}
+		list = textf("declared: %s", strings.Join(s, ", "))
+	}
+	for _, u := range uses {
+		if u.number == 0 || slices.Contains(declared, u.number) {
+			continue
+		}
+		var w *ValidationError
+		if c.hostNetwork {
+			w = crossField(u.field, u.node, "probes port %d of the node (hostNetwork is true), which is no containerPort of the container (%s)", u.number, list)
+			w.Rule = ruleProbePortHostNetwork
+		} else {
+			w = crossField(u.field, u.node, "probes port %d, which is no containerPort of the container (%s); declare it with a name and probe it by name", u.number, list)
+			w.Rule = ruleProbePortUndeclared
+		}
+		w.Severity = SeverityWarning
+		c.report(w)
+	}
+}
+
+// resolvePortNames reports each port name in uses that ctr does not
+// define, once per name at its first use, listing the names that ctr
+// does define and the other fields that use the same name.
+func (c *checker) resolvePortNames(ctr *yaml.Node, uses []portUse) {
+	var defined []string
+	for _, p := range mappingItems(ctr, "ports") {
+		if n := getField(p, "name"); n != nil && n.Kind == yaml.ScalarNode && n.Value != "" {
+			defined = append(defined, n.Value)
+		}
+	}
+	byName := make(map[string][]portUse)
+	var order []string
+	for _, u := range uses {
+		if u.number != 0 || slices.Contains(defined, u.node.Value) {
+			continue
+		}
+		if byName[u.node.Value] == nil {
+			order = append(order, u.node.Value)
+		}
+		byName[u.node.Value] = append(byName[u.node.Value], u)
+	}
+	for _, name := range order {
+		first := byName[name][0]
+		msg := textf("the container defines no named ports")
+		if len(defined) > 0 {
+			msg = textf("defined: %s", strings.Join(defined, ", "))
+		}
+		var also []string
+		for _, u := range byName[name][1:] {
+			also = append(also, u.field)
+		}
+		if len(also) > 0 {
+			msg = textf("%s; also used by %s", msg, strings.Join(also, ", "))
+		}
+		c.report(crossField(first.field, first.node, "refers to port '%s', which the container does not define (%s)", name, msg))
+	}
+}
+
+// portNameRe is the IANA service name syntax Kubernetes uses for named
+// ports: at most 15 lowercase alphanumerics or dashes.
+var portNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,13}[a-z0-9])?$`)
+
+// httpPath checks that n is an absolute request path with an optional
+// query string. Full URLs are rejected with a pointer to the fields
+// that hold the scheme, host and port.
+func (c *checker) httpPath(n *yaml.Node, field string) {
+	v := n.Value
+	u, err := url.Parse(v)
+	if err == nil && (u.Scheme != "" || u.Host != "") {
+		c.report(newError(CategoryFormat, field, n,
+			"has invalid format '%s': must be a path, not a URL; set httpGet.scheme, httpGet.host and httpGet.port instead", v))
+		return
+	}
+	if !strings.HasPrefix(v, "/") {
+		c.report(newError(CategoryFormat, field, n, "has invalid format '%s': must start with '/'", v))
+		return
+	}
+	if err != nil || u.Fragment != "" {
+		c.report(invalidFormat(field, n))
+		return
+	}
+	if bad := unencodedPathChars(v); bad != "" {
+		w := newError(CategoryFormat, field, n, "contains characters that should be percent-encoded: %s", bad)
+		w.Severity = SeverityWarning
+		c.report(w)
+	}
+}
+
+// unencodedPathChars lists, quoted, the distinct characters of a path
+// with query that RFC 3986 requires to be percent-encoded.
+func unencodedPathChars(s string) string {
+	var bad []string
+	seen := map[rune]bool{}
+	for _, r := range s {
+		if isURIChar(r) || seen[r] {
+			continue
+		}
+		seen[r] = true
+		bad = append(bad, "'"+string(r)+"'")
+	}
+	return strings.Join(bad, ", ")
+}
+
+func isURIChar(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("-._~!$&'()*+,;=:@/?%", r)
+}
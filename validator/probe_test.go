@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProbePortNumbers(t *testing.T) {
+	const (
+		port80 = "    ports:\n    - containerPort: 80\n"
+		http   = "    ports:\n    - containerPort: 80\n      name: http\n"
+	)
+	tests := []struct {
+		name        string
+		hostNetwork bool
+		container   string // appended to the container of validPod
+		ports       string // the ports of the container
+		want        string // "rule: field message", or ""
+	}{
+		{"declared port", false, "    livenessProbe:\n      httpGet: {path: /, port: 80}\n", port80, ""},
+		{"named port", false, "    livenessProbe:\n      httpGet: {path: /, port: http}\n", http, ""},
+		{"undeclared port", false, "    readinessProbe:\n      tcpSocket: {port: 8080}\n", port80,
+			"probe-port-undeclared: spec.containers[name=web].readinessProbe.tcpSocket.port probes port 8080, which is no containerPort of the container (declared: 80); declare it with a name and probe it by name"},
+		{"no ports declared", false, "    startupProbe:\n      httpGet: {path: /, port: 8080}\n", "",
+			"probe-port-undeclared: spec.containers[name=web].startupProbe.httpGet.port probes port 8080, which is no containerPort of the container (the container declares none); declare it with a name and probe it by name"},
+		{"lifecycle hooks are no probes", false, "    lifecycle:\n      preStop:\n        tcpSocket: {port: 8080}\n", port80, ""},
+		{"host network, declared port", true, "    livenessProbe:\n      httpGet: {path: /, port: 80}\n", port80, ""},
+		{"host network, undeclared port", true, "    livenessProbe:\n      httpGet: {path: /, port: 8080}\n", port80,
+			"probe-port-host-network: spec.containers[name=web].livenessProbe.httpGet.port probes port 8080 of the node (hostNetwork is true), which is no containerPort of the container (declared: 80)"},
+		{"host network, no ports declared", true, "    readinessProbe:\n      tcpSocket: {port: 9000}\n", "",
+			"probe-port-host-network: spec.containers[name=web].readinessProbe.tcpSocket.port probes port 9000 of the node (hostNetwork is true), which is no containerPort of the container (the container declares none)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := strings.Replace(validPod, port80, tt.ports, 1)
+			if tt.hostNetwork {
+				src = strings.Replace(src, "spec:\n", "spec:\n  hostNetwork: true\n", 1)
+			}
+			src += tt.container
+			for _, enabled := range []bool{false, true} {
+				opts := Options{DisableRules: []string{ruleHostPort}}
+				if enabled {
+					opts.EnableRules = []string{"probe-port"}
+				}
+				res := mustValidate(t, "pod.yaml", src, opts)
+				var got []string
+				for _, e := range res.Findings {
+					if e.Severity != SeverityWarning {
+						t.Errorf("unexpected finding %v", e)
+						continue
+					}
+					got = append(got, e.Rule+": "+e.Error())
+				}
+				want := tt.want
+				if !enabled {
+					want = "" // the rules are opt-in
+				}
+				if strings.Join(got, "\n") != want {
+					t.Errorf("enabled %t: findings %q, want %q", enabled, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a parsed Kubernetes resource quantity such as "500m",
+// "128Mi" or "1.5". It is stored exactly, in thousandths of the base
+// unit, so arbitrarily long numerals parse without overflow.
+type Quantity struct {
+	milli *big.Int
+}
+
+// MilliValue returns q in thousandths of the base unit, saturating at
+// the int64 bounds; use IsInt64 to detect that case.
+func (q Quantity) MilliValue() int64 {
+	m := q.bigMilli()
+	switch {
+	case m.IsInt64():
+		return m.Int64()
+	case m.Sign() > 0:
+		return math.MaxInt64
+	default:
+		return math.MinInt64
+	}
+}
+
+// Value returns q in base units, rounded up and saturated like
+// MilliValue.
+func (q Quantity) Value() int64 {
+	v, r := new(big.Int).QuoRem(q.bigMilli(), big.NewInt(1000), new(big.Int))
+	if r.Sign() > 0 {
+		v.Add(v, big.NewInt(1))
+	}
+	if !v.IsInt64() {
+		if v.Sign() > 0 {
+			return math.MaxInt64
+		}
+		return math.MinInt64
+	}
+	return v.Int64()
+}
+
+// IsInt64 reports whether the milli value of q fits in an int64, which
+// is the range the apiserver accepts.
+func (q Quantity) IsInt64() bool { return q.bigMilli().IsInt64() }
+
+// Cmp compares q and o and returns -1, 0 or +1.
+func (q Quantity) Cmp(o Quantity) int { return q.bigMilli().Cmp(o.bigMilli()) }
+
+func (q Quantity) bigMilli() *big.Int {
+	if q.milli == nil {
+		return new(big.Int)
+	}
+	return q.milli
+}
+
+var errQuantityRange = errors.New("quantity out of range")
+
+// maxQuantityExp bounds the decimal exponent accepted by ParseQuantity
+// so that inputs like "1e999999999" cannot allocate huge numbers.
+const maxQuantityExp = 100
+
+var quantitySuffixes = map[string]struct {
+	base, exp int64
+}{
+	"Ki": {2, 10}, "Mi": {2, 20}, "Gi": {2, 30}, "Ti": {2, 40}, "Pi": {2, 50}, "Ei": {2, 60},
+	"n": {10, -9}, "u": {10, -6}, "m": {10, -3}, "": {10, 0},
+	"k": {10, 3}, "M": {10, 6}, "G": {10, 9}, "T": {10, 12}, "P": {10, 15}, "E": {10, 18},
+}
+
+// ParseQuantity parses s using the Kubernetes quantity syntax: an
+// optionally signed decimal number followed by a binary (Ki, Mi, ...)
+// or decimal (m, k, M, ...) suffix or a decimal exponent (1e3).
+// Fractions finer than a thousandth are rounded up.
+func ParseQuantity(s string) (Quantity, error) {
+	q, _, err := parseQuantity(s)
+	return q, err
+}
+
+// parseQuantity is ParseQuantity that also reports whether s is an
+// exact number of thousandths, i.e. whether no rounding took place.
+func parseQuantity(s string) (q Quantity, exact bool, err error) {
+	num, suffix := splitQuantity(s)
+	if num == "" || strings.Trim(num, "+-.") == "" {
+		return Quantity{}, false, fmt.Errorf("invalid quantity %q", s)
+	}
+	base, exp := int64(10), int64(0)
+	if sf, ok := quantitySuffixes[suffix]; ok {
+		base, exp = sf.base, sf.exp
+	} else if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		e, err := strconv.ParseInt(suffix[1:], 10, 32)
+		if err != nil {
+			return Quantity{}, false, fmt.Errorf("invalid quantity %q", s)
+		}
+		exp = e
+	} else {
+		return Quantity{}, false, fmt.Errorf("invalid quantity %q", s)
+	}
+	if exp > maxQuantityExp || exp < -maxQuantityExp {
+		return Quantity{}, false, errQuantityRange
+	}
+
+	neg := strings.HasPrefix(num, "-")
+	num = strings.TrimLeft(num, "+-")
+	whole, frac, _ := strings.Cut(num, ".")
+	if strings.Contains(frac, ".") {
+		return Quantity{}, false, fmt.Errorf("invalid quantity %q", s)
+	}
+	// Scale the digits to an exact rational, then to thousandths.
+	digits := whole + frac
+	if digits == "" {
+		digits = "0"
+	}
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Quantity{}, false, fmt.Errorf("invalid quantity %q", s)
+	}
+	num1 := new(big.Int).Mul(n, big.NewInt(1000))
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(len(frac))), nil)
+	scale := new(big.Int).Exp(big.NewInt(base), big.NewInt(absInt64(exp)), nil)
+	if exp >= 0 {
+		num1.Mul(num1, scale)
+	} else {
+		den.Mul(den, scale)
+	}
+	milli, rem := new(big.Int).QuoRem(num1, den, new(big.Int))
+	if rem.Sign() != 0 {
+		milli.Add(milli, big.NewInt(1)) // round up like the apiserver
+	}
+	if neg {
+		milli.Neg(milli)
+	}
+	return Quantity{milli: milli}, rem.Sign() == 0, nil
+}
+
+// splitQuantity separates the numeric part of s from its suffix.
+func splitQuantity(s string) (num, suffix string) {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// SizeError reports an input that is larger than the configured limit.
+type SizeError struct {
+	Name  string
+	Limit int64
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("%s: input exceeds the maximum size of %s", e.Name, FormatSize(e.Limit))
+}
+
+func (e *SizeError) Unwrap() error { return ErrTooLarge }
+
+// ReadAll reads r until EOF, refusing to buffer more than limit bytes.
+// A limit of zero or less reads without bound. Because the limit is
+// enforced on the bytes actually read, it also holds for decompressed
+// or otherwise generated streams whose size is not known up front.
+func ReadAll(name string, r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > limit {
+		return nil, &SizeError{Name: name, Limit: limit}
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatSize renders a byte count using binary units, e.g. "10MiB".
+func FormatSize(n int64) string {
+	const unit = 1024
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for n >= unit && n%unit == 0 && i < len(units)-1 {
+		n /= unit
+		i++
+	}
+	return fmt.Sprintf("%d%s", n, units[i])
+}
+
+// ParseSize parses a byte count with an optional binary or decimal
+// suffix: "10MiB", "512K", "1G", "4096".
+func ParseSize(s string) (int64, error) {
+	suffixes := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+		{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+		{"B", 1},
+	}
+	mult := int64(1)
+	num := s
+	for _, sf := range suffixes {
+		if len(s) > len(sf.suffix) && s[len(s)-len(sf.suffix):] == sf.suffix {
+			num, mult = s[:len(s)-len(sf.suffix)], sf.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if n > math.MaxInt64/mult {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return n * mult, nil
+}
@@ -0,0 +1,172 @@
+package validator
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// podTemplatePaths gives, for each workload kind, the keys leading to
+// its pod template. A Pod is its own template.
+var podTemplatePaths = map[string][]string{
+	"Pod":                   nil,
+	"Deployment":            {"spec", "template"},
+	"ReplicaSet":            {"spec", "template"},
+	"ReplicationController": {"spec", "template"},
+	"StatefulSet":           {"spec", "template"},
+	"DaemonSet":             {"spec", "template"},
+	"Job":                   {"spec", "template"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template"},
+}
+
+// RefSet indexes the documents of one input set so references between
+// them can be checked once all of them are known. Documents are indexed
+// on a best-effort basis: a document that does not parse or that failed
+// its own validation contributes whatever can still be read from it.
+type RefSet struct {
+	workloads []refWorkload
+	services  []refService
+	backends  []refBackend
+	order     map[string]int // position of each input among those added
+}
+
+type refWorkload struct {
+	namespace string
+	labels    map[string]string
+}
+
+type refService struct {
+	file, namespace, name string
+	selector              map[string]string
+	node                  *yaml.Node
+}
+
+type refBackend struct {
+	file, namespace, field, service string
+	node                            *yaml.Node
+}
+
+// Add indexes every document of data, read from the input called name.
+func (s *RefSet) Add(name string, data []byte) {
+	if s.order == nil {
+		s.order = make(map[string]int)
+	}
+	if _, ok := s.order[name]; !ok {
+		s.order[name] = len(s.order)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var root yaml.Node
+		if err := dec.Decode(&root); err != nil {
+			// io.EOF ends the stream; the input's own validation
+			// reports any other error.
+			return
+		}
+		if len(root.Content) > 0 && root.Content[0].Kind == yaml.MappingNode {
+			s.addDocument(name, root.Content[0])
+		}
+	}
+}
+
+func (s *RefSet) addDocument(name string, doc *yaml.Node) {
+	kind := scalarValue(getField(doc, "kind"))
+	namespace, _ := documentScope(doc)
+	if keys, ok := podTemplatePaths[kind]; ok {
+		tmpl := doc
+		for _, k := range keys {
+			tmpl = getField(tmpl, k)
+		}
+		if tmpl != nil {
+			_, labels := documentScope(tmpl)
+			s.workloads = append(s.workloads, refWorkload{namespace: namespace, labels: labels})
+		}
+		return
+	}
+	switch kind {
+	case "Service":
+		svc := refService{file: name, namespace: namespace, name: scalarValue(getField(getField(doc, "metadata"), "name"))}
+		// Selectorless services are wired to endpoints by hand; they
+		// are kept, with a nil selector, as targets for Ingresses.
+		if sel := getField(getField(doc, "spec"), "selector"); sel != nil && sel.Kind == yaml.MappingNode && len(sel.Content) > 0 {
+			svc.node, svc.selector = sel, map[string]string{}
+			for i := 0; i+1 < len(sel.Content); i += 2 {
+				svc.selector[sel.Content[i].Value] = sel.Content[i+1].Value
+			}
+		}
+		s.services = append(s.services, svc)
+	case "Ingress":
+		s.addIngress(name, namespace, doc)
+	}
+}
+
+// addIngress records the Service names an Ingress routes to, in both
+// the networking.k8s.io/v1 and the older extensions/v1beta1 layouts.
+func (s *RefSet) addIngress(name, namespace string, doc *yaml.Node) {
+	add := func(field string, backend *yaml.Node) {
+		if n := getField(getField(backend, "service"), "name"); n != nil {
+			field += ".service.name"
+			s.backends = append(s.backends, refBackend{file: name, namespace: namespace, field: field, service: n.Value, node: n})
+		} else if n := getField(backend, "serviceName"); n != nil {
+			field += ".serviceName"
+			s.backends = append(s.backends, refBackend{file: name, namespace: namespace, field: field, service: n.Value, node: n})
+		}
+	}
+	spec := getField(doc, "spec")
+	add("spec.defaultBackend", getField(spec, "defaultBackend"))
+	add("spec.backend", getField(spec, "backend"))
+	for i, rule := range mappingItems(spec, "rules") {
+		for j, p := range mappingItems(getField(rule, "http"), "paths") {
+			add(fmt.Sprintf("spec.rules[%d].http.paths[%d].backend", i, j), getField(p, "backend"))
+		}
+	}
+}
+
+// Check returns a warning for each Service whose selector matches no
+// workload in the set and each Ingress backend naming a Service that is
+// not in the set. They are warnings because the other end may well be
+// deployed from elsewhere. They are ordered by input, in the order the
+// inputs were added, and then like Result.Sort.
+func (s *RefSet) Check() []*ValidationError {
+	var out []*ValidationError
+	for _, svc := range s.services {
+		if svc.selector != nil && !slices.ContainsFunc(s.workloads, func(w refWorkload) bool { return w.namespace == svc.namespace && selects(svc.selector, w.labels) }) {
+			e := newError(CategoryCrossField, "spec.selector", svc.node, "matches no workload in the input set (selector %s, namespace '%s')", formatSelector(svc.selector), svc.namespace)
+			e.File, e.Severity = svc.file, SeverityWarning
+			out = append(out, e)
+		}
+	}
+	for _, b := range s.backends {
+		if !slices.ContainsFunc(s.services, func(svc refService) bool { return svc.namespace == b.namespace && svc.name == b.service }) {
+			e := newError(CategoryCrossField, b.field, b.node, "refers to Service '%s', which is not in the input set (namespace '%s')", b.service, b.namespace)
+			e.File, e.Severity = b.file, SeverityWarning
+			out = append(out, e)
+		}
+	}
+	setCodes(out)
+	slices.SortStableFunc(out, func(a, b *ValidationError) int {
+		return cmp.Or(cmp.Compare(s.order[a.File], s.order[b.File]), compareFindings(a, b))
+	})
+	return out
+}
+
+func selects(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func formatSelector(sel map[string]string) string {
+	var parts []string
+	for _, k := range slices.Sorted(maps.Keys(sel)) {
+		parts = append(parts, k+"="+sel[k])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,193 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GroupVersionKind identifies a kind of document. The core group is the
+// empty string, so a Pod is {Version: "v1", Kind: "Pod"}.
+type GroupVersionKind struct {
+	Group, Version, Kind string
+}
+
+// APIVersion returns the apiVersion string that declares gvk.
+func (gvk GroupVersionKind) APIVersion() string {
+	if gvk.Group == "" {
+		return gvk.Version
+	}
+	return gvk.Group + "/" + gvk.Version
+}
+
+func (gvk GroupVersionKind) String() string {
+	return gvk.APIVersion() + ", Kind=" + gvk.Kind
+}
+
+// ParseGroupVersion splits an apiVersion value into group and version.
+func ParseGroupVersion(apiVersion string) (group, version string) {
+	if g, v, ok := strings.Cut(apiVersion, "/"); ok {
+		return g, v
+	}
+	return "", apiVersion
+}
+
+// ReportFunc receives the findings of a KindValidator.
+type ReportFunc func(*ValidationError)
+
+// KindValidator validates a document of a registered kind. doc is the
+// top-level mapping and report must be called once per finding.
+type KindValidator func(doc *yaml.Node, h Helpers, report ReportFunc)
+
+// Helpers bundles the building blocks the built-in validators use, so
+// that registered kinds can produce findings in the same shape.
+type Helpers struct {
+	opts  *Options
+	trace *trace
+}
+
+func (h Helpers) checker(report ReportFunc) *checker {
+	opts := h.opts
+	if opts == nil {
+		opts = &Options{}
+	}
+	if t := h.trace; t != nil {
+		inner := report
+		report = func(e *ValidationError) {
+			t.finding(e, opts.ruleOff(e.Rule))
+			inner(e)
+		}
+	}
+	return &checker{opts: opts, report: report, trace: h.trace}
+}
+
+// Field returns the value stored under key in mapping m, or nil.
+func (Helpers) Field(m *yaml.Node, key string) *yaml.Node { return getField(m, key) }
+
+// Key extends a field path with a mapping key.
+func (Helpers) Key(path, key string) string { return joinKey(path, key) }
+
+// Index extends a field path with a sequence index.
+func (Helpers) Index(path string, i int) string { return joinIndex(path, i) }
+
+// ParseQuantity parses a Kubernetes resource quantity.
+func (Helpers) ParseQuantity(s string) (Quantity, error) { return ParseQuantity(s) }
+
+// NewError builds a finding of the given category positioned at node,
+// which may be nil when there is nothing to point at.
+func NewError(cat Category, field string, node *yaml.Node, format string, args ...any) *ValidationError {
+	return newError(cat, field, node, format, args...)
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func joinIndex(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// containerLists are the pod spec keys whose items are addressed by
+// container name rather than by index.
+var containerLists = map[string]bool{"containers": true, "initContainers": true, "ephemeralContainers": true}
+
+// itemPath returns the field path of item i of list, found at path.
+// Containers are named when their name is a string no other item of the
+// list uses, as in "spec.containers[name=web].image", so findings say
+// which container they are about; other items keep their index.
+func itemPath(path string, list *yaml.Node, i int) string {
+	if !containerLists[path[strings.LastIndexByte(path, '.')+1:]] {
+		return joinIndex(path, i)
+	}
+	name := getField(list.Content[i], "name")
+	if name == nil || name.Kind != yaml.ScalarNode || name.Tag != "!!str" || name.Value == "" || strings.ContainsAny(name.Value, "[]") {
+		return joinIndex(path, i)
+	}
+	for j, other := range list.Content {
+		if n := getField(other, "name"); j != i && n != nil && n.Value == name.Value {
+			return joinIndex(path, i)
+		}
+	}
+	return path + "[name=" + name.Value + "]"
+}
+
+// Registry maps kinds to the validators that check them. It is safe
+// for concurrent use. Registered kinds are for library users: the
+// command line validates the built-in kinds only.
+type Registry struct {
+	mu    sync.RWMutex
+	kinds map[GroupVersionKind]KindValidator
+}
+
+// NewRegistry returns a registry holding the built-in kinds.
+func NewRegistry() *Registry {
+	r := &Registry{kinds: make(map[GroupVersionKind]KindValidator)}
+	for gvk, fn := range builtinKinds {
+		if err := r.RegisterKind(gvk, fn); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// builtinKinds lists the kinds every NewRegistry starts with.
+var builtinKinds = map[GroupVersionKind]KindValidator{
+	{Version: "v1", Kind: "Pod"}:                       validatePod,
+	{Group: "apps", Version: "v1", Kind: "Deployment"}: validateDeployment,
+}
+
+// RegisterKind adds fn as the validator for gvk. Registering a kind
+// that already has a validator is an error.
+func (r *Registry) RegisterKind(gvk GroupVersionKind, fn KindValidator) error {
+	if gvk.Version == "" || gvk.Kind == "" {
+		return fmt.Errorf("register %v: version and kind are required", gvk)
+	}
+	if fn == nil {
+		return fmt.Errorf("register %v: nil validator", gvk)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.kinds[gvk]; ok {
+		return fmt.Errorf("register %v: kind is already registered", gvk)
+	}
+	r.kinds[gvk] = fn
+	return nil
+}
+
+// lookup returns the validator for gvk and, when there is none, the
+// apiVersions under which the kind is registered.
+func (r *Registry) lookup(gvk GroupVersionKind) (KindValidator, []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.kinds[gvk]; ok {
+		return fn, nil
+	}
+	var versions []string
+	for k := range r.kinds {
+		if k.Kind == gvk.Kind {
+			versions = append(versions, k.APIVersion())
+		}
+	}
+	sort.Strings(versions)
+	return nil, versions
+}
+
+var (
+	defaultRegistryOnce sync.Once
+	defaultRegistry     *Registry
+)
+
+func (o *Options) registry() *Registry {
+	if o.Registry != nil {
+		return o.Registry
+	}
+	defaultRegistryOnce.Do(func() { defaultRegistry = NewRegistry() })
+	return defaultRegistry
+}
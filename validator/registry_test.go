@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var widgetKind = GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+
+// validateWidget is a toy KindValidator: a Widget needs metadata.name
+// and a spec.size between 1 and 10.
+func validateWidget(doc *yaml.Node, h Helpers, report ReportFunc) {
+	if h.Field(h.Field(doc, "metadata"), "name") == nil {
+		report(NewError(CategoryRequired, "metadata.name", doc, "is required"))
+	}
+	spec := h.Field(doc, "spec")
+	size := h.Field(spec, "size")
+	field := h.Key("spec", "size")
+	switch {
+	case size == nil:
+		report(NewError(CategoryRequired, field, spec, "is required"))
+	case size.Tag != "!!int":
+		report(NewError(CategoryType, field, size, "must be an integer"))
+	default:
+		if n, err := strconv.Atoi(size.Value); err != nil || n < 1 || n > 10 {
+			report(NewError(CategoryRange, field, size, "must be between 1 and 10"))
+		}
+	}
+}
+
+func TestRegisteredKind(t *testing.T) {
+	r := NewRegistry()
+	if err := r.RegisterKind(widgetKind, validateWidget); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Registry: r}
+
+	res := mustValidate(t, "w.yaml", "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\nspec:\n  size: 3\n", opts)
+	if len(res.Findings) > 0 {
+		t.Errorf("valid Widget: %v", res.Findings)
+	}
+	if len(res.Documents) != 1 || res.Documents[0].Label() != "Widget/w" {
+		t.Errorf("Documents = %+v, want Widget/w", res.Documents)
+	}
+
+	res = mustValidate(t, "w.yaml", "apiVersion: example.com/v1\nkind: Widget\nspec:\n  size: big\n", opts)
+	want := map[string]Category{"metadata.name": CategoryRequired, "spec.size": CategoryType}
+	for _, e := range res.Findings {
+		if want[e.Field] != e.Category {
+			t.Errorf("unexpected finding %s: %s", e.Field, e.Message)
+		}
+		if e.Line == 0 || e.File != "w.yaml" {
+			t.Errorf("finding %s at %s:%d, want a position in w.yaml", e.Field, e.File, e.Line)
+		}
+		delete(want, e.Field)
+	}
+	if len(want) > 0 {
+		t.Errorf("missing findings %v in %v", want, res.Findings)
+	}
+
+	// The built-in kinds come with every registry.
+	if res := mustValidate(t, "pod.yaml", validPod, opts); len(res.Findings) > 0 {
+		t.Errorf("Pod through the custom registry: %v", res.Findings)
+	}
+	// Without the registry, a Widget is unsupported.
+	res = mustValidate(t, "w.yaml", "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n", Options{})
+	if len(res.Findings) != 1 || !errors.Is(res.Findings[0], ErrUnsupportedKind) {
+		t.Errorf("Widget without registration: %v, want ErrUnsupportedKind", res.Findings)
+	}
+}
+
+func TestRegisterKindErrors(t *testing.T) {
+	r := NewRegistry()
+	if err := r.RegisterKind(widgetKind, validateWidget); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		gvk  GroupVersionKind
+		fn   KindValidator
+	}{
+		{"twice", widgetKind, validateWidget},
+		{"built-in", GroupVersionKind{Version: "v1", Kind: "Pod"}, validateWidget},
+		{"no version", GroupVersionKind{Group: "example.com", Kind: "Gadget"}, validateWidget},
+		{"no kind", GroupVersionKind{Group: "example.com", Version: "v1"}, validateWidget},
+		{"nil validator", GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.RegisterKind(tt.gvk, tt.fn); err == nil {
+				t.Errorf("RegisterKind(%v) succeeded", tt.gvk)
+			}
+		})
+	}
+}
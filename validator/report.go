@@ -0,0 +1,285 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Reporter receives the findings of a run as they are produced and a
+// summary once the run is over. Reporters that write a single document,
+// such as JSON, hold findings back until Summary. A Reporter is not
+// safe for concurrent use.
+//
+// Reporters write findings in the order they receive them. The CLI
+// hands them over input by input, in the order the inputs were given,
+// each input's in Result order (see Result.Sort), and findings about
+// several inputs, such as those of RefSet.Check, last.
+type Reporter interface {
+	Report(e *ValidationError)
+	// Summary ends the run. It returns the first error met while
+	// writing output, so Report itself need not.
+	Summary(s Stats) error
+}
+
+// Stats summarizes a run.
+type Stats struct {
+	Files    int // inputs validated, archive members included
+	Invalid  int // inputs of Files with at least one error
+	Skipped  int // inputs left out by the kind filters
+	Ignored  int // files and directories left out by ignore patterns
+	Excluded int // files and directories left out by --exclude patterns
+	// Unreadable counts the inputs of Files that could not be read,
+	// reported by IOResult; each is also counted in Invalid, with one
+	// error.
+	Unreadable int
+	Errors     int
+	Warnings   int
+	// Omitted counts the findings left out of the output by a cap such
+	// as --max-errors. They are still counted in Errors and Warnings.
+	Omitted int
+	// Documents counts the documents validated in Files, and
+	// InvalidDocuments those of them with at least one error.
+	Documents, InvalidDocuments int
+}
+
+// Count adds the findings of res to s as one more file, or as one more
+// skipped input.
+func (s *Stats) Count(res *Result) {
+	if res.Skipped {
+		s.Skipped++
+		return
+	}
+	s.Files++
+	if !res.Valid() {
+		s.Invalid++
+	}
+	if len(res.Findings) == 1 && res.Findings[0].Category == CategoryIO {
+		s.Unreadable++
+	}
+	s.Documents += len(res.Documents)
+	s.InvalidDocuments += res.invalidDocuments()
+	s.Errors += len(res.Errors())
+	s.Warnings += len(res.Warnings())
+}
+
+// String renders s as the closing line of a run, such as
+// "3 files checked, 2 valid, 1 invalid, 5 errors, 2 warnings". When
+// files held more than one document each, the document counts follow
+// the file counts: "...; 7 documents, 6 valid, 1 invalid; 5 errors...".
+func (s Stats) String() string {
+	out := fmt.Sprintf("%s checked, %d valid, %d invalid", plural(s.Files, "file"), s.Files-s.Invalid, s.Invalid)
+	sep := ", "
+	if s.Documents > s.Files {
+		out += fmt.Sprintf("; %s, %d valid, %d invalid", plural(s.Documents, "document"),
+			s.Documents-s.InvalidDocuments, s.InvalidDocuments)
+		sep = "; "
+	}
+	out += sep + plural(s.Errors, "error") + ", " + plural(s.Warnings, "warning")
+	if s.Unreadable > 0 {
+		out += fmt.Sprintf(", %d unreadable", s.Unreadable)
+	}
+	if s.Ignored > 0 {
+		out += fmt.Sprintf(", %d ignored", s.Ignored)
+	}
+	if s.Excluded > 0 {
+		out += fmt.Sprintf(", %d excluded", s.Excluded)
+	}
+	return out
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// summaryJSON is the wire form of Stats in the JSON document.
+type summaryJSON struct {
+	Files      int `json:"files"`
+	Valid      int `json:"valid"`
+	Invalid    int `json:"invalid"`
+	Skipped    int `json:"skipped"`
+	Ignored    int `json:"ignored,omitempty"`
+	Excluded   int `json:"excluded,omitempty"`
+	Unreadable int `json:"unreadable,omitempty"`
+	Errors     int `json:"errors"`
+	Warnings   int `json:"warnings"`
+	Omitted    int `json:"omitted"`
+
+	Documents        int `json:"documents"`
+	ValidDocuments   int `json:"validDocuments"`
+	InvalidDocuments int `json:"invalidDocuments"`
+}
+
+func (s Stats) wire() summaryJSON {
+	return summaryJSON{Files: s.Files, Valid: s.Files - s.Invalid, Invalid: s.Invalid, Skipped: s.Skipped,
+		Ignored: s.Ignored, Excluded: s.Excluded, Unreadable: s.Unreadable,
+		Errors: s.Errors, Warnings: s.Warnings, Omitted: s.Omitted,
+		Documents: s.Documents, ValidDocuments: s.Documents - s.InvalidDocuments, InvalidDocuments: s.InvalidDocuments}
+}
+
+// stats is the inverse of Stats.wire.
+func (s summaryJSON) stats() Stats {
+	return Stats{Files: s.Files, Invalid: s.Invalid, Skipped: s.Skipped, Ignored: s.Ignored, Excluded: s.Excluded,
+		Unreadable: s.Unreadable, Errors: s.Errors, Warnings: s.Warnings, Omitted: s.Omitted,
+		Documents: s.Documents, InvalidDocuments: s.InvalidDocuments}
+}
+
+// OmittedNotice is the closing line of a run that left findings out.
+func (s Stats) OmittedNotice() string {
+	return fmt.Sprintf("... and %d more findings, rerun with --max-errors=0", s.Omitted)
+}
+
+// CollectingReporter accumulates findings in memory, for library users
+// who want the whole run without any output.
+type CollectingReporter struct {
+	Findings []*ValidationError
+	Stats    Stats
+}
+
+func (c *CollectingReporter) Report(e *ValidationError) { c.Findings = append(c.Findings, e) }
+
+func (c *CollectingReporter) Summary(s Stats) error {
+	c.Stats = s
+	return nil
+}
+
+// NewTextReporter returns a Reporter writing one line per finding to w,
+// in the "file:line:col field message" format of the CLI.
+func NewTextReporter(w io.Writer) Reporter { return &textReporter{w: w} }
+
+type textReporter struct {
+	w   io.Writer
+	err error
+}
+
+func (t *textReporter) Report(e *ValidationError) {
+	t.write(FormatText(e))
+}
+
+func (t *textReporter) write(line string) {
+	if _, err := io.WriteString(t.w, line+"\n"); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *textReporter) Summary(s Stats) error {
+	if s.Omitted > 0 {
+		t.write(s.OmittedNotice())
+	}
+	return t.err
+}
+
+// FormatText renders e as "file:line:col [code] field message", leaving
+// out the parts of the position that are unknown and the code when
+// there is none. Warnings read "file:line:col warning: [code] ...", and
+// a finding that stands for several ends in "(x3)".
+func FormatText(e *ValidationError) string {
+	msg := e.Error()
+	if e.Occurrences > 1 {
+		msg += fmt.Sprintf(" (x%d)", e.Occurrences)
+	}
+	if e.Code != "" {
+		msg = "[" + e.Code + "] " + msg
+	}
+	if e.Severity == SeverityWarning {
+		msg = "warning: " + msg
+	}
+	file := e.File
+	if e.Resource != "" {
+		file += " [" + e.Resource + "]"
+	}
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d %s", file, e.Line, e.Column, msg)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d %s", file, e.Line, msg)
+	}
+	return fmt.Sprintf("%s: %s", file, msg)
+}
+
+// Resource is a document that validated without errors.
+type Resource struct {
+	File string
+	Document
+}
+
+// ResourceReporter is a Reporter that also lists the documents that
+// validated without errors, so a clean run still says what it checked.
+type ResourceReporter interface {
+	Reporter
+	Resource(r Resource)
+}
+
+// resourceJSON is the wire form of a Resource.
+type resourceJSON struct {
+	File          string `json:"file"`
+	DocumentIndex int    `json:"documentIndex"`
+	Kind          string `json:"kind"`
+	Name          string `json:"name,omitempty"`
+	Containers    int    `json:"containers"`
+	Ports         int    `json:"ports"`
+}
+
+func (r Resource) wire() resourceJSON {
+	return resourceJSON{File: r.File, DocumentIndex: r.Index, Kind: r.Kind, Name: r.Name, Containers: r.Containers, Ports: r.Ports}
+}
+
+// resource is the inverse of Resource.wire.
+func (r resourceJSON) resource() Resource {
+	return Resource{File: r.File, Document: Document{Index: r.DocumentIndex, Kind: r.Kind, Name: r.Name,
+		Containers: r.Containers, Ports: r.Ports}}
+}
+
+// NewJSONReporter returns a Reporter writing the run to w as one
+// indented JSON object, described by OutputSchema, when it ends: the
+// schema ID, the findings array, the resources that validated without
+// errors and a summary of the Stats. An empty run has an empty
+// findings array and no resources. When Stats.Omitted is set the
+// document carries "truncated": true.
+func NewJSONReporter(w io.Writer) ResourceReporter { return &jsonReporter{w: w} }
+
+// documentJSON is the document NewJSONReporter writes, and
+// Result.MarshalJSON for one input.
+type documentJSON struct {
+	Schema string `json:"schema"`
+	// File and Documents are set by Result.MarshalJSON alone: the input
+	// and every document of it, valid or not.
+	File      string         `json:"file,omitempty"`
+	Findings  []findingJSON  `json:"findings"`
+	Resources []resourceJSON `json:"resources,omitempty"`
+	Documents []resourceJSON `json:"documents,omitempty"`
+	Summary   summaryJSON    `json:"summary"`
+	// Truncated marks a run whose findings were cut short by a cap on
+	// their number.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// newDocument builds the document of a run from its findings, the
+// resources that validated without errors and its Stats.
+func newDocument(findings []*ValidationError, resources []resourceJSON, s Stats) documentJSON {
+	out := make([]findingJSON, len(findings))
+	for i, e := range findings {
+		out[i] = e.wire()
+	}
+	return documentJSON{Schema: OutputSchemaID, Findings: out, Resources: resources, Summary: s.wire(), Truncated: s.Omitted > 0}
+}
+
+type jsonReporter struct {
+	w         io.Writer
+	findings  []*ValidationError
+	resources []resourceJSON
+}
+
+func (j *jsonReporter) Report(e *ValidationError) { j.findings = append(j.findings, e) }
+
+func (j *jsonReporter) Resource(r Resource) { j.resources = append(j.resources, r.wire()) }
+
+func (j *jsonReporter) Summary(s Stats) error {
+	enc := json.NewEncoder(j.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newDocument(j.findings, j.resources, s))
+}
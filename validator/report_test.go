@@ -0,0 +1,301 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestJSONReporterTruncated(t *testing.T) {
+	res := mustValidate(t, "pod.yaml", twoContainerPod, Options{})
+	for _, omitted := range []int{0, 3} {
+		var buf bytes.Buffer
+		r := NewJSONReporter(&buf)
+		for _, e := range res.Findings {
+			r.Report(e)
+		}
+		if err := r.Summary(Stats{Files: 1, Invalid: 1, Errors: len(res.Findings), Omitted: omitted}); err != nil {
+			t.Fatal(err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := doc["truncated"] == true, omitted > 0; got != want {
+			t.Errorf("omitted %d: truncated = %v, want %v", omitted, doc["truncated"], want)
+		}
+		for _, f := range doc["findings"].([]any) {
+			if _, ok := f.(map[string]any)["truncated"]; ok {
+				t.Errorf("omitted %d: finding %v carries truncated", omitted, f)
+			}
+		}
+		props := OutputSchema()["properties"].(map[string]any)
+		for key := range doc {
+			if _, ok := props[key]; !ok {
+				t.Errorf("document has %q, which OutputSchema does not describe", key)
+			}
+		}
+		rep, err := ReadJSONReport(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rep.Stats.Omitted != omitted || len(rep.Findings) != len(res.Findings) {
+			t.Errorf("read back %d findings, %d omitted; want %d and %d", len(rep.Findings), rep.Stats.Omitted, len(res.Findings), omitted)
+		}
+	}
+}
+
+func TestReadJSONReportSchemas(t *testing.T) {
+	const v2 = `{"schema": "https://github.com/abdddev/go-magistr-lesson2-tpl/schemas/findings/v2.json",
+"findings": [{"file": "a.yaml", "line": 1, "column": 1, "field": "kind", "message": "is required",
+  "severity": "error", "category": "required", "truncated": true}],
+"summary": {"files": 1, "valid": 0, "invalid": 1, "skipped": 0, "errors": 1, "warnings": 0, "omitted": 2,
+  "documents": 1, "validDocuments": 0, "invalidDocuments": 1}}`
+	rep, err := ReadJSONReport([]byte(v2))
+	if err != nil {
+		t.Fatalf("v2 report: %v", err)
+	}
+	if len(rep.Findings) != 1 || rep.Stats.Omitted != 2 {
+		t.Errorf("v2 report read as %+v", rep)
+	}
+
+	v1 := strings.Replace(v2, "v2.json", "v1.json", 1)
+	if _, err := ReadJSONReport([]byte(v1)); !errors.Is(err, ErrIncompatibleReport) {
+		t.Errorf("v1 report: err = %v, want ErrIncompatibleReport", err)
+	}
+}
+
+func TestSARIFRuleIDs(t *testing.T) {
+	res := mustValidate(t, "pod.yaml", twoContainerPod, Options{})
+	plugin := &ValidationError{File: "pod.yaml", Field: "spec.os", Message: "is odd", Category: CategoryEnum}
+	var buf bytes.Buffer
+	r := NewSARIFReporter(&buf, "podvalidator")
+	for _, e := range append(res.Findings, plugin) {
+		r.Report(e)
+	}
+	if err := r.Summary(Stats{}); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	var ids []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ids = append(ids, rule.ID)
+	}
+	for i, e := range append(res.Findings, plugin) {
+		want := e.Code
+		if want == "" {
+			want = "enum/spec.os"
+		}
+		got := run.Results[i]
+		if got.RuleID != want {
+			t.Errorf("%s: ruleId %q, want %q", e.Field, got.RuleID, want)
+		}
+		if got.RuleIndex < 0 || got.RuleIndex >= len(ids) || ids[got.RuleIndex] != want {
+			t.Errorf("%s: ruleIndex %d does not point at %q in %v", e.Field, got.RuleIndex, want, ids)
+		}
+	}
+}
+
+func TestCheckstyleSource(t *testing.T) {
+	res := mustValidate(t, "pod.yaml", twoContainerPod, Options{})
+	var buf bytes.Buffer
+	r := NewCheckstyleReporter(&buf)
+	r.StartFile("clean.yaml")
+	for _, e := range res.Findings {
+		r.Report(e)
+	}
+	if err := r.Summary(Stats{}); err != nil {
+		t.Fatal(err)
+	}
+	var log checkstyleLog
+	if err := xml.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Files) != 2 || log.Files[0].Name != "clean.yaml" || len(log.Files[0].Errors) != 0 {
+		t.Fatalf("files %+v, want an empty clean.yaml and pod.yaml", log.Files)
+	}
+	errs := log.Files[1].Errors
+	if len(errs) != len(res.Findings) {
+		t.Fatalf("%d errors, want %d", len(errs), len(res.Findings))
+	}
+	for i, e := range res.Findings {
+		if errs[i].Source != e.Code {
+			t.Errorf("%s: source %q, want code %q", e.Field, errs[i].Source, e.Code)
+		}
+	}
+}
+
+// shardReport builds the report of a shard that validated srcs, by
+// file name.
+func shardReport(t *testing.T, srcs map[string]string) *Report {
+	t.Helper()
+	rep := &Report{}
+	for _, name := range slices.Sorted(maps.Keys(srcs)) {
+		res := mustValidate(t, name, srcs[name], Options{})
+		rep.Findings = append(rep.Findings, res.Findings...)
+		for _, d := range res.ValidDocuments() {
+			rep.Resources = append(rep.Resources, Resource{File: name, Document: d})
+		}
+		rep.Stats.Count(res)
+	}
+	return rep
+}
+
+func TestMergeReportsOverlap(t *testing.T) {
+	const twoDocs = validPod + "---\n" + validPod
+	a := shardReport(t, map[string]string{"valid.yaml": validPod, "bad.yaml": twoContainerPod, "two.yaml": twoDocs})
+	b := shardReport(t, map[string]string{"bad.yaml": twoContainerPod, "two.yaml": twoDocs, "other.yaml": validPod})
+	omitted := shardReport(t, map[string]string{"late.yaml": twoContainerPod})
+	omitted.Stats.Omitted, omitted.Findings = len(omitted.Findings), nil
+	tests := []struct {
+		name      string
+		reports   []*Report
+		files     int
+		documents int
+		invalid   int
+	}{
+		{"one shard", []*Report{a}, 3, 4, 1},
+		{"overlapping shards", []*Report{a, b}, 4, 5, 1},
+		{"same shard twice", []*Report{a, a}, 3, 4, 1},
+		{"shard with omitted findings", []*Report{a, omitted}, 4, 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeReports(tt.reports...).Stats
+			if got.Files != tt.files || got.Documents != tt.documents || got.Invalid != tt.invalid {
+				t.Errorf("files %d, documents %d, invalid %d; want %d, %d, %d", got.Files, got.Documents, got.Invalid, tt.files, tt.documents, tt.invalid)
+			}
+		})
+	}
+}
+
+// sarifSubset is the part of the SARIF 2.1.0 schema that covers what
+// the SARIF reporter writes: the required properties of each object,
+// the types and ranges of the properties, and no others.
+const sarifSubset = `{
+  "type": "object",
+  "required": ["version", "runs"],
+  "additionalProperties": false,
+  "properties": {
+    "$schema": {"type": "string"},
+    "version": {"enum": ["2.1.0"]},
+    "runs": {"type": "array", "items": {"$ref": "#/$defs/run"}}
+  },
+  "$defs": {
+    "run": {
+      "type": "object", "required": ["tool"], "additionalProperties": false,
+      "properties": {
+        "tool": {"$ref": "#/$defs/tool"},
+        "results": {"type": "array", "items": {"$ref": "#/$defs/result"}}
+      }
+    },
+    "tool": {
+      "type": "object", "required": ["driver"], "additionalProperties": false,
+      "properties": {"driver": {"$ref": "#/$defs/driver"}}
+    },
+    "driver": {
+      "type": "object", "required": ["name"], "additionalProperties": false,
+      "properties": {
+        "name": {"type": "string"},
+        "informationUri": {"type": "string"},
+        "rules": {"type": "array", "items": {"$ref": "#/$defs/rule"}}
+      }
+    },
+    "rule": {
+      "type": "object", "required": ["id"], "additionalProperties": false,
+      "properties": {"id": {"type": "string"}, "shortDescription": {"$ref": "#/$defs/message"}}
+    },
+    "message": {
+      "type": "object", "required": ["text"], "additionalProperties": false,
+      "properties": {"text": {"type": "string"}}
+    },
+    "result": {
+      "type": "object", "required": ["message"], "additionalProperties": false,
+      "properties": {
+        "ruleId": {"type": "string"},
+        "ruleIndex": {"type": "integer", "minimum": -1},
+        "level": {"enum": ["none", "note", "warning", "error"]},
+        "message": {"$ref": "#/$defs/message"},
+        "locations": {"type": "array", "items": {"$ref": "#/$defs/location"}},
+        "partialFingerprints": {"type": "object"}
+      }
+    },
+    "location": {
+      "type": "object", "additionalProperties": false,
+      "properties": {
+        "physicalLocation": {"$ref": "#/$defs/physicalLocation"},
+        "logicalLocations": {"type": "array", "items": {"$ref": "#/$defs/logicalLocation"}}
+      }
+    },
+    "physicalLocation": {
+      "type": "object", "additionalProperties": false,
+      "properties": {
+        "artifactLocation": {
+          "type": "object", "additionalProperties": false,
+          "properties": {"uri": {"type": "string"}}
+        },
+        "region": {
+          "type": "object", "additionalProperties": false,
+          "properties": {
+            "startLine": {"type": "integer", "minimum": 1},
+            "startColumn": {"type": "integer", "minimum": 1},
+            "endLine": {"type": "integer", "minimum": 1},
+            "endColumn": {"type": "integer", "minimum": 1}
+          }
+        }
+      }
+    },
+    "logicalLocation": {
+      "type": "object", "additionalProperties": false,
+      "properties": {"fullyQualifiedName": {"type": "string"}, "kind": {"type": "string"}}
+    }
+  }
+}`
+
+func TestSARIFLogStructure(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(sarifSubset), &schema); err != nil {
+		t.Fatal(err)
+	}
+	invalid := mustValidate(t, "pod.yaml", twoContainerPod, Options{})
+	warned := mustValidate(t, "latest.yaml", strings.Replace(validPod, "nginx:1.25", "nginx", 1), Options{})
+	unread := IOResult("gone.yaml", ErrIO)
+	tests := []struct {
+		name    string
+		results []*Result
+	}{
+		{"no findings", nil},
+		{"errors", []*Result{invalid}},
+		{"errors, warnings and a file without a position", []*Result{invalid, warned, unread}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := NewSARIFReporter(&buf, "podvalidator")
+			for _, res := range tt.results {
+				for _, e := range res.Findings {
+					r.Report(e)
+				}
+			}
+			if err := r.Summary(Stats{}); err != nil {
+				t.Fatal(err)
+			}
+			var log any
+			if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkSchema(schema, schema, log, ""); len(got) > 0 {
+				t.Errorf("violations %q\nlog: %s", got, buf.Bytes())
+			}
+		})
+	}
+}
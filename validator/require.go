@@ -0,0 +1,139 @@
+package validator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleRequire prefixes the default rule IDs of RequiredField entries.
+const ruleRequire = "require:"
+
+// RequiredField makes a normally optional Pod field mandatory. Path is
+// a dotted field path where [*] stands for every item of a list, e.g.
+// "metadata.labels.team" or "spec.containers[*].livenessProbe".
+type RequiredField struct {
+	Path string `yaml:"path"`
+	// Rule identifies the policy entry in findings. It defaults to
+	// "require:" followed by Path.
+	Rule string `yaml:"rule"`
+
+	segs []pathSegment
+}
+
+// UnmarshalYAML accepts either a bare path or a mapping with path and
+// rule keys.
+func (r *RequiredField) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		r.Path = n.Value
+		return nil
+	}
+	type plain RequiredField
+	return n.Decode((*plain)(r))
+}
+
+// Compile returns r with Path parsed and checked against the fields the
+// validator knows, so that a typo is reported when the policy is loaded
+// rather than silently never matching, and with Rule defaulted.
+func (r RequiredField) Compile() (RequiredField, error) {
+	segs, err := parsePath(r.Path)
+	if err != nil {
+		return RequiredField{}, &ConfigError{Field: "require", Err: err}
+	}
+	s := podSchema
+	for i, seg := range segs {
+		switch {
+		case seg.key != "":
+			next := s.child(seg.key)
+			if next == nil {
+				return RequiredField{}, &ConfigError{Field: "require", Err: fmt.Errorf("%q: unknown field %q", r.Path, seg.key)}
+			}
+			s = next
+		case seg.wildcard && s.items != nil:
+			s = s.items
+		default:
+			return RequiredField{}, &ConfigError{Field: "require", Err: fmt.Errorf("%q: step %d must be a key or [*] on a list", r.Path, i+1)}
+		}
+	}
+	if r.Rule == "" {
+		r.Rule = ruleRequire + r.Path
+	}
+	r.segs = segs
+	return r, nil
+}
+
+// compileRequired returns req with every entry compiled, leaving out the
+// ones that do not compile. req itself is never written, as it may be
+// shared by validations running at once; it is returned as is when
+// every entry is compiled already.
+func compileRequired(req []RequiredField) []RequiredField {
+	if !slices.ContainsFunc(req, func(r RequiredField) bool { return r.segs == nil }) {
+		return req
+	}
+	out := make([]RequiredField, 0, len(req))
+	for _, r := range req {
+		if r.segs == nil {
+			var err error
+			if r, err = r.Compile(); err != nil {
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// requiredFields enforces Options.Require on pod, a Pod document or the
+// pod template of a workload at path. A template is held to the paths
+// under metadata and spec only.
+func (c *checker) requiredFields(pod *yaml.Node, path string) {
+	for i := range c.opts.Require {
+		r := &c.opts.Require[i]
+		if path != "" && r.segs[0].key != "metadata" && r.segs[0].key != "spec" {
+			continue
+		}
+		c.requirePath(pod, r.segs, path, r)
+	}
+}
+
+// requirePath walks segs from n. Lists expand over their items; a
+// missing key is reported with the full path that was required.
+func (c *checker) requirePath(n *yaml.Node, segs []pathSegment, path string, r *RequiredField) {
+	if len(segs) == 0 {
+		return
+	}
+	seg := segs[0]
+	if seg.wildcard {
+		if n == nil || n.Kind != yaml.SequenceNode {
+			return // an absent list has no items to check
+		}
+		for i, item := range n.Content {
+			c.requirePath(item, segs[1:], itemPath(path, n, i), r)
+		}
+		return
+	}
+	next := getField(n, seg.key)
+	if isNull(next) {
+		if n != nil && n.Kind == yaml.MappingNode {
+			e := requiredKey(joinKey(path, seg.key+renderSegments(segs[1:])), seg.key, n)
+			e.Rule = r.Rule
+			c.report(e)
+		}
+		return
+	}
+	c.requirePath(next, segs[1:], joinKey(path, seg.key), r)
+}
+
+func renderSegments(segs []pathSegment) string {
+	var b strings.Builder
+	for _, s := range segs {
+		if s.wildcard {
+			b.WriteString("[*]")
+		} else {
+			b.WriteString("." + s.key)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+
+func TestRequireDeploymentTemplate(t *testing.T) {
+	opts := Options{Require: []RequiredField{
+		{Path: "metadata.labels.team"},
+		{Path: "spec.containers[*].livenessProbe"},
+		{Path: "apiVersion"}, // set on the Deployment, and not held against its template
+	}}
+	res := mustValidate(t, "deploy.yaml", deployment, opts)
+	want := map[string]string{
+		"spec.template.metadata.labels.team":                    "require:metadata.labels.team",
+		"spec.template.spec.containers[name=web].livenessProbe": "require:spec.containers[*].livenessProbe",
+	}
+	for _, e := range res.Findings {
+		if rule, ok := want[e.Field]; ok && e.Rule == rule && e.Category == CategoryRequired {
+			delete(want, e.Field)
+		} else if strings.HasPrefix(e.Rule, "require:") {
+			t.Errorf("unexpected finding %s: %s (rule %s)", e.Field, e.Message, e.Rule)
+		}
+	}
+	for field, rule := range want {
+		t.Errorf("no %s finding at %s in %v", rule, field, res.Findings)
+	}
+}
+
+// TestRequireShared validates with one Options from 8 goroutines, as
+// --jobs 8 does, while Require is still to be compiled; run with -race.
+func TestRequireShared(t *testing.T) {
+	opts := Options{Require: []RequiredField{{Path: "metadata.labels.team"}, {Path: "spec.containers[*].resources"}}}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 20 {
+				src := validPod
+				if (i+j)%2 == 1 {
+					src = deployment
+				}
+				res, err := ValidateBytes(context.Background(), fmt.Sprintf("in%d-%d.yaml", i, j), []byte(src), opts)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n := len(res.ByRule("require:metadata.labels.team")); n != 1 {
+					t.Errorf("%s: %d require:metadata.labels.team findings, want 1", res.File, n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, r := range opts.Require {
+		if r.segs != nil || r.Rule != "" {
+			t.Errorf("Require entry %+v was written", r)
+		}
+	}
+}
+
+func TestRequiredFieldCompile(t *testing.T) {
+	r := RequiredField{Path: "spec.containers[*].livenessProbe"}
+	c, err := r.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Rule != "require:spec.containers[*].livenessProbe" || len(c.segs) != 4 {
+		t.Errorf("Compile() = %+v, want the default rule and 4 steps", c)
+	}
+	if r.Rule != "" || r.segs != nil {
+		t.Errorf("Compile wrote its receiver: %+v", r)
+	}
+
+	for _, path := range []string{"spec.containerz", "metadata.labels[*]", "spec..x"} {
+		_, err := RequiredField{Path: path}.Compile()
+		var ce *ConfigError
+		if !errors.As(err, &ce) {
+			t.Errorf("Compile(%q) = %v, want a *ConfigError", path, err)
+		}
+	}
+}
+
+// TestRequireOnePosition checks that two Require entries missing from
+// one mapping, whose findings share position and code, are both kept.
+func TestRequireOnePosition(t *testing.T) {
+	opts := Options{Require: []RequiredField{{Path: "metadata.labels.team"}, {Path: "metadata.labels.owner"}}}
+	res := mustValidate(t, "pod.yaml", validPod, opts)
+	if len(res.Findings) != 2 || res.Findings[0].Field == res.Findings[1].Field {
+		t.Fatalf("findings %v, want one for each label", res.Findings)
+	}
+	for _, e := range res.Findings {
+		if e.Occurrences > 1 {
+			t.Errorf("%s is folded (x%d)", e.Field, e.Occurrences)
+		}
+	}
+	var s Stats
+	s.Count(res)
+	if s.Errors != 2 {
+		t.Errorf("Stats.Errors = %d, want 2", s.Errors)
+	}
+}
@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fullPod sets every field of a Pod that is required, or required once
+// its parent is set.
+const fullPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx:1.25
+    ports:
+    - containerPort: 8080
+      name: http
+    env:
+    - name: MODE
+      value: prod
+    livenessProbe:
+      httpGet:
+        path: /healthz
+        port: http
+  volumes:
+  - name: data
+    emptyDir: {}
+`
+
+// deleteKey returns src without the key at path, a dotted path in which
+// numbers index lists.
+func deleteKey(t *testing.T, src, path string) string {
+	t.Helper()
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &root); err != nil {
+		t.Fatal(err)
+	}
+	steps := strings.Split(path, ".")
+	n := root.Content[0]
+	for _, step := range steps[:len(steps)-1] {
+		if i, err := strconv.Atoi(step); err == nil {
+			n = n.Content[i]
+		} else {
+			n = getField(n, step)
+		}
+	}
+	last := steps[len(steps)-1]
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == last {
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			out, err := yaml.Marshal(&root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(out)
+		}
+	}
+	t.Fatalf("no key %s", path)
+	return ""
+}
+
+func TestRequiredFindingsArePositioned(t *testing.T) {
+	if res := mustValidate(t, "pod.yaml", fullPod, Options{}); len(res.Findings) > 0 {
+		t.Fatalf("fullPod has findings: %v", res.Findings)
+	}
+	tests := []struct {
+		key   string
+		field string
+	}{
+		{"apiVersion", "apiVersion"},
+		{"kind", "kind"},
+		{"metadata", "metadata"},
+		{"metadata.name", "metadata.name"},
+		{"spec", "spec"},
+		{"spec.containers", "spec.containers"},
+		{"spec.containers.0.name", "spec.containers[0].name"},
+		{"spec.containers.0.image", "spec.containers[name=web].image"},
+		{"spec.containers.0.ports.0.containerPort", "spec.containers[name=web].ports[0].containerPort"},
+		{"spec.containers.0.env.0.name", "spec.containers[name=web].env[0].name"},
+		{"spec.containers.0.livenessProbe.httpGet.path", "spec.containers[name=web].livenessProbe.httpGet.path"},
+		{"spec.containers.0.livenessProbe.httpGet.port", "spec.containers[name=web].livenessProbe.httpGet.port"},
+		{"spec.volumes.0.name", "spec.volumes[0].name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			res := mustValidate(t, "pod.yaml", deleteKey(t, fullPod, tt.key), Options{})
+			var found bool
+			for _, e := range res.Findings {
+				if e.Category != CategoryRequired {
+					continue
+				}
+				if e.Line <= 0 || e.Column <= 0 {
+					t.Errorf("%s is required at %d:%d, want a position", e.Field, e.Line, e.Column)
+				}
+				found = found || e.Field == tt.field
+			}
+			if !found {
+				t.Errorf("no required finding at %s: %v", tt.field, res.Findings)
+			}
+		})
+	}
+}
+
+func TestMisspelledKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		keys string // the keys of the mapping
+		want string // the suggestion, or ""
+	}{
+		{"containers", "continers", "continers"},
+		{"containers", "Containers", "Containers"},
+		{"containers", "CONTAINERS", "CONTAINERS"},
+		{"containers", "containrz", "containrz"}, // two edits, within a third of ten
+		{"containers", "cntnrs", ""},             // four edits
+		{"containers", "volumes, continers, containrz", "continers"},
+		{"containers", "containers", ""}, // present, so nothing to suggest
+		{"kind", "knd", "knd"},
+		{"kind", "Kind", "Kind"},
+		{"kind", "kidn", ""}, // two edits, more than a third of four
+		{"spec", "sepc", ""},
+		{"image", "imgae", ""},
+		{"image", "imag", "imag"},
+		{"apiVersion", "apiversion", "apiversion"},
+		{"apiVersion", "apiVer", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"/"+tt.keys, func(t *testing.T) {
+			var m yaml.Node
+			if err := yaml.Unmarshal([]byte("{"+strings.ReplaceAll(tt.keys, ", ", ": 1, ")+": 1}"), &m); err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			if k := misspelledKey(m.Content[0], tt.key); k != nil {
+				got = k.Value
+			}
+			if got != tt.want {
+				t.Errorf("misspelledKey(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredSuggestsKey(t *testing.T) {
+	tests := []struct {
+		name string
+		from string // replaced in validPod
+		to   string
+		want string // "line:column field message" of the required finding
+	}{
+		{"misspelled", "  containers:", "  continers:",
+			"6:3 spec.containers is required (did you mean 'containers' instead of 'continers'? found: continers)"},
+		{"wrong case", "  containers:", "  Containers:",
+			"6:3 spec.containers is required (did you mean 'containers' instead of 'Containers'? found: Containers)"},
+		{"too far off", "  containers:", "  pods:",
+			"6:3 spec.containers is required (found: pods)"},
+		{"in a container", "    image:", "    imag:",
+			"8:5 spec.containers[name=web].image is required (did you mean 'image' instead of 'imag'? found: name, imag, ports)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := mustValidate(t, "pod.yaml", strings.Replace(validPod, tt.from, tt.to, 1), Options{})
+			var got []string
+			for _, e := range res.Findings {
+				if e.Category == CategoryRequired {
+					got = append(got, strconv.Itoa(e.Line)+":"+strconv.Itoa(e.Column)+" "+e.Error())
+				}
+			}
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("required findings %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package validator
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Severity tells whether a finding fails validation.
+type Severity int
+
+const (
+	// SeverityError findings make a document invalid.
+	SeverityError Severity = iota
+	// SeverityWarning findings are reported but do not fail validation.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "error":
+		*s = SeverityError
+	case "warning":
+		*s = SeverityWarning
+	default:
+		return fmt.Errorf("unknown severity %q", b)
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Category) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Category) UnmarshalText(b []byte) error {
+	for k, v := range categoryNames {
+		if v == string(b) {
+			*c = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown category %q", b)
+}
+
+// sentinelNames names the sentinel errors a finding can wrap so that
+// the link survives a JSON round trip.
+var sentinelNames = map[error]string{
+	ErrNotYAML:         "not-yaml",
+	ErrEmptyDocument:   "empty-document",
+	ErrUnsupportedKind: "unsupported-kind",
+}
+
+// findingJSON is the wire form of a ValidationError and the source of
+// OutputSchema; changing it may call for a new OutputSchemaID.
+type findingJSON struct {
+	File        string   `json:"file"`
+	Document    int      `json:"documentIndex,omitempty"`
+	Resource    string   `json:"resource,omitempty"`
+	Line        int      `json:"line"`
+	Column      int      `json:"column"`
+	KeyLine     int      `json:"keyLine,omitempty"`
+	KeyColumn   int      `json:"keyColumn,omitempty"`
+	ValueLine   int      `json:"valueLine,omitempty"`
+	ValueColumn int      `json:"valueColumn,omitempty"`
+	Field       string   `json:"field"`
+	Path        string   `json:"path,omitempty"`
+	Message     string   `json:"message"`
+	Severity    Severity `json:"severity"`
+	Category    Category `json:"category"`
+	Rule        string   `json:"rule,omitempty"`
+	Code        string   `json:"code,omitempty"`
+	Cause       string   `json:"cause,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	// Occurrences is set when the finding stands for several identical
+	// ones.
+	Occurrences int `json:"occurrences,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *ValidationError) MarshalJSON() ([]byte, error) { return marshalJSON(e.wire()) }
+
+func (e *ValidationError) wire() findingJSON {
+	return findingJSON{
+		File:        e.File,
+		Document:    e.Document,
+		Resource:    e.Resource,
+		Line:        e.Line,
+		Column:      e.Column,
+		KeyLine:     e.KeyLine,
+		KeyColumn:   e.KeyColumn,
+		ValueLine:   e.ValueLine,
+		ValueColumn: e.ValueColumn,
+		Field:       e.Field,
+		Path:        e.Path,
+		Message:     e.Message,
+		Severity:    e.Severity,
+		Category:    e.Category,
+		Rule:        e.Rule,
+		Code:        e.Code,
+		Cause:       sentinelNames[e.Err],
+		Fingerprint: e.Fingerprint,
+		Occurrences: e.Occurrences,
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *ValidationError) UnmarshalJSON(b []byte) error {
+	var f findingJSON
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	*e = *f.finding()
+	return nil
+}
+
+// finding is the inverse of ValidationError.wire.
+func (f findingJSON) finding() *ValidationError {
+	e := &ValidationError{
+		File:        f.File,
+		Document:    f.Document,
+		Resource:    f.Resource,
+		Field:       f.Field,
+		Path:        f.Path,
+		Line:        f.Line,
+		Column:      f.Column,
+		KeyLine:     f.KeyLine,
+		KeyColumn:   f.KeyColumn,
+		ValueLine:   f.ValueLine,
+		ValueColumn: f.ValueColumn,
+		Message:     f.Message,
+		Severity:    f.Severity,
+		Category:    f.Category,
+		Rule:        f.Rule,
+		Code:        f.Code,
+		Fingerprint: f.Fingerprint,
+		Occurrences: f.Occurrences,
+	}
+	for err, name := range sentinelNames {
+		if name == f.Cause && f.Cause != "" {
+			e.Err = err
+		}
+	}
+	return e
+}
+
+// Result holds the findings of validating one input.
+type Result struct {
+	// File is the name the input was validated under.
+	File string
+	// Findings lists errors and warnings in position order; see Sort.
+	Findings []*ValidationError
+	// Skipped is set when the input was left out by Options.Kinds or
+	// Options.SkipKinds and not validated.
+	Skipped bool
+	// Documents describes the documents of the input that were
+	// validated, in file order; the Document of a finding indexes it
+	// from 1.
+	Documents []Document
+}
+
+// Document identifies one validated document of an input.
+type Document struct {
+	Index      int // 1-based, counting the validated documents of the input
+	Kind, Name string
+	// Containers counts the containers of a workload's pod template and
+	// Ports the ports they declare; both are 0 for other kinds.
+	Containers, Ports int
+}
+
+// Label names d as "Kind/name", or by its kind alone when it has no
+// name; it is empty when d has no kind either.
+func (d Document) Label() string {
+	if d.Name == "" || d.Kind == "" {
+		return d.Kind
+	}
+	return d.Kind + "/" + d.Name
+}
+
+// ValidDocuments returns the documents of r without errors.
+func (r *Result) ValidDocuments() []Document {
+	invalid := make(map[int]bool)
+	for _, e := range r.Errors() {
+		invalid[e.Document] = true
+	}
+	var out []Document
+	for _, d := range r.Documents {
+		if !invalid[d.Index] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// invalidDocuments counts the documents of r with at least one error.
+func (r *Result) invalidDocuments() int {
+	invalid := make(map[int]bool)
+	for _, e := range r.Errors() {
+		if e.Document > 0 {
+			invalid[e.Document] = true
+		}
+	}
+	return len(invalid)
+}
+
+// Sort orders the findings by document, then line, column and code,
+// then field, rule and message, so the same input always reports the
+// same sequence whatever order the checks ran in; dedupe and the
+// fingerprint ordinals rely on it. Findings without a position keep
+// their relative order after the positioned ones of their document.
+func (r *Result) Sort() {
+	slices.SortStableFunc(r.Findings, compareFindings)
+}
+
+func compareFindings(a, b *ValidationError) int {
+	if c := cmp.Or(cmp.Compare(a.Document, b.Document), cmp.Compare(a.File, b.File)); c != 0 {
+		return c
+	}
+	switch {
+	case a.Line == 0 && b.Line == 0:
+		return 0
+	case a.Line == 0:
+		return 1
+	case b.Line == 0:
+		return -1
+	}
+	return cmp.Or(
+		cmp.Compare(a.Line, b.Line),
+		cmp.Compare(a.Column, b.Column),
+		cmp.Compare(a.Code, b.Code),
+		cmp.Compare(a.Field, b.Field),
+		cmp.Compare(a.Rule, b.Rule),
+		cmp.Compare(a.Message, b.Message),
+	)
+}
+
+// dedupe folds the findings that share file, position, code, field,
+// rule and message into the first of them, counting them in its
+// Occurrences. Findings without a line are never folded, and distinct
+// findings at one position, such as two missing required fields of one
+// mapping, are all kept.
+func dedupe(findings []*ValidationError) []*ValidationError {
+	type key struct {
+		file         string
+		line, column int
+		code         string
+		field, rule  string
+		message      string
+	}
+	first := make(map[key]*ValidationError)
+	out := findings[:0]
+	for _, e := range findings {
+		k := key{e.File, e.Line, e.Column, e.Code, e.Field, e.Rule, e.Message}
+		if f := first[k]; f != nil && e.Line > 0 {
+			f.Occurrences = max(f.Occurrences, 1) + 1
+			continue
+		}
+		first[k] = e
+		out = append(out, e)
+	}
+	return out
+}
+
+// Valid reports whether the input has no error-severity findings.
+func (r *Result) Valid() bool { return len(r.Errors()) == 0 }
+
+// Errors returns the error-severity findings.
+func (r *Result) Errors() []*ValidationError {
+	return r.filter(func(e *ValidationError) bool { return e.Severity == SeverityError })
+}
+
+// Warnings returns the warning-severity findings.
+func (r *Result) Warnings() []*ValidationError {
+	return r.filter(func(e *ValidationError) bool { return e.Severity == SeverityWarning })
+}
+
+// ByRule returns the findings reported by the rule with the given id.
+func (r *Result) ByRule(id string) []*ValidationError {
+	return r.filter(func(e *ValidationError) bool { return e.Rule == id })
+}
+
+// ByPath returns the findings whose field is prefix or lies below it,
+// so "spec.containers" matches "spec.containers[0].image" but not
+// "spec.containersx".
+func (r *Result) ByPath(prefix string) []*ValidationError {
+	return r.filter(func(e *ValidationError) bool { return pathHasPrefix(e.Field, prefix) })
+}
+
+func (r *Result) filter(keep func(*ValidationError) bool) []*ValidationError {
+	var out []*ValidationError
+	for _, e := range r.Findings {
+		if keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func pathHasPrefix(path, prefix string) bool {
+	if prefix == "" || path == prefix {
+		return true
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	next := path[len(prefix)]
+	return next == '.' || next == '['
+}
+
+// MarshalJSON implements json.Marshaler. r is written as the
+// --format=json document of a run over its input alone, with the input
+// and all of its documents added, so that UnmarshalJSON can restore it.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	var s Stats
+	s.Count(r)
+	var resources []resourceJSON
+	for _, d := range r.ValidDocuments() {
+		resources = append(resources, Resource{File: r.File, Document: d}.wire())
+	}
+	doc := newDocument(r.Findings, resources, s)
+	doc.File = r.File
+	for _, d := range r.Documents {
+		doc.Documents = append(doc.Documents, Resource{File: r.File, Document: d}.wire())
+	}
+	return marshalJSON(doc)
+}
+
+// marshalJSON is json.Marshal without HTML escaping, so messages such
+// as "did you mean '&name'" read the same in JSON as on the terminal.
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Result) UnmarshalJSON(b []byte) error {
+	var v documentJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = Result{File: v.File, Skipped: v.Summary.Skipped > 0}
+	for _, f := range v.Findings {
+		r.Findings = append(r.Findings, f.finding())
+	}
+	for _, d := range v.Documents {
+		r.Documents = append(r.Documents, d.resource().Document)
+	}
+	return nil
+}
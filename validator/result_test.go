@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// exported returns res with the unexported state of its findings, and
+// the causes JSON has no name for, cleared.
+func exported(res *Result) *Result {
+	out := *res
+	out.Findings = nil
+	for _, e := range res.Findings {
+		c := *e
+		c.node, c.embedded, c.format, c.args = nil, "", "", nil
+		if _, ok := sentinelNames[c.Err]; !ok {
+			c.Err = nil
+		}
+		out.Findings = append(out.Findings, &c)
+	}
+	return &out
+}
+
+func TestResultJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+	}{
+		{"valid", validPod, Options{}},
+		{"invalid", validPod + "---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: Bad_Name\nspec: {}\n", Options{}},
+		{"unsupported kind", "apiVersion: v1\nkind: Gadget\nmetadata:\n  name: g\n", Options{}},
+		{"skipped", validPod, Options{Kinds: []string{"Deployment"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := mustValidate(t, "in.yaml", tt.src, tt.opts)
+			data, err := json.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Result
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", data, err)
+			}
+			if want := exported(res); !reflect.DeepEqual(&got, want) {
+				t.Errorf("round trip of %s:\ngot  %+v\nwant %+v", data, &got, want)
+			}
+			again, err := json.Marshal(&got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again, data) {
+				t.Errorf("re-marshalled:\n%s\nwant\n%s", again, data)
+			}
+		})
+	}
+}
+
+// TestResultJSONIsCLIDocument checks that a Result marshals as the
+// document NewJSONReporter writes for a run over its input, plus the
+// properties OutputSchema reserves for a single Result.
+func TestResultJSONIsCLIDocument(t *testing.T) {
+	res := mustValidate(t, "in.yaml", validPod+"---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: x\nspec: {}\n", Options{})
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	r := NewJSONReporter(&buf)
+	var s Stats
+	for _, e := range res.Findings {
+		r.Report(e)
+	}
+	for _, d := range res.ValidDocuments() {
+		r.Resource(Resource{File: res.File, Document: d})
+	}
+	s.Count(res)
+	if err := r.Summary(s); err != nil {
+		t.Fatal(err)
+	}
+
+	var fromResult, fromReporter map[string]any
+	if err := json.Unmarshal(data, &fromResult); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &fromReporter); err != nil {
+		t.Fatal(err)
+	}
+	props := OutputSchema()["properties"].(map[string]any)
+	for key := range fromResult {
+		if _, ok := props[key]; !ok {
+			t.Errorf("Result JSON has %q, which OutputSchema does not describe", key)
+		}
+	}
+	delete(fromResult, "file")
+	delete(fromResult, "documents")
+	if !reflect.DeepEqual(fromResult, fromReporter) {
+		t.Errorf("Result JSON keys %v differ from the CLI document's %v:\n%s\n%s",
+			slices.Sorted(maps.Keys(fromResult)), slices.Sorted(maps.Keys(fromReporter)), data, buf.Bytes())
+	}
+}
+
+func TestResultSort(t *testing.T) {
+	f := func(doc, line, col int, code string) *ValidationError {
+		return &ValidationError{File: "in.yaml", Document: doc, Line: line, Column: col, Code: code, Message: code}
+	}
+	want := []*ValidationError{
+		f(1, 3, 1, "PV010"),
+		f(1, 3, 5, "PV002"),
+		f(1, 3, 5, "PV011"),
+		f(1, 9, 1, "PV001"),
+		f(1, 0, 0, "PV907"), // unpositioned, after the positioned ones
+		f(1, 0, 0, "PV100"), // and in the order reported
+		f(2, 1, 1, "PV005"),
+		f(2, 2, 1, "PV004"),
+	}
+	// Every order keeps the unpositioned 4 before 5.
+	for _, perm := range [][]int{{7, 6, 4, 5, 3, 2, 1, 0}, {3, 4, 0, 6, 2, 5, 7, 1}, {4, 5, 1, 0, 7, 6, 3, 2}} {
+		r := &Result{}
+		for _, i := range perm {
+			r.Findings = append(r.Findings, want[i])
+		}
+		r.Sort()
+		if !slices.Equal(r.Findings, want) {
+			var got []string
+			for _, e := range r.Findings {
+				got = append(got, e.Code)
+			}
+			t.Errorf("Sort of %v: %v", perm, got)
+		}
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	f := func(line int, field, rule, msg string) *ValidationError {
+		return &ValidationError{File: "in.yaml", Line: line, Column: 3, Code: "PV006", Field: field, Rule: rule, Message: msg}
+	}
+	tests := []struct {
+		name     string
+		findings []*ValidationError
+		want     []int // Occurrences of the findings kept
+	}{
+		{"identical", []*ValidationError{f(4, "a", "", "is required"), f(4, "a", "", "is required"), f(4, "a", "", "is required")}, []int{3}},
+		{"other field", []*ValidationError{f(4, "a", "", "is required"), f(4, "b", "", "is required")}, []int{0, 0}},
+		{"other rule", []*ValidationError{f(4, "a", "require:a", "is required"), f(4, "a", "require:b", "is required")}, []int{0, 0}},
+		{"other message", []*ValidationError{f(4, "a", "", "is required"), f(4, "a", "", "must be object (found sequence)")}, []int{0, 0}},
+		{"no line", []*ValidationError{f(0, "a", "", "is required"), f(0, "a", "", "is required")}, []int{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, e := range dedupe(tt.findings) {
+				got = append(got, e.Occurrences)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Occurrences %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
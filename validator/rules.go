@@ -0,0 +1,345 @@
+package validator
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ruleCode assigns a stable code such as "PV021" to one check. A check
+// is named either by the rule ID it sets on its findings, or by the
+// category and index-free field path of the findings it reports, so
+// that a type mismatch and a range error on one field are two checks
+// with two codes. Field patterns match the end of the path at a
+// segment boundary, with "*" standing for one segment, or for the rest
+// of the path at the end of a pattern. "resources.*.memory" thus covers
+// the limits and requests of every container of every pod template,
+// and "metadata.labels.*" label keys such as "app.kubernetes.io/name".
+// The fields of one entry are siblings checked alike, such as the
+// three host namespace switches.
+type ruleCode struct {
+	code     string
+	rule     string
+	category Category
+	fields   []string
+}
+
+// ruleCodes is the one table of codes; output formats, suppressions and
+// documentation all refer to these. Codes are never reused: a retired
+// check keeps its line with a comment. Findings that no entry covers,
+// such as those of plugins, get the code of their category's catch-all
+// at the end.
+var ruleCodes = []ruleCode{
+	{code: "PV001", category: CategoryEnum, fields: []string{"apiVersion"}},
+	{code: "PV002", category: CategoryEnum, fields: []string{"kind"}},
+	{code: "PV003", category: CategoryType, fields: []string{""}},
+	{code: "PV004", category: CategoryRequired, fields: []string{"metadata"}},
+	{code: "PV005", category: CategoryRequired, fields: []string{"metadata.name"}},
+	{code: "PV006", category: CategoryType, fields: []string{"metadata.labels.*"}},
+	{code: "PV007", category: CategoryType, fields: []string{"metadata.annotations.*"}},
+	{code: "PV008", category: CategoryRequired, fields: []string{"spec"}},
+	{code: "PV009", category: CategoryRequired, fields: []string{"spec.containers"}},
+	{code: "PV010", category: CategoryFormat, fields: []string{"containers[].name"}},
+	{code: "PV011", category: CategoryFormat, fields: []string{"containers[].image"}},
+	{code: "PV012", category: CategoryType, fields: []string{"containers[].stdin", "containers[].stdinOnce", "containers[].tty"}},
+	{code: "PV020", category: CategoryType, fields: []string{"containers[].resources"}},
+	{code: "PV021", category: CategoryFormat, fields: []string{"resources.*.memory"}},
+	{code: "PV022", category: CategoryFormat, fields: []string{"resources.*.cpu"}},
+	{code: "PV023", category: CategoryFormat, fields: []string{"resources.limits.*", "resources.requests.*"}},
+	{code: "PV024", category: CategoryCrossField, fields: []string{"resources.requests.*"}},
+	{code: "PV030", category: CategoryRange, fields: []string{"ports[].containerPort"}},
+	{code: "PV031", category: CategoryRange, fields: []string{"ports[].hostPort"}},
+	{code: "PV032", category: CategoryEnum, fields: []string{"ports[].protocol"}},
+	{code: "PV040", category: CategoryRequired, fields: []string{"env[].name"}},
+	{code: "PV050", category: CategoryType, fields: []string{"livenessProbe", "readinessProbe", "startupProbe",
+		"lifecycle", "lifecycle.postStart", "lifecycle.preStop", "httpGet", "tcpSocket"}},
+	{code: "PV051", category: CategoryCrossField, fields: []string{"httpGet.port", "tcpSocket.port"}},
+	{code: "PV060", category: CategoryType, fields: []string{"containers[].volumeMounts"}},
+	{code: "PV061", category: CategoryType, fields: []string{"containers[].securityContext"}},
+	{code: "PV062", category: CategoryType, fields: []string{"spec.hostNetwork", "spec.hostPID", "spec.hostIPC"}},
+	{code: "PV070", rule: ruleUniqueContainerName},
+	{code: "PV071", rule: ruleUniqueVolumeName},
+	{code: "PV072", rule: ruleUniqueMountPath},
+	{code: "PV073", rule: ruleUniqueClaimName},
+	{code: "PV074", rule: ruleUniqueSpread},
+	{code: "PV080", rule: ruleWindowsHostNamespaces},
+	{code: "PV081", rule: ruleWindowsPrivileged},
+	{code: "PV082", rule: ruleWindowsLinuxSecurity},
+	{code: "PV083", rule: ruleWindowsHostPath},
+	{code: "PV090", rule: ruleLimitContainers},
+	{code: "PV091", rule: ruleLimitPorts},
+	{code: "PV092", rule: ruleLimitEnv},
+	{code: "PV093", rule: ruleLimitLabels},
+	{code: "PV094", rule: ruleLimitAnnotations},
+	{code: "PV100", rule: ruleUnusedAnchor},
+	{code: "PV110", category: CategoryCrossField, fields: []string{"spec.selector"}},
+	{code: "PV111", category: CategoryCrossField, fields: []string{"backend.service.name", "backend.serviceName"}},
+	{code: "PV120", category: CategoryRequired, fields: []string{"spec.selector"}},
+	{code: "PV121", category: CategoryCrossField, fields: []string{"spec.selector.matchLabels.*", "template.metadata.labels.*"}},
+	{code: "PV122", category: CategoryRange, fields: []string{"spec.replicas"}},
+	{code: "PV123", category: CategoryRequired, fields: []string{"spec.template"}},
+	{code: "PV124", category: CategoryCrossField, fields: []string{"template.metadata.name", "template.metadata.namespace"}},
+
+	{code: "PV130", rule: ruleLatestTag},
+	{code: "PV131", rule: ruleHostPort},
+	{code: "PV132", rule: ruleEmptyLabels},
+	{code: "PV133", rule: ruleImageLock},
+	{code: "PV134", rule: ruleImageUnlocked},
+	{code: "PV135", rule: ruleProbePortHostNetwork},
+	{code: "PV136", rule: ruleProbePortUndeclared},
+	{code: "PV137", rule: ruleRequire},
+	{code: "PV138", rule: ruleDeprecatedAPI},
+	{code: "PV139", rule: ruleDeprecatedAnnotation},
+
+	{code: "PV140", category: CategoryType, fields: []string{"items"}},
+	{code: "PV141", rule: ruleListItemKind},
+	{code: "PV142", rule: ruleNestedParse},
+
+	// Documents and metadata.
+	{code: "PV150", category: CategoryRequired, fields: []string{"apiVersion"}},
+	{code: "PV151", category: CategoryType, fields: []string{"apiVersion"}},
+	{code: "PV152", category: CategoryRequired, fields: []string{"kind"}},
+	{code: "PV153", category: CategoryType, fields: []string{"kind"}},
+	{code: "PV154", category: CategoryType, fields: []string{"metadata"}},
+	{code: "PV155", category: CategoryType, fields: []string{"metadata.name"}},
+	{code: "PV156", category: CategoryRange, fields: []string{"metadata.name"}},
+	{code: "PV157", category: CategoryType, fields: []string{"metadata.labels"}},
+	{code: "PV158", category: CategoryType, fields: []string{"metadata.annotations"}},
+	{code: "PV159", category: CategoryType, fields: []string{"spec"}},
+
+	// Containers.
+	{code: "PV160", category: CategoryType, fields: []string{"spec.containers"}},
+	{code: "PV161", category: CategoryType, fields: []string{"containers[]"}},
+	{code: "PV162", category: CategoryRequired, fields: []string{"containers[].name"}},
+	{code: "PV163", category: CategoryType, fields: []string{"containers[].name"}},
+	{code: "PV164", category: CategoryRange, fields: []string{"containers[].name"}},
+	{code: "PV165", category: CategoryRequired, fields: []string{"containers[].image"}},
+	{code: "PV166", category: CategoryType, fields: []string{"containers[].image"}},
+	{code: "PV167", category: CategoryEnum, fields: []string{"containers[].image"}},
+	{code: "PV168", category: CategoryType, fields: []string{"containers[].securityContext.*"}},
+	{code: "PV170", category: CategoryType, fields: []string{"containers[].ports"}},
+	{code: "PV171", category: CategoryType, fields: []string{"ports[]"}},
+	{code: "PV172", category: CategoryRequired, fields: []string{"ports[].containerPort"}},
+	{code: "PV173", category: CategoryType, fields: []string{"ports[].containerPort"}},
+	{code: "PV174", category: CategoryType, fields: []string{"ports[].hostPort"}},
+	{code: "PV175", category: CategoryCrossField, fields: []string{"ports[].hostPort", "ports[].containerPort"}},
+	{code: "PV176", category: CategoryType, fields: []string{"ports[].name"}},
+	{code: "PV177", category: CategoryRange, fields: []string{"ports[].name"}},
+	{code: "PV178", category: CategoryType, fields: []string{"ports[].protocol"}},
+	{code: "PV180", category: CategoryType, fields: []string{"containers[].env"}},
+	{code: "PV181", category: CategoryType, fields: []string{"env[]"}},
+	{code: "PV182", category: CategoryType, fields: []string{"env[].name"}},
+	{code: "PV183", category: CategoryType, fields: []string{"env[].value"}},
+	{code: "PV184", category: CategoryRequired, fields: []string{"httpGet.path"}},
+	{code: "PV185", category: CategoryType, fields: []string{"httpGet.path"}},
+	{code: "PV186", category: CategoryFormat, fields: []string{"httpGet.path"}},
+	{code: "PV187", category: CategoryRequired, fields: []string{"httpGet.port", "tcpSocket.port"}},
+	{code: "PV188", category: CategoryType, fields: []string{"httpGet.port", "tcpSocket.port"}},
+	{code: "PV189", category: CategoryRange, fields: []string{"httpGet.port", "tcpSocket.port"}},
+	{code: "PV190", category: CategoryFormat, fields: []string{"httpGet.port", "tcpSocket.port"}},
+	{code: "PV191", category: CategoryType, fields: []string{"resources.limits", "resources.requests"}},
+	{code: "PV192", category: CategoryType, fields: []string{"resources.limits.*", "resources.requests.*"}},
+	{code: "PV193", category: CategoryRange, fields: []string{"resources.limits.*", "resources.requests.*"}},
+	{code: "PV194", category: CategoryType, fields: []string{"volumeMounts[]"}},
+	{code: "PV195", category: CategoryType, fields: []string{"volumeMounts[].readOnly"}},
+
+	// Pod specs and Deployments.
+	{code: "PV200", category: CategoryRequired, fields: []string{"volumes[].name"}},
+	{code: "PV201", category: CategoryType, fields: []string{"volumes[].name"}},
+	{code: "PV202", category: CategoryRange, fields: []string{"volumes[].name"}},
+	{code: "PV203", category: CategoryRequired, fields: []string{"template.metadata.labels"}},
+	{code: "PV204", category: CategoryCrossField, fields: []string{"template.metadata.generateName"}},
+	{code: "PV205", category: CategoryType, fields: []string{"spec.selector"}},
+	{code: "PV206", category: CategoryType, fields: []string{"spec.selector.matchExpressions"}},
+	{code: "PV207", category: CategoryRequired, fields: []string{"spec.selector.matchLabels"}},
+	{code: "PV208", category: CategoryType, fields: []string{"spec.selector.matchLabels"}},
+	{code: "PV209", category: CategoryType, fields: []string{"spec.selector.matchLabels.*"}},
+	{code: "PV210", category: CategoryType, fields: []string{"spec.replicas"}},
+	{code: "PV211", category: CategoryType, fields: []string{"spec.template"}},
+	{code: "PV212", category: CategoryRequired, fields: []string{"spec.template.spec"}},
+	{code: "PV213", category: CategoryType, fields: []string{"spec.template.spec"}},
+
+	// Lists and ConfigMaps.
+	{code: "PV220", category: CategoryRequired, fields: []string{"items"}},
+	{code: "PV221", category: CategoryRange, fields: []string{"items"}},
+	{code: "PV222", category: CategoryType, fields: []string{"items[]"}},
+	{code: "PV223", category: CategoryType, fields: []string{"data"}},
+	{code: "PV224", category: CategoryType, fields: []string{"data.*"}},
+
+	{code: "PV901", category: CategoryRequired},
+	{code: "PV902", category: CategoryType},
+	{code: "PV903", category: CategoryFormat},
+	{code: "PV904", category: CategoryRange},
+	{code: "PV905", category: CategoryEnum},
+	{code: "PV906", category: CategoryCrossField},
+	{code: "PV907", category: CategoryIO},
+	{code: "PV908", category: CategoryParse},
+}
+
+// builtinRules lists the rule IDs the built-in checks set on findings.
+var builtinRules = func() []string {
+	var out []string
+	for _, rc := range ruleCodes {
+		if rc.rule != "" && rc.rule != ruleRequire {
+			out = append(out, rc.rule)
+		}
+	}
+	return out
+}()
+
+// codePatterns holds the compiled field patterns of ruleCodes, by entry.
+var codePatterns = func() [][]*regexp.Regexp {
+	out := make([][]*regexp.Regexp, len(ruleCodes))
+	for i, rc := range ruleCodes {
+		for _, f := range rc.fields {
+			if f == "" {
+				out[i] = append(out[i], regexp.MustCompile(`^$`))
+				continue
+			}
+			pat := regexp.QuoteMeta(f)
+			if rest, ok := strings.CutSuffix(pat, `\*`); ok {
+				pat = rest + `.+`
+			}
+			pat = strings.ReplaceAll(pat, `\*`, `[^.\[\]]+`)
+			out[i] = append(out[i], regexp.MustCompile(`(?:^|\.)`+pat+`$`))
+		}
+	}
+	return out
+}()
+
+// containerListNames folds the other container lists into "containers"
+// for code lookup; their items are checked the same way.
+var containerListNames = strings.NewReplacer("initContainers", "containers", "ephemeralContainers", "containers")
+
+// ruleCodeOf returns the code of the check that reported e. Rule IDs
+// decide first, Require findings by their "require:" prefix; then the
+// field patterns of e's category, and last the category's catch-all.
+// Among the patterns the one with the most segments wins, so
+// "spec.template.spec" takes precedence over "spec".
+func ruleCodeOf(e *ValidationError) string {
+	if e.Rule != "" {
+		for _, rc := range ruleCodes {
+			if rc.rule != "" && (rc.rule == e.Rule || rc.rule == ruleRequire && strings.HasPrefix(e.Rule, ruleRequire)) {
+				return rc.code
+			}
+		}
+	}
+	field := containerListNames.Replace(listIndex.ReplaceAllString(e.Field, "[]"))
+	best, bestLen := "", -1
+	for i, rc := range ruleCodes {
+		if rc.category != e.Category || rc.fields == nil {
+			continue
+		}
+		for j, re := range codePatterns[i] {
+			if n := patternDepth(rc.fields[j]); n > bestLen && re.MatchString(field) {
+				best, bestLen = rc.code, n
+			}
+		}
+	}
+	if best != "" {
+		return best
+	}
+	for _, rc := range ruleCodes {
+		if rc.fields == nil && rc.rule == "" && rc.category == e.Category {
+			return rc.code
+		}
+	}
+	return ""
+}
+
+// patternDepth counts the segments of a field pattern, leaving out a
+// trailing "*" since it matches anything below.
+func patternDepth(pattern string) int {
+	return len(strings.Split(strings.TrimSuffix(pattern, ".*"), "."))
+}
+
+// setCodes fills in the Code of every finding.
+func setCodes(findings []*ValidationError) {
+	for _, e := range findings {
+		e.Code = ruleCodeOf(e)
+	}
+}
+
+// optInRules are the rules that report nothing unless
+// Options.EnableRules names them.
+var optInRules = map[string]bool{
+	ruleProbePortHostNetwork: true,
+	ruleProbePortUndeclared:  true,
+}
+
+// ruleOff reports whether o switches rule off: DisableRules names it,
+// or it is opt-in and EnableRules does not name it.
+func (o *Options) ruleOff(rule string) bool {
+	return ruleListed(rule, o.DisableRules) || (optInRules[rule] && !ruleListed(rule, o.EnableRules))
+}
+
+// ruleListed reports whether ids, which hold rule IDs and group names,
+// name rule: the group "windows" covers every "windows-*".
+func ruleListed(rule string, ids []string) bool {
+	if rule == "" {
+		return false
+	}
+	for _, d := range ids {
+		if rule == d || strings.HasPrefix(rule, d+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// BuiltinRules returns the rule IDs the built-in checks can report,
+// sorted. Rules from Options.Require come on top of these.
+func BuiltinRules() []string {
+	return slices.Sorted(slices.Values(builtinRules))
+}
+
+// Check describes one entry of the table of codes, for listings such
+// as the rules command.
+type Check struct {
+	Code string
+	// Rule is the rule ID the check sets on its findings; "require:"
+	// stands for every Options.Require entry with a default rule ID.
+	Rule string
+	// Category and Fields name the checks without a rule: the category
+	// of their findings and the index-free field patterns they cover.
+	// A catch-all has a category but no fields.
+	Category Category
+	Fields   []string
+}
+
+// Checks returns every check with a code, sorted by code.
+func Checks() []Check {
+	out := make([]Check, 0, len(ruleCodes))
+	for _, rc := range ruleCodes {
+		out = append(out, Check{Code: rc.code, Rule: rc.rule, Category: rc.category, Fields: slices.Clone(rc.fields)})
+	}
+	slices.SortFunc(out, func(a, b Check) int { return strings.Compare(a.Code, b.Code) })
+	return out
+}
+
+// listIndex matches the list indexes of a field path.
+var listIndex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// stableRuleID names the check behind e. Findings with a rule use it;
+// the others are named by category and index-free field path, e.g.
+// "range/spec.containers[].ports[].containerPort", which stays the same
+// across releases as long as the check does.
+func stableRuleID(e *ValidationError) string {
+	if e.Rule != "" {
+		return e.Rule
+	}
+	id := e.Category.String()
+	if e.Field != "" {
+		id += "/" + listIndex.ReplaceAllString(e.Field, "[]")
+	}
+	return id
+}
+
+// reportID names the check behind e in reports that identify checks:
+// its code, or stableRuleID for a finding built without one.
+func reportID(e *ValidationError) string {
+	if e.Code != "" {
+		return e.Code
+	}
+	return stableRuleID(e)
+}
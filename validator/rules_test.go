@@ -0,0 +1,248 @@
+package validator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRuleCodesUnique(t *testing.T) {
+	codeRe := regexp.MustCompile(`^PV\d{3}$`)
+	codes, rules, fields, catchAlls := map[string]bool{}, map[string]bool{}, map[string]string{}, map[Category]string{}
+	for _, rc := range ruleCodes {
+		if !codeRe.MatchString(rc.code) {
+			t.Errorf("code %q is not PV and three digits", rc.code)
+		}
+		if codes[rc.code] {
+			t.Errorf("code %s is listed twice", rc.code)
+		}
+		codes[rc.code] = true
+		if rc.rule != "" {
+			if rules[rc.rule] {
+				t.Errorf("rule %s has more than one code", rc.rule)
+			}
+			rules[rc.rule] = true
+			continue
+		}
+		if rc.fields == nil {
+			if prev, ok := catchAlls[rc.category]; ok {
+				t.Errorf("category %s has catch-alls %s and %s", rc.category, prev, rc.code)
+			}
+			catchAlls[rc.category] = rc.code
+			continue
+		}
+		if rc.category == 0 {
+			t.Errorf("code %s names fields but no category", rc.code)
+		}
+		for _, f := range rc.fields {
+			key := rc.category.String() + " " + f
+			if prev, ok := fields[key]; ok {
+				t.Errorf("field %q (category %s) maps to %s and %s", f, rc.category, prev, rc.code)
+			}
+			fields[key] = rc.code
+		}
+	}
+	for cat := CategoryRequired; cat <= CategoryParse; cat++ {
+		if catchAlls[cat] == "" {
+			t.Errorf("category %s has no catch-all", cat)
+		}
+	}
+}
+
+// badManifests breaks checks of every category in every kind the
+// package knows, with the opt-in rules and nested manifests on.
+const badManifests = `apiVersion: v1
+kind: Pod
+metadata:
+  name: Web_Server
+  labels: {}
+  annotations: [a]
+spec:
+  os:
+    name: windows
+  hostNetwork: "yes"
+  hostPID: true
+  defaults: &unused {}
+  volumes:
+  - name: data
+    hostPath:
+      path: /data
+  - name: data
+    emptyDir: {}
+  containers:
+  - name: Web
+    image: nginx:latest
+    ports:
+    - containerPort: 0
+      hostPort: 8080
+      protocol: tcp
+    - containerPort: http
+    resources:
+      requests:
+        memory: 1Gi
+        cpu: two
+      limits:
+        memory: 128Mi
+        gpu: 1
+    env:
+    - value: x
+    readinessProbe:
+      tcpSocket:
+        port: 9090
+    livenessProbe:
+      httpGet:
+        port: metrics
+    volumeMounts:
+    - name: data
+      mountPath: /data
+    - name: data
+      mountPath: /data
+    securityContext:
+      privileged: true
+      runAsUser: 0
+  - name: Web
+    image: NGINX::1
+    stdin: "no"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: -1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      name: web
+      labels:
+        app: api
+    spec:
+      containers: []
+---
+apiVersion: v1
+kind: List
+items:
+- 42
+- kind: Secret
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: manifests
+data:
+  pod.yaml: "key: [unclosed"
+---
+- not a mapping
+---
+kind: Pod
+`
+
+func TestFindingsHaveCodes(t *testing.T) {
+	res := mustValidate(t, "bad.yaml", badManifests, Options{Nested: true, EnableRules: []string{"probe-port"}})
+	seen := map[string]bool{}
+	// A check is its rule, or its category and field; each has one code,
+	// and each code stands for checks of one category.
+	checks, categories := map[string]string{}, map[string]Category{}
+	for _, e := range res.Findings {
+		if e.Code == "" {
+			t.Errorf("finding %s has no code", FormatText(e))
+		}
+		seen[e.Code] = true
+		if e.Category != CategoryParse && strings.HasPrefix(e.Code, "PV9") {
+			t.Errorf("finding %s of a built-in check has the catch-all code %s", FormatText(e), e.Code)
+		}
+		check := e.Rule
+		if check == "" {
+			check = e.Category.String() + " " + containerListNames.Replace(listIndex.ReplaceAllString(e.Field, "[]"))
+		}
+		if prev, ok := checks[check]; ok && prev != e.Code {
+			t.Errorf("check %q has codes %s and %s", check, prev, e.Code)
+		}
+		checks[check] = e.Code
+		if prev, ok := categories[e.Code]; ok && prev != e.Category {
+			t.Errorf("code %s covers categories %s and %s", e.Code, prev, e.Category)
+		}
+		categories[e.Code] = e.Category
+	}
+	if len(seen) < 30 {
+		t.Errorf("findings cover %d codes, want at least 30: %v", len(seen), seen)
+	}
+}
+
+func TestDistinctChecksDistinctCodes(t *testing.T) {
+	src := `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: a
+    image: nginx:1.25
+    ports: 80
+    env: x
+  - name: b
+    image: nginx:1.25
+    ports:
+    - containerPort: 0
+    - containerPort: http
+    env:
+    - value: x
+    - name: 1
+`
+	res := mustValidate(t, "pod.yaml", src, Options{})
+	codes := map[string]string{}
+	for _, e := range res.Findings {
+		codes[e.Category.String()+" "+e.Field] = e.Code
+	}
+	tests := []struct {
+		name, a, b string
+	}{
+		{"ports type and containerPort range", "type spec.containers[name=a].ports", "range spec.containers[name=b].ports[0].containerPort"},
+		{"containerPort range and type", "range spec.containers[name=b].ports[0].containerPort", "type spec.containers[name=b].ports[1].containerPort"},
+		{"env type and env name required", "type spec.containers[name=a].env", "required spec.containers[name=b].env[0].name"},
+		{"env name required and type", "required spec.containers[name=b].env[0].name", "type spec.containers[name=b].env[1].name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := codes[tt.a], codes[tt.b]
+			if a == "" || b == "" {
+				t.Fatalf("missing finding: %s=%q %s=%q in %v", tt.a, a, tt.b, b, codes)
+			}
+			if a == b {
+				t.Errorf("%s and %s share code %s", tt.a, tt.b, a)
+			}
+		})
+	}
+}
+
+func TestPolicyFindingCodes(t *testing.T) {
+	const deprecated = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+`
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		rule string
+		code string
+	}{
+		{"require default rule", validPod, Options{Require: []RequiredField{{Path: "metadata.labels.team"}}}, "require:metadata.labels.team", "PV137"},
+		{"require own rule", validPod, Options{Require: []RequiredField{{Path: "metadata.labels.team", Rule: "team-label"}}}, "team-label", "PV901"},
+		{"removed apiVersion", deprecated, Options{KubernetesVersion: KubeVersion{1, 16}}, ruleDeprecatedAPI, "PV138"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := mustValidate(t, "in.yaml", tt.src, tt.opts)
+			if len(res.Findings) != 1 {
+				t.Fatalf("%d findings, want 1: %v", len(res.Findings), res.Findings)
+			}
+			if e := res.Findings[0]; e.Rule != tt.rule || e.Code != tt.code {
+				t.Errorf("rule %q code %s, want %q %s", e.Rule, e.Code, tt.rule, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package validator
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// sarifVersion and sarifSchema identify the SARIF format written by
+// --format=sarif.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// sarifLogicalLocation names the offending field by its JSONPath.
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// NewSARIFReporter returns a Reporter writing the findings to w as a
+// SARIF log with a single run when the run ends. tool names the driver.
+func NewSARIFReporter(w io.Writer, tool string) Reporter { return &sarifReporter{w: w, tool: tool} }
+
+type sarifReporter struct {
+	w        io.Writer
+	tool     string
+	findings []*ValidationError
+}
+
+func (s *sarifReporter) Report(e *ValidationError) { s.findings = append(s.findings, e) }
+
+// Summary writes the log. Rules are identified by code and listed
+// sorted, so the descriptor does not depend on the order of the inputs;
+// each is described by the rule ID or category and field of its first
+// finding.
+func (s *sarifReporter) Summary(Stats) error {
+	w, tool, findings := s.w, s.tool, s.findings
+	var ids []string
+	descriptions := map[string]string{}
+	for _, e := range findings {
+		if id := reportID(e); !slices.Contains(ids, id) {
+			ids = append(ids, id)
+			descriptions[id] = stableRuleID(e)
+		}
+	}
+	slices.Sort(ids)
+	driver := sarifDriver{Name: tool, InformationURI: "https://github.com/abdddev/go-magistr-lesson2-tpl", Rules: []sarifRule{}}
+	for _, id := range ids {
+		driver.Rules = append(driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: descriptions[id]}})
+	}
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, e := range findings {
+		id := reportID(e)
+		level := "error"
+		if e.Severity == SeverityWarning {
+			level = "warning"
+		}
+		loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: sarifURI(e.File)}}
+		switch {
+		case e.KeyLine > 0 && e.ValueLine > 0:
+			// The region runs from the key up to its value, whichever
+			// of the two Line points at.
+			loc.Region = &sarifRegion{StartLine: e.KeyLine, StartColumn: e.KeyColumn, EndLine: e.ValueLine, EndColumn: e.ValueColumn}
+		case e.Line > 0:
+			loc.Region = &sarifRegion{StartLine: e.Line, StartColumn: e.Column}
+		}
+		r := sarifResult{
+			RuleID:    id,
+			RuleIndex: slices.Index(ids, id),
+			Level:     level,
+			Message:   sarifMessage{Text: e.Error()},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		}
+		if e.Path != "" {
+			r.Locations[0].LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: e.Path, Kind: "member"}}
+		}
+		if e.Fingerprint != "" {
+			r.PartialFingerprints = map[string]string{"findingFingerprint/v1": e.Fingerprint}
+		}
+		run.Results = append(run.Results, r)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// sarifURI turns a file name into the URI reference SARIF expects:
+// relative names stay relative, absolute ones become file URIs.
+func sarifURI(name string) string {
+	if !filepath.IsAbs(name) {
+		return filepath.ToSlash(name)
+	}
+	u := filepath.ToSlash(name)
+	if !strings.HasPrefix(u, "/") {
+		u = "/" + u // a Windows drive letter
+	}
+	return (&url.URL{Scheme: "file", Path: u}).String()
+}
@@ -0,0 +1,93 @@
+package validator
+
+// schemaNode describes the fields the validator knows about. It is
+// used to reject policy paths that could never match.
+type schemaNode struct {
+	fields map[string]*schemaNode
+	// items describes the elements of a sequence field.
+	items *schemaNode
+	// anyKey accepts arbitrary keys, as for labels.
+	anyKey bool
+}
+
+func object(fields map[string]*schemaNode) *schemaNode { return &schemaNode{fields: fields} }
+func list(items *schemaNode) *schemaNode               { return &schemaNode{items: items} }
+
+var (
+	leaf     = &schemaNode{}
+	freeform = &schemaNode{anyKey: true}
+)
+
+// Actions shared by probes and lifecycle hooks.
+var (
+	httpGetSchema = object(map[string]*schemaNode{
+		"path": leaf, "port": leaf, "host": leaf, "scheme": leaf, "httpHeaders": list(leaf),
+	})
+	tcpSocketSchema = object(map[string]*schemaNode{"port": leaf, "host": leaf})
+	execSchema      = object(map[string]*schemaNode{"command": list(leaf)})
+)
+
+// handlerSchema describes a lifecycle hook.
+var handlerSchema = object(map[string]*schemaNode{
+	"httpGet": httpGetSchema, "tcpSocket": tcpSocketSchema, "exec": execSchema,
+	"sleep": object(map[string]*schemaNode{"seconds": leaf}),
+})
+
+var probeSchema = object(map[string]*schemaNode{
+	"httpGet": httpGetSchema, "tcpSocket": tcpSocketSchema, "exec": execSchema,
+	"grpc":                object(map[string]*schemaNode{"port": leaf, "service": leaf}),
+	"initialDelaySeconds": leaf, "periodSeconds": leaf, "timeoutSeconds": leaf,
+	"successThreshold": leaf, "failureThreshold": leaf, "terminationGracePeriodSeconds": leaf,
+})
+
+var containerSchema = object(map[string]*schemaNode{
+	"name": leaf, "image": leaf, "imagePullPolicy": leaf,
+	"command": list(leaf), "args": list(leaf), "workingDir": leaf,
+	"stdin": leaf, "stdinOnce": leaf, "tty": leaf, "restartPolicy": leaf,
+	"terminationMessagePath": leaf, "terminationMessagePolicy": leaf,
+	"ports": list(object(map[string]*schemaNode{
+		"name": leaf, "containerPort": leaf, "hostPort": leaf, "hostIP": leaf, "protocol": leaf,
+	})),
+	"env": list(object(map[string]*schemaNode{
+		"name": leaf, "value": leaf, "valueFrom": freeform,
+	})),
+	"envFrom": list(freeform),
+	"resources": object(map[string]*schemaNode{
+		"limits": freeform, "requests": freeform, "claims": list(object(map[string]*schemaNode{"name": leaf})),
+	}),
+	"volumeMounts": list(object(map[string]*schemaNode{
+		"name": leaf, "mountPath": leaf, "subPath": leaf, "readOnly": leaf, "mountPropagation": leaf,
+	})),
+	"livenessProbe": probeSchema, "readinessProbe": probeSchema, "startupProbe": probeSchema,
+	"lifecycle":       object(map[string]*schemaNode{"postStart": handlerSchema, "preStop": handlerSchema}),
+	"securityContext": freeform,
+})
+
+var podSchema = object(map[string]*schemaNode{
+	"apiVersion": leaf,
+	"kind":       leaf,
+	"metadata": object(map[string]*schemaNode{
+		"name": leaf, "generateName": leaf, "namespace": leaf,
+		"labels": freeform, "annotations": freeform,
+	}),
+	"spec": object(map[string]*schemaNode{
+		"containers": list(containerSchema), "initContainers": list(containerSchema),
+		"os":          object(map[string]*schemaNode{"name": leaf}),
+		"hostNetwork": leaf, "hostPID": leaf, "hostIPC": leaf,
+		"restartPolicy": leaf, "dnsPolicy": leaf, "serviceAccountName": leaf,
+		"nodeSelector": freeform, "nodeName": leaf, "priorityClassName": leaf,
+		"terminationGracePeriodSeconds": leaf, "securityContext": freeform,
+		"volumes": list(freeform), "tolerations": list(freeform), "affinity": freeform,
+		"imagePullSecrets": list(object(map[string]*schemaNode{"name": leaf})),
+		"resourceClaims":   list(freeform), "topologySpreadConstraints": list(freeform),
+	}),
+})
+
+// child returns the schema of key below s, or nil when s has no such
+// field.
+func (s *schemaNode) child(key string) *schemaNode {
+	if s.anyKey {
+		return freeform
+	}
+	return s.fields[key]
+}
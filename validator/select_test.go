@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// twoContainerPod has findings in metadata and in both of its
+// containers.
+const twoContainerPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: Bad_Name
+spec:
+  containers:
+  - name: web
+    image: nginx:1.25
+    ports:
+    - containerPort: 70000
+  - name: sidecar
+    ports:
+    - containerPort: 0
+`
+
+func TestSelectContainerByName(t *testing.T) {
+	all := mustValidate(t, "pod.yaml", twoContainerPod, Options{})
+	res := mustValidate(t, "pod.yaml", twoContainerPod, Options{Select: "spec.containers[name=web]"})
+	if len(res.Findings) == 0 {
+		t.Fatal("no findings under spec.containers[name=web]")
+	}
+	if len(res.Findings) >= len(all.Findings) {
+		t.Errorf("Select kept %d of %d findings, want fewer", len(res.Findings), len(all.Findings))
+	}
+	for _, e := range res.Findings {
+		if !strings.HasPrefix(e.Field, "spec.containers[name=web].") {
+			t.Errorf("finding %q at %s lies outside the selection", e.Message, e.Field)
+		}
+	}
+	var want int
+	for _, e := range all.Findings {
+		if strings.HasPrefix(e.Field, "spec.containers[name=web].") {
+			want++
+		}
+	}
+	if len(res.Findings) != want {
+		t.Errorf("Select kept %d findings, want the %d under the container", len(res.Findings), want)
+	}
+}
+
+func TestSelectDoesNotResolve(t *testing.T) {
+	for _, expr := range []string{"spec.containers[name=db]", "spec.containers[5]", "spec.volumes", "spec.containers["} {
+		t.Run(expr, func(t *testing.T) {
+			res, err := ValidateBytes(context.Background(), "pod.yaml", []byte(twoContainerPod), Options{Select: expr})
+			if !errors.Is(err, ErrBadSelector) {
+				t.Fatalf("err = %v, want one wrapping ErrBadSelector", err)
+			}
+			if res != nil {
+				t.Errorf("got a Result along with error %v", err)
+			}
+		})
+	}
+}
+
+func TestSelectResolvesInOneDocument(t *testing.T) {
+	src := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n---\n" + twoContainerPod
+	res := mustValidate(t, "in.yaml", src, Options{Select: "spec.containers[name=sidecar]"})
+	if len(res.Documents) != 1 || res.Documents[0].Kind != "Pod" {
+		t.Errorf("Documents = %+v, want the Pod alone", res.Documents)
+	}
+	for _, e := range res.Findings {
+		if !strings.HasPrefix(e.Field, "spec.containers[name=sidecar].") {
+			t.Errorf("finding %q at %s lies outside the selection", e.Message, e.Field)
+		}
+	}
+}
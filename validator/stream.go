@@ -0,0 +1,190 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// streamBufferSize is the read buffer of each stage of a source.
+const streamBufferSize = 64 << 10
+
+// source is the stream of bytes validate decodes documents from: the
+// input, decompressed, held to the size limit and checked to be UTF-8
+// as it is read, so that only the document being decoded needs to be
+// in memory. Its first read failure is kept in err, since the YAML
+// decoder passes read errors on as text only. The lines read since the
+// start of the last document decoded are kept as well, for
+// explainParseError.
+type source struct {
+	r        io.Reader
+	err      error
+	n        int64  // bytes read
+	tail     []byte // the bytes read from line tailLine on
+	tailLine int
+}
+
+// openSource prepares r, the input called name, for decoding. Input in
+// UTF-16 or UTF-32 is read whole to be transcoded (see toUTF8); every
+// other input is streamed.
+func openSource(name string, r io.Reader, limit int64) (*source, error) {
+	ioErr := func(err error) error { return fmt.Errorf("%s: %w: %w", name, ErrIO, err) }
+	br := bufio.NewReaderSize(mapErr{r, ioErr}, streamBufferSize)
+	head, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if IsGzip(name, head) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, gzipError(name, err)
+		}
+		br = bufio.NewReaderSize(mapErr{zr, func(err error) error { return gzipError(name, err) }}, streamBufferSize)
+	}
+	if limit > 0 {
+		br = bufio.NewReaderSize(&sizeCap{r: br, name: name, limit: limit, left: limit}, streamBufferSize)
+	}
+	if head, err = br.Peek(4); err != nil && err != io.EOF {
+		return nil, err
+	}
+	transcode := false
+	for _, b := range boms {
+		if bytes.HasPrefix(head, b.bom) {
+			transcode = b.width > 1
+			if !transcode {
+				br.Discard(len(b.bom))
+			}
+			break
+		}
+	}
+	// Without a BOM, ASCII text in UTF-16 shows up as alternating NULs.
+	if head, _ = br.Peek(2); len(head) == 2 && (head[0] == 0) != (head[1] == 0) {
+		transcode = true
+	}
+	if !transcode {
+		return &source{r: &utf8Checker{r: br, name: name, line: 1}, tailLine: 1}, nil
+	}
+	data, err := io.ReadAll(br)
+	if err == nil {
+		data, err = toUTF8(name, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &source{r: bytes.NewReader(data), tailLine: 1}, nil
+}
+
+func (s *source) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.n += int64(n)
+	s.tail = append(s.tail, p[:n]...)
+	if err != nil && err != io.EOF && s.err == nil {
+		s.err = err
+	}
+	return n, err
+}
+
+// forget drops the kept lines before line.
+func (s *source) forget(line int) {
+	for s.tailLine < line {
+		i := bytes.IndexByte(s.tail, '\n')
+		if i < 0 {
+			return
+		}
+		s.tail = s.tail[i+1:]
+		s.tailLine++
+	}
+}
+
+// mapErr passes reads on to r, turning read failures into what fn
+// returns for them.
+type mapErr struct {
+	r  io.Reader
+	fn func(error) error
+}
+
+func (m mapErr) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = m.fn(err)
+	}
+	return n, err
+}
+
+// sizeCap fails with a SizeError once r turns out to hold more than
+// limit bytes.
+type sizeCap struct {
+	r           io.Reader
+	name        string
+	limit, left int64
+}
+
+func (c *sizeCap) Read(p []byte) (int, error) {
+	if c.left <= 0 {
+		// Only a stream that goes on past the limit is too large.
+		var probe [1]byte
+		if n, err := c.r.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+		return 0, &SizeError{Name: c.name, Limit: c.limit}
+	}
+	if int64(len(p)) > c.left {
+		p = p[:c.left]
+	}
+	n, err := c.r.Read(p)
+	c.left -= int64(n)
+	return n, err
+}
+
+// utf8Checker passes r on, failing with an EncodingError, placed like
+// toUTF8 places it, at the first byte that does not start a valid UTF-8
+// sequence.
+type utf8Checker struct {
+	r    *bufio.Reader
+	name string
+	off  int64 // offset of the next byte read
+	line int   // line of the next byte read
+	skip int   // leading bytes of the next read that end a sequence already checked
+	err  error
+}
+
+func (u *utf8Checker) Read(p []byte) (int, error) {
+	if u.err != nil {
+		return 0, u.err
+	}
+	n, err := u.r.Read(p)
+	data := p[:n]
+	i := min(u.skip, n)
+	u.skip -= i
+	if !utf8.Valid(data[i:]) {
+		for i < n {
+			if data[i] < utf8.RuneSelf {
+				i++
+				continue
+			}
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size <= 1 && !utf8.FullRune(data[i:]) {
+				// The sequence goes on past this read: check it whole
+				// and let the next read skip the rest of it.
+				rest, _ := u.r.Peek(utf8.UTFMax - (n - i))
+				seq := append(bytes.Clone(data[i:]), rest...)
+				if r, size = utf8.DecodeRune(seq); size > n-i {
+					u.skip = size - (n - i)
+					break
+				}
+			}
+			if r == utf8.RuneError && size <= 1 {
+				u.err = &EncodingError{Name: u.name, Line: u.line + bytes.Count(data[:i], []byte{'\n'}),
+					Message: fmt.Sprintf("invalid UTF-8 sequence at byte offset %d; please save as UTF-8", u.off+int64(i))}
+				return 0, u.err
+			}
+			i += size
+		}
+	}
+	u.off += int64(n)
+	u.line += bytes.Count(data, []byte{'\n'})
+	return n, err
+}
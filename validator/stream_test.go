@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+// badPort is validPod with a port out of range, so that it has exactly
+// one finding, and a non-ASCII comment.
+var badPort = strings.Replace(validPod, "containerPort: 80", "containerPort: 0 # порт", 1)
+
+func encode16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = order.AppendUint16(out, 0xFEFF)
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = order.AppendUint16(out, u)
+	}
+	return out
+}
+
+func encode32(s string, order binary.AppendByteOrder) []byte {
+	out := order.AppendUint32(nil, 0xFEFF)
+	for _, r := range s {
+		out = order.AppendUint32(out, uint32(r))
+	}
+	return out
+}
+
+func gzipped(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestSourceEncodings(t *testing.T) {
+	want := mustValidate(t, "in.yaml", badPort, Options{})
+	if len(want.Findings) != 1 {
+		t.Fatalf("badPort has findings %v, want one", want.Findings)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		line int // of the EncodingError; -1 when the input validates
+		msg  string
+	}{
+		{"UTF-8", []byte(badPort), -1, ""},
+		{"UTF-8 BOM", append([]byte("\xef\xbb\xbf"), badPort...), -1, ""},
+		{"UTF-16LE BOM", encode16(badPort, binary.LittleEndian, true), -1, ""},
+		{"UTF-16BE BOM", encode16(badPort, binary.BigEndian, true), -1, ""},
+		{"UTF-32LE BOM", encode32(badPort, binary.LittleEndian), -1, ""},
+		{"UTF-32BE BOM", encode32(badPort, binary.BigEndian), -1, ""},
+		{"gzip", gzipped([]byte(badPort)), -1, ""},
+		{"gzip UTF-16LE BOM", gzipped(encode16(badPort, binary.LittleEndian, true)), -1, ""},
+		{"UTF-16LE without BOM", encode16(badPort, binary.LittleEndian, false), 0, "file appears to be UTF-16 encoded; please save as UTF-8"},
+		{"UTF-16BE without BOM", encode16(badPort, binary.BigEndian, false), 0, "file appears to be UTF-16 encoded; please save as UTF-8"},
+		{"UTF-16LE odd length", append(encode16(badPort, binary.LittleEndian, true), 'x'), 0,
+			"file appears to be UTF-16LE encoded but has an odd length; please save as UTF-8"},
+		{"UTF-32LE truncated", encode32(badPort, binary.LittleEndian)[:41], 0,
+			"file appears to be UTF-32LE encoded but has a truncated code point; please save as UTF-8"},
+		{"invalid UTF-8", []byte("a: 1\nb: 2\nc: \xff\n"), 3, "invalid UTF-8 sequence at byte offset 13; please save as UTF-8"},
+		{"truncated UTF-8 sequence", []byte("a: 1\nb: \xd0\n"), 2, "invalid UTF-8 sequence at byte offset 8; please save as UTF-8"},
+		{"invalid UTF-8 after BOM", []byte("\xef\xbb\xbfa: \xc0\xaf\n"), 1, "invalid UTF-8 sequence at byte offset 3; please save as UTF-8"},
+		{"invalid UTF-8 in gzip", gzipped([]byte("a: 1\nb: \xff\n")), 2, "invalid UTF-8 sequence at byte offset 8; please save as UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, read := range []string{"whole", "byte by byte"} {
+				var res *Result
+				var err error
+				if read == "whole" {
+					res, err = ValidateBytes(context.Background(), "in.yaml", tt.data, Options{})
+				} else {
+					// Multi-byte sequences and BOMs span reads.
+					res, err = ValidateReader(context.Background(), "in.yaml", iotest.OneByteReader(bytes.NewReader(tt.data)), Options{})
+				}
+				if tt.line < 0 {
+					if err != nil {
+						t.Fatalf("%s: %v", read, err)
+					}
+					if len(res.Findings) != 1 || !sameFinding(res.Findings[0], want.Findings[0]) {
+						t.Errorf("%s: findings %+v, want %+v", read, res.Findings, want.Findings)
+					}
+					continue
+				}
+				var ee *EncodingError
+				if !errors.As(err, &ee) {
+					t.Fatalf("%s: err = %v, want an *EncodingError", read, err)
+				}
+				if ee.Line != tt.line || ee.Message != tt.msg {
+					t.Errorf("%s: EncodingError line %d %q, want line %d %q", read, ee.Line, ee.Message, tt.line, tt.msg)
+				}
+			}
+		})
+	}
+}
+
+// sameFinding reports whether a and b agree on what and where.
+func sameFinding(a, b *ValidationError) bool {
+	return a.Line == b.Line && a.Column == b.Column && a.Field == b.Field && a.Message == b.Message && a.Code == b.Code
+}
+
+// largeStream is a multi-thousand-line stream of valid Pods and
+// Deployments with a Pod with findings every tenth document.
+var largeStream = func() []byte {
+	var buf bytes.Buffer
+	for i := range 3000 {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		switch {
+		case i%10 == 9:
+			buf.WriteString(identicalFindingsPod)
+		case i%2 == 1:
+			buf.WriteString(deployment)
+		default:
+			buf.WriteString(validPod)
+		}
+	}
+	return buf.Bytes()
+}()
+
+// BenchmarkValidateLarge validates largeStream read as a stream, the
+// way the CLI reads files.
+func BenchmarkValidateLarge(b *testing.B) {
+	b.SetBytes(int64(len(largeStream)))
+	b.ReportAllocs()
+	for b.Loop() {
+		res, err := ValidateReader(context.Background(), "large.yaml", bytes.NewReader(largeStream), Options{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(res.Documents) != 3000 {
+			b.Fatalf("%d documents, want 3000", len(res.Documents))
+		}
+	}
+}
+
+// TestMaxFileSizeGzip checks that the limit holds the decompressed
+// content of a gzip input, which here is small on disk.
+func TestMaxFileSizeGzip(t *testing.T) {
+	padded := validPod + strings.Repeat("# padding\n", 1000)
+	data := gzipped([]byte(padded))
+	const limit = 4096
+	if len(data) >= limit || len(padded) <= limit {
+		t.Fatalf("%d bytes compressed, %d inflated; want under and over %d", len(data), len(padded), limit)
+	}
+	path := filepath.Join(t.TempDir(), "pod.yaml.gz")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries := map[string]func(Options) (*Result, error){
+		"ValidateFile":  func(o Options) (*Result, error) { return ValidateFile(context.Background(), path, o) },
+		"ValidateBytes": func(o Options) (*Result, error) { return ValidateBytes(context.Background(), path, data, o) },
+		"ValidateReader": func(o Options) (*Result, error) {
+			return ValidateReader(context.Background(), path, bytes.NewReader(data), o)
+		},
+	}
+	for name, validate := range entries {
+		t.Run(name, func(t *testing.T) {
+			_, err := validate(Options{MaxFileSize: limit})
+			var se *SizeError
+			if !errors.As(err, &se) || !errors.Is(err, ErrTooLarge) || se.Limit != limit {
+				t.Errorf("err = %v, want a *SizeError for %d bytes", err, limit)
+			}
+			res, err := validate(Options{MaxFileSize: int64(len(padded))})
+			if err != nil || len(res.Findings) > 0 {
+				t.Errorf("at the inflated size: err %v, findings %v", err, res.Findings)
+			}
+		})
+	}
+}
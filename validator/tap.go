@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewTAPReporter returns a Reporter writing a TAP version 13 stream to w
+// when the run ends: one test point per input, "not ok" when it has
+// error findings, with every finding in the text format as a diagnostic
+// line below it. The
+// plan comes last, as TAP allows, since the number of inputs is only
+// known then.
+func NewTAPReporter(w io.Writer) FileReporter { return &tapReporter{w: w, index: map[string]int{}} }
+
+type tapReporter struct {
+	w     io.Writer
+	files []tapPoint
+	index map[string]int
+}
+
+type tapPoint struct {
+	name     string
+	findings []*ValidationError
+}
+
+func (t *tapReporter) StartFile(name string) { t.point(name) }
+
+func (t *tapReporter) Report(e *ValidationError) {
+	p := t.point(e.File)
+	p.findings = append(p.findings, e)
+}
+
+func (t *tapReporter) point(name string) *tapPoint {
+	i, ok := t.index[name]
+	if !ok {
+		i = len(t.files)
+		t.index[name] = i
+		t.files = append(t.files, tapPoint{name: name})
+	}
+	return &t.files[i]
+}
+
+func (t *tapReporter) Summary(s Stats) error {
+	b := bufio.NewWriter(t.w)
+	fmt.Fprintln(b, "TAP version 13")
+	for i, p := range t.files {
+		status := "ok"
+		for _, e := range p.findings {
+			if e.Severity == SeverityError {
+				status = "not ok"
+			}
+		}
+		fmt.Fprintf(b, "%s %d - %s\n", status, i+1, tapEscape(p.name))
+		for _, e := range p.findings {
+			fmt.Fprintf(b, "# %s\n", strings.ReplaceAll(FormatText(e), "\n", " "))
+		}
+	}
+	if s.Omitted > 0 {
+		fmt.Fprintf(b, "# %s\n", s.OmittedNotice())
+	}
+	fmt.Fprintf(b, "1..%d\n", len(t.files))
+	return b.Flush()
+}
+
+// tapEscape keeps a description from being read as a directive.
+func tapEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "#", `\#`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// trace collects what was checked on each field of one document and
+// how it turned out, for the debug log. A nil trace records nothing.
+type trace struct {
+	log    *slog.Logger
+	fields []string // in the order they were first checked
+	notes  map[string][]string
+}
+
+// newTrace returns a trace logging to log, or nil when log discards
+// debug records.
+func newTrace(log *slog.Logger) *trace {
+	if !log.Enabled(context.Background(), slog.LevelDebug) {
+		return nil
+	}
+	return &trace{log: log, notes: make(map[string][]string)}
+}
+
+// note records outcome, such as "tag ok", for field.
+func (t *trace) note(field, outcome string) {
+	if t == nil {
+		return
+	}
+	if _, ok := t.notes[field]; !ok {
+		t.fields = append(t.fields, field)
+	}
+	t.notes[field] = append(t.notes[field], outcome)
+}
+
+// finding notes e as the outcome of a check on its field: the rule or
+// category that fired and its severity, or that the rule is disabled.
+func (t *trace) finding(e *ValidationError, off bool) {
+	if t == nil {
+		return
+	}
+	label := e.Rule
+	if label == "" {
+		label = e.Category.String()
+	}
+	switch {
+	case off:
+		t.note(e.Field, label+" disabled")
+	case e.Severity == SeverityWarning:
+		t.note(e.Field, label+" warning")
+	default:
+		t.note(e.Field, label+" error")
+	}
+}
+
+// flush logs one "checked" record per field, such as
+// field=spec.containers[0].image result="string ok, tag ok".
+func (t *trace) flush() {
+	if t == nil {
+		return
+	}
+	for _, f := range t.fields {
+		t.log.Debug("checked", "field", displayPath(f), "result", strings.Join(t.notes[f], ", "))
+	}
+}
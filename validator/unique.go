@@ -0,0 +1,107 @@
+package validator
+
+import "gopkg.in/yaml.v3"
+
+// Rule IDs of the uniqueness checks.
+const (
+	ruleUniqueContainerName = "unique-container-name"
+	ruleUniqueVolumeName    = "unique-volume-name"
+	ruleUniqueMountPath     = "unique-mount-path"
+	ruleUniqueClaimName     = "unique-resource-claim-name"
+	ruleUniqueSpread        = "unique-topology-spread"
+)
+
+// keyedItem is a list element that must not share its key with an
+// earlier element of the same list.
+type keyedItem struct {
+	key   string
+	field string     // reported field, e.g. "spec.volumes[2].name"
+	node  *yaml.Node // positions the finding
+	what  text       // the key as the message shows it, e.g. "value 'data'"
+}
+
+// unique reports each item whose key repeats an earlier item's, at the
+// repeat, with a reference to the first occurrence. Items with an empty
+// key are left to the per-item checks.
+func (c *checker) unique(rule string, items []keyedItem) {
+	first := map[string]keyedItem{}
+	for _, it := range items {
+		if it.key == "" {
+			continue
+		}
+		prev, ok := first[it.key]
+		if !ok {
+			first[it.key] = it
+			continue
+		}
+		e := crossField(it.field, it.node, "has duplicate %s (first used at %s)", it.what, describePosition(prev.field, prev.node))
+		e.Rule = rule
+		c.report(e)
+	}
+}
+
+// itemsByKey extracts key from each mapping item of list, found at
+// path, as a scalar field keyed items can be checked on.
+func itemsByKey(list []*yaml.Node, path, key string) []keyedItem {
+	var out []keyedItem
+	for i, it := range list {
+		n := getField(it, key)
+		if n == nil || n.Kind != yaml.ScalarNode {
+			continue
+		}
+		out = append(out, scalarItem(joinKey(joinIndex(path, i), key), n))
+	}
+	return out
+}
+
+// scalarItem is the keyedItem for scalar n found at field.
+func scalarItem(field string, n *yaml.Node) keyedItem {
+	return keyedItem{key: n.Value, field: field, node: n, what: textf("value '%s'", n.Value)}
+}
+
+func describePosition(field string, n *yaml.Node) text {
+	if n == nil || n.Line == 0 {
+		return textf("%s", field)
+	}
+	return textf("%s, line %d", field, n.Line)
+}
+
+// podSpecUnique checks the name-keyed lists of a pod spec other than
+// the containers themselves.
+func (c *checker) podSpecUnique(spec *yaml.Node, path string) {
+	c.unique(ruleUniqueVolumeName, itemsByKey(indexedItems(spec, "volumes"), joinKey(path, "volumes"), "name"))
+	c.unique(ruleUniqueClaimName, itemsByKey(indexedItems(spec, "resourceClaims"), joinKey(path, "resourceClaims"), "name"))
+
+	// The scheduler keys spread constraints on the pair.
+	var spread []keyedItem
+	tscPath := joinKey(path, "topologySpreadConstraints")
+	for i, it := range indexedItems(spec, "topologySpreadConstraints") {
+		tk, wu := getField(it, "topologyKey"), getField(it, "whenUnsatisfiable")
+		if tk == nil || wu == nil || tk.Kind != yaml.ScalarNode || wu.Kind != yaml.ScalarNode {
+			continue
+		}
+		spread = append(spread, keyedItem{
+			key:   tk.Value + "\x00" + wu.Value,
+			field: joinIndex(tscPath, i),
+			node:  it,
+			what:  textf("topologyKey '%s' with whenUnsatisfiable '%s'", tk.Value, wu.Value),
+		})
+	}
+	c.unique(ruleUniqueSpread, spread)
+}
+
+// indexedItems returns the elements of the sequence at key in m, with
+// non-mapping elements kept as nil so that indexes match the source.
+func indexedItems(m *yaml.Node, key string) []*yaml.Node {
+	seq := getField(m, key)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return nil
+	}
+	out := make([]*yaml.Node, len(seq.Content))
+	for i, it := range seq.Content {
+		if it.Kind == yaml.MappingNode {
+			out[i] = it
+		}
+	}
+	return out
+}
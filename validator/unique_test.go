@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUniqueLists(t *testing.T) {
+	const volumes = "  volumes:\n  - name: data\n    emptyDir: {}\n  - name: logs\n    emptyDir: {}\n"
+	tests := []struct {
+		name string
+		rule string
+		src  string
+		want []string // "line: field message" of each finding of rule
+	}{
+		{"container names", ruleUniqueContainerName,
+			validPod + "  - name: web\n    image: nginx:1.25\n",
+			[]string{"11: spec.containers[1].name has duplicate value 'web' (first used at spec.containers[0].name, line 7)"}},
+		{"volume names", ruleUniqueVolumeName,
+			validPod + volumes + "  - name: data\n    emptyDir: {}\n",
+			[]string{"16: spec.volumes[2].name has duplicate value 'data' (first used at spec.volumes[0].name, line 12)"}},
+		{"distinct volume names", ruleUniqueVolumeName, validPod + volumes, nil},
+		{"mount paths", ruleUniqueMountPath,
+			validPod + "    volumeMounts:\n    - name: data\n      mountPath: /data\n    - name: logs\n      mountPath: /logs\n    - name: logs\n      mountPath: /data\n" + volumes,
+			[]string{"17: spec.containers[name=web].volumeMounts[2].mountPath has duplicate value '/data' (first used at spec.containers[name=web].volumeMounts[0].mountPath, line 13)"}},
+		{"resource claim names", ruleUniqueClaimName,
+			validPod + "  resourceClaims:\n  - name: gpu\n  - name: fpga\n  - name: gpu\n",
+			[]string{"14: spec.resourceClaims[2].name has duplicate value 'gpu' (first used at spec.resourceClaims[0].name, line 12)"}},
+		{"topology spread pairs", ruleUniqueSpread,
+			validPod + "  topologySpreadConstraints:\n" +
+				"  - {maxSkew: 1, topologyKey: zone, whenUnsatisfiable: DoNotSchedule}\n" +
+				"  - {maxSkew: 1, topologyKey: zone, whenUnsatisfiable: ScheduleAnyway}\n" +
+				"  - {maxSkew: 2, topologyKey: zone, whenUnsatisfiable: DoNotSchedule}\n",
+			[]string{"14: spec.topologySpreadConstraints[2] has duplicate topologyKey 'zone' with whenUnsatisfiable 'DoNotSchedule' (first used at spec.topologySpreadConstraints[0], line 12)"}},
+		{"every repeat of one key", ruleUniqueVolumeName,
+			validPod + volumes + "  - name: data\n    emptyDir: {}\n  - name: data\n    emptyDir: {}\n",
+			[]string{
+				"16: spec.volumes[2].name has duplicate value 'data' (first used at spec.volumes[0].name, line 12)",
+				"18: spec.volumes[3].name has duplicate value 'data' (first used at spec.volumes[0].name, line 12)",
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := mustValidate(t, "pod.yaml", tt.src, Options{})
+			var got []string
+			for _, e := range res.ByRule(tt.rule) {
+				got = append(got, fmt.Sprintf("%d: %s", e.Line, e))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("findings\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Package validator checks Kubernetes manifests written in YAML.
+package validator
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMaxFileSize is the input size limit applied when Options are
+// built by the CLI without an explicit --max-file-size.
+const DefaultMaxFileSize = 10 << 20
+
+// Options configures a validation run.
+type Options struct {
+	// MaxFileSize caps the number of bytes accepted from a single input.
+	// Zero disables the check.
+	MaxFileSize int64
+
+	// Registry resolves document kinds to validators. A nil Registry
+	// uses the built-in kinds, which are all the CLI knows.
+	Registry *Registry
+
+	// Select restricts findings to the subtree addressed by a path
+	// expression such as "spec.containers[2]" or
+	// "spec.containers[name=web]". Findings keep their full paths. It
+	// filters rather than narrows: every check still runs on the whole
+	// document, since checks such as the ones across fields need the
+	// rest of it, so validating with Select costs as much as without. An
+	// expression that does not resolve is an error wrapping
+	// ErrBadSelector, returned before any check runs.
+	Select string
+
+	// CoerceScalars accepts plain ints, floats and bools where a free-form
+	// string is required (names, labels, annotations, env values) and
+	// reports a warning suggesting explicit quoting instead of an error.
+	// Enum fields are never coerced.
+	CoerceScalars bool
+
+	// Nested validates the data values of v1 ConfigMaps whose keys end
+	// in .yaml or .yml as manifests of their own, naming the input of
+	// their findings "FILE[data/KEY]" and counting lines within the
+	// value. Without it ConfigMaps are an unsupported kind.
+	Nested bool
+
+	// ImagePolicy restricts image registries; nil allows any.
+	ImagePolicy *ImagePolicy
+
+	// ImageLock requires images of the repositories it lists to pin the
+	// locked digest; nil checks nothing. See also ApplyFixes.
+	ImageLock *ImageLock
+
+	// Require lists additional fields every Pod must set, and with them
+	// the pod template of every Deployment, under spec.template. Entries
+	// not yet compiled are compiled for each validation, into a copy;
+	// the ones that do not compile are ignored.
+	Require []RequiredField
+
+	// DisableRules switches off findings by rule ID or by a group of
+	// rules, named by a dash-separated prefix of their IDs: "windows"
+	// covers "windows-privileged" and the rest of the Windows checks.
+	DisableRules []string
+
+	// EnableRules switches on opt-in rules, which report nothing
+	// otherwise, named like DisableRules. DisableRules wins over it.
+	EnableRules []string
+
+	// Kinds, when set, limits validation to documents of the listed
+	// kinds, given as "Deployment" or "apps/Deployment". SkipKinds
+	// leaves documents of the listed kinds out. Left-out documents yield
+	// a Result with Skipped set and no findings, even when their kind is
+	// unsupported. See CheckKindFilters.
+	Kinds, SkipKinds []string
+
+	// Limits bounds list and map sizes; nil applies DefaultLimits.
+	Limits *Limits
+
+	// KubernetesVersion enables deprecation and removal findings for
+	// apiVersions and fields as of that release. Zero disables them.
+	KubernetesVersion KubeVersion
+
+	// Logger receives operational logs. Findings are never logged; they
+	// are returned to the caller, but at debug level every checked field
+	// gets a "checked" record stating each check and its outcome. A nil
+	// Logger discards everything.
+	Logger *slog.Logger
+}
+
+func (o *Options) logger() *slog.Logger {
+	if o.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return o.Logger
+}
+
+// ValidateFile reads the file at path and validates it with the path as
+// its name. Regular files larger than opts.MaxFileSize are refused
+// before any content is read. Read failures wrap ErrIO.
+//
+// Every entry point decompresses gzip input (see Decompress) before
+// validating it, holding the content to opts.MaxFileSize as well;
+// findings keep the name of the compressed input.
+func ValidateFile(ctx context.Context, path string, opts Options) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIO, err)
+	}
+	defer f.Close()
+	if opts.MaxFileSize > 0 {
+		if st, err := f.Stat(); err == nil && st.Mode().IsRegular() && st.Size() > opts.MaxFileSize {
+			return nil, &SizeError{Name: path, Limit: opts.MaxFileSize}
+		}
+	}
+	return ValidateReader(ctx, path, f, opts)
+}
+
+// ValidateReader reads r to EOF and validates the content under name.
+// Documents are validated as they are decoded, so memory use grows with
+// the largest document rather than with the input; reading stops with
+// a SizeError past opts.MaxFileSize bytes. Read failures wrap ErrIO.
+func ValidateReader(ctx context.Context, name string, r io.Reader, opts Options) (*Result, error) {
+	return validate(ctx, name, r, opts)
+}
+
+// ValidateBytes validates data under name. The name labels the Result
+// and every finding in it.
+func ValidateBytes(ctx context.Context, name string, data []byte, opts Options) (*Result, error) {
+	if opts.MaxFileSize > 0 && int64(len(data)) > opts.MaxFileSize {
+		opts.logger().Debug("input refused", "file", name, "bytes", len(data), "limit", opts.MaxFileSize)
+		return nil, &SizeError{Name: name, Limit: opts.MaxFileSize}
+	}
+	return validate(ctx, name, bytes.NewReader(data), opts)
+}
+
+// validate is the core shared by every entry point. Every document of
+// the stream read from r is validated; empty ones, such as a trailing
+// "---" or a document of comments, are skipped, and documents are
+// indexed in the order they are validated. The error result is
+// reserved for inputs that cannot be validated at all, such as
+// ErrNotYAML or ErrEmptyDocument.
+func validate(ctx context.Context, name string, r io.Reader, opts Options) (*Result, error) {
+	log := opts.logger().With("file", name)
+	opts.Logger = log
+	opts.Require = compileRequired(opts.Require)
+	start := time.Now()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	src, err := openSource(name, r, opts.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{File: name}
+	resources := make(map[int]string)
+	var selectErr error
+	docs := 0
+	dec := yaml.NewDecoder(src)
+	for pos := 1; ; pos++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var root yaml.Node
+		err := dec.Decode(&root)
+		if src.err != nil {
+			return nil, src.err
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			log.Debug("parse failed", "position", pos, "error", err)
+			return nil, fmt.Errorf("%s: %w: %w", name, ErrNotYAML, explainParseError(src.tail, src.tailLine, err))
+		}
+		src.forget(root.Line)
+		if len(root.Content) == 0 || isNull(root.Content[0]) {
+			log.Debug("skipping empty document", "position", pos)
+			continue
+		}
+		docs++
+		doc := root.Content[0]
+		if kind, ok := opts.kindSelected(doc); !ok {
+			log.Debug("skipped by kind filter", "position", pos, "kind", kind)
+			continue
+		}
+		findings, err := validateDocument(doc, &opts)
+		if err != nil {
+			// A selector need only resolve in one of the documents.
+			log.Debug("selector does not resolve", "position", pos, "error", err)
+			selectErr = cmp.Or(selectErr, err)
+			continue
+		}
+		i := len(res.Documents) + 1
+		res.Documents = append(res.Documents, describeDocument(i, doc))
+		resources[i] = resourceID(doc)
+		for _, e := range findings {
+			e.File, e.Document = name, i
+			if e.embedded != "" {
+				e.File += "[" + e.embedded + "]"
+			}
+		}
+		res.Findings = append(res.Findings, findings...)
+	}
+	switch {
+	case docs == 0:
+		return nil, fmt.Errorf("%s: %w", name, ErrEmptyDocument)
+	case len(res.Documents) == 0 && selectErr != nil:
+		return nil, selectErr
+	case len(res.Documents) == 0:
+		return &Result{File: name, Skipped: true}, nil
+	}
+	if docs > 1 {
+		for _, e := range res.Findings {
+			e.Resource = res.Documents[e.Document-1].Label()
+		}
+	}
+	setCodes(res.Findings)
+	res.Sort()
+	res.Findings = dedupe(res.Findings)
+	res.setFingerprints(resources)
+	log.Debug("validated", "bytes", src.n, "documents", len(res.Documents), "findings", len(res.Findings), "duration", time.Since(start))
+	return res, nil
+}
+
+// validateDocument returns the findings about doc, the top-level node
+// of one document, that opts asks for, with their fields' JSONPaths.
+func validateDocument(doc *yaml.Node, opts *Options) ([]*ValidationError, error) {
+	var selected string
+	if opts.Select != "" {
+		_, path, err := resolvePath(doc, opts.Select)
+		if err != nil {
+			return nil, err
+		}
+		selected = path
+	}
+	r := &Result{Findings: append(validateTopLevel(doc, opts), unusedAnchors(doc)...)}
+	positionAtKeys(doc, r.Findings)
+	r.Findings = r.filter(func(e *ValidationError) bool { return !opts.ruleOff(e.Rule) })
+	if opts.Select != "" {
+		r.Findings = r.ByPath(selected)
+	}
+	if len(r.Findings) > 0 {
+		setPaths(NewPathIndex(doc), r.Findings)
+	}
+	return r.Findings, nil
+}
+
+// describeDocument returns the Document for doc, found at index in its
+// input.
+func describeDocument(index int, doc *yaml.Node) Document {
+	d := Document{Index: index, Kind: scalarValue(getField(doc, "kind")),
+		Name: scalarValue(getField(getField(doc, "metadata"), "name"))}
+	keys, ok := podTemplatePaths[d.Kind]
+	if !ok {
+		return d
+	}
+	tmpl := doc
+	for _, k := range keys {
+		tmpl = getField(tmpl, k)
+	}
+	for _, ctr := range mappingItems(getField(tmpl, "spec"), "containers") {
+		d.Containers++
+		d.Ports += len(mappingItems(ctr, "ports"))
+	}
+	return d
+}
+
+func validateTopLevel(doc *yaml.Node, opts *Options) []*ValidationError {
+	if doc.Kind != yaml.MappingNode {
+		return []*ValidationError{newError(CategoryType, "", doc, "document must be a mapping (found %s)", describeNode(doc))}
+	}
+	var errs []*ValidationError
+	h := Helpers{opts: opts, trace: newTrace(opts.logger())}
+	defer h.trace.flush()
+	validateObject(doc, h, func(e *ValidationError) { errs = append(errs, e) })
+	return errs
+}
+
+// validateObject checks the apiVersion and kind of the mapping doc and
+// hands it to the validator registered for them, or for a v1 List
+// validates its items.
+func validateObject(doc *yaml.Node, h Helpers, report ReportFunc) {
+	c := h.checker(report)
+	apiVersion := c.requireEnum(doc, "apiVersion", "")
+	kind := c.requireEnum(doc, "kind", "")
+	if kind == nil {
+		return
+	}
+	var gvk GroupVersionKind
+	if apiVersion != nil {
+		gvk.Group, gvk.Version = ParseGroupVersion(apiVersion.Value)
+	}
+	gvk.Kind = kind.Value
+	if apiVersion != nil && c.apiDeprecated(apiVersion, gvk) {
+		return
+	}
+	if gvk == listKind {
+		validateList(doc, h, report)
+		return
+	}
+	if gvk == configMapKind && h.opts.Nested {
+		validateConfigMap(doc, h, report)
+		return
+	}
+	fn, versions := h.opts.registry().lookup(gvk)
+	if fn == nil && len(versions) == 0 {
+		e := unsupportedValue("kind", kind)
+		e.Err = ErrUnsupportedKind
+		report(e)
+		return
+	}
+	if fn == nil {
+		if apiVersion != nil {
+			report(unsupportedValue("apiVersion", apiVersion))
+		}
+		return
+	}
+	fn(doc, h, report)
+}
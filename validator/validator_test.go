@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEntryPointsAgree(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(twoContainerPod))
+	zw.Close()
+	tests := []struct {
+		name string
+		data []byte
+		opts Options
+	}{
+		{"valid", []byte(validPod), Options{}},
+		{"findings", []byte(badManifests), Options{Nested: true, EnableRules: []string{"probe-port"}}},
+		{"several documents", []byte(validPod + "---\n" + twoContainerPod), Options{}},
+		{"gzip", compressed.Bytes(), Options{}},
+		{"within the size limit", []byte(validPod), Options{MaxFileSize: int64(len(validPod))}},
+		{"too large", []byte(validPod), Options{MaxFileSize: 16}},
+		{"not YAML", []byte("key: [unclosed\n"), Options{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "in.yaml")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			entries := map[string]func() (*Result, error){
+				"ValidateFile":   func() (*Result, error) { return ValidateFile(ctx, path, tt.opts) },
+				"ValidateBytes":  func() (*Result, error) { return ValidateBytes(ctx, path, tt.data, tt.opts) },
+				"ValidateReader": func() (*Result, error) { return ValidateReader(ctx, path, bytes.NewReader(tt.data), tt.opts) },
+			}
+			want, wantErr := outcome(entries["ValidateBytes"]())
+			for name, validate := range entries {
+				got, err := outcome(validate())
+				if err != wantErr {
+					t.Errorf("%s: error %q, ValidateBytes %q", name, err, wantErr)
+				}
+				if got != want {
+					t.Errorf("%s:\n%s\nValidateBytes:\n%s", name, got, want)
+				}
+			}
+			if strings.Contains(want, `"file":"`) && !strings.Contains(want, `"file":"`+path+`"`) {
+				t.Errorf("findings do not name %s:\n%s", path, want)
+			}
+		})
+	}
+}
+
+// outcome renders what a Validate function returned for comparison.
+func outcome(res *Result, err error) (string, string) {
+	if err != nil {
+		return "", err.Error()
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return "", err.Error()
+	}
+	return string(b), ""
+}
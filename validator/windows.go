@@ -0,0 +1,73 @@
+package validator
+
+import "gopkg.in/yaml.v3"
+
+// Rule IDs of the Windows pod group. Disabling "windows" turns off all
+// of them; see Options.DisableRules.
+const (
+	ruleWindowsHostNamespaces = "windows-host-namespaces"
+	ruleWindowsPrivileged     = "windows-privileged"
+	ruleWindowsLinuxSecurity  = "windows-linux-security"
+	ruleWindowsHostPath       = "windows-host-path"
+)
+
+// linuxOnlySecurity lists the securityContext fields Windows nodes do
+// not implement, by the level they appear at.
+var linuxOnlySecurity = map[string][]string{
+	"pod":       {"runAsUser", "runAsGroup", "fsGroup", "seLinuxOptions", "seccompProfile"},
+	"container": {"runAsUser", "runAsGroup", "seLinuxOptions", "seccompProfile"},
+}
+
+// windowsPod checks the constraints of pods that declare spec.os.name
+// windows. Every finding points at the offending field and names the
+// line of the declaration that makes them apply.
+func (c *checker) windowsPod(spec *yaml.Node, path string) {
+	osName := getField(getField(spec, "os"), "name")
+	if osName == nil || osName.Kind != yaml.ScalarNode || osName.Value != "windows" {
+		return
+	}
+	because := textf("spec.os.name is windows on line %d", osName.Line)
+	report := func(rule string, sev Severity, field string, n *yaml.Node, format string, args ...any) {
+		e := crossField(field, n, "%s (%s)", textf(format, args...), because)
+		e.Rule, e.Severity = rule, sev
+		c.report(e)
+	}
+
+	for _, key := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if n := getField(spec, key); isTrue(n) {
+			report(ruleWindowsHostNamespaces, SeverityError, joinKey(path, key), n, "must not be true for Windows pods")
+		}
+	}
+	linuxFields := func(sc *yaml.Node, scPath, level string) {
+		for _, key := range linuxOnlySecurity[level] {
+			if n := getField(sc, key); !isNull(n) {
+				report(ruleWindowsLinuxSecurity, SeverityError, joinKey(scPath, key), n, "must not be set for Windows pods")
+			}
+		}
+	}
+	linuxFields(getField(spec, "securityContext"), joinKey(path, "securityContext"), "pod")
+	for _, list := range []string{"initContainers", "containers"} {
+		for i, ctr := range indexedItems(spec, list) {
+			sc := getField(ctr, "securityContext")
+			if sc == nil {
+				continue
+			}
+			scPath := joinKey(itemPath(joinKey(path, list), getField(spec, list), i), "securityContext")
+			if n := getField(sc, "privileged"); isTrue(n) {
+				report(ruleWindowsPrivileged, SeverityError, joinKey(scPath, "privileged"), n, "must not be true for Windows pods; use a HostProcess container instead")
+			}
+			linuxFields(sc, scPath, "container")
+		}
+	}
+	for i, v := range indexedItems(spec, "volumes") {
+		if n := getField(v, "hostPath"); n != nil {
+			report(ruleWindowsHostPath, SeverityWarning, joinKey(joinIndex(joinKey(path, "volumes"), i), "hostPath"), n,
+				"mounts a host path, which on Windows nodes needs a Windows path and often HostProcess privileges")
+		}
+	}
+}
+
+// isTrue reports whether n is the boolean true.
+func isTrue(n *yaml.Node) bool {
+	return n != nil && n.Kind == yaml.ScalarNode && n.Tag == "!!bool" && n.Value == "true"
+}